@@ -0,0 +1,21 @@
+// 檢查所有錯誤代碼在每個內嵌語系中皆有訊息，供建置時執行
+package main
+
+import (
+	"GolangBlog/locales"
+	"fmt"
+	"os"
+)
+
+func main() {
+	errs := locales.Check()
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	if len(errs) > 0 {
+		fmt.Fprintf(os.Stderr, "語系檢查失敗：共 %d 個問題\n", len(errs))
+		os.Exit(1)
+	}
+
+	fmt.Printf("語系檢查通過：%d 個錯誤代碼，語系 %v\n", len(locales.Codes()), locales.Locales())
+}
@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"GolangBlog/locales"
+	"GolangBlog/models"
+	"GolangBlog/services"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 第一方分析服務
+var analyticsService *services.AnalyticsService
+
+// 讀取國家代碼的請求標頭（由 CDN 或反向代理提供）
+var analyticsCountryHeader = "CF-IPCountry"
+
+// 分析事件請求結構
+type AnalyticsEventRequest struct {
+	Type         string `json:"type" binding:"required,oneof=page_view scroll read_complete"`
+	ArticleID    *uint  `json:"article_id"`
+	LanguageCode string `json:"language_code" binding:"max=10"`
+	URL          string `json:"url" binding:"required"`
+	Referrer     string `json:"referrer"`
+	ScrollDepth  int    `json:"scroll_depth" binding:"min=0,max=100"`
+	ReadSeconds  int    `json:"read_seconds" binding:"min=0"`
+}
+
+// 初始化第一方分析服務（需在資料庫連接後呼叫）
+func InitAnalyticsService(db *gorm.DB) {
+	if header := os.Getenv("ANALYTICS_COUNTRY_HEADER"); header != "" {
+		analyticsCountryHeader = header
+	}
+
+	analyticsService = services.NewAnalyticsService(services.AnalyticsConfig{
+		SiteHosts: splitEnvList("ANALYTICS_SITE_HOSTS"),
+	}, db)
+}
+
+// 接收前端送出的分析事件
+func TrackAnalyticsEvent(c *gin.Context) {
+	// 尊重瀏覽器的不追蹤設定
+	if c.GetHeader("DNT") == "1" || c.GetHeader("Sec-GPC") == "1" {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	var req AnalyticsEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	userAgent := c.Request.UserAgent()
+	if viewCounter.IsBot(userAgent) {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	pageURL, err := url.Parse(req.URL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrAnalyticsInvalidURL))
+		return
+	}
+
+	now := time.Now()
+	visitorHash, err := analyticsService.VisitorHash(c.ClientIP(), userAgent, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrAnalyticsRecordFailed))
+		return
+	}
+
+	referrer := analyticsService.ParseReferrer(req.Referrer)
+	utm := services.ParseUTM(req.URL)
+
+	// 只保留兩碼國家代碼，未知或 Tor 代碼不記錄
+	country := strings.ToUpper(c.GetHeader(analyticsCountryHeader))
+	if len(country) != 2 || country == "XX" || country == "T1" {
+		country = ""
+	}
+
+	path := pageURL.EscapedPath()
+	if len(path) > 500 {
+		path = path[:500]
+	}
+	// 國際化網域名稱可能包含多位元組字元，依字元數截斷
+	referrerHost := services.Truncate(referrer.Host, 255)
+
+	// 未指定語言時使用語言協商結果
+	if req.LanguageCode == "" {
+		req.LanguageCode = currentLanguage(c)
+	}
+
+	event := models.AnalyticsEvent{
+		CreatedAt:    now,
+		Type:         req.Type,
+		ArticleID:    req.ArticleID,
+		LanguageCode: req.LanguageCode,
+		Path:         path,
+		VisitorHash:  visitorHash,
+		ReferrerHost: referrerHost,
+		ReferrerType: referrer.Type,
+		UTMSource:    utm.Source,
+		UTMMedium:    utm.Medium,
+		UTMCampaign:  utm.Campaign,
+		Country:      country,
+		DeviceType:   services.DeviceType(userAgent),
+		ScrollDepth:  req.ScrollDepth,
+		ReadSeconds:  req.ReadSeconds,
+	}
+	if req.Type == services.AnalyticsReadComplete {
+		event.ScrollDepth = 100
+	}
+
+	if err := analyticsService.Track(&event); err != nil {
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrAnalyticsRecordFailed))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// 獲取分析統計（可依文章、語言與日期區間篩選）
+func GetAnalyticsStats(c *gin.Context) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	filter := services.AnalyticsFilter{
+		From:         today.AddDate(0, 0, -29),
+		To:           today.AddDate(0, 0, 1),
+		LanguageCode: c.Query("lang"),
+	}
+
+	if from := c.Query("from"); from != "" {
+		parsed, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrDateFromInvalid))
+			return
+		}
+		filter.From = parsed
+	}
+
+	if to := c.Query("to"); to != "" {
+		parsed, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrDateToInvalid))
+			return
+		}
+		// 結束日期包含當日
+		filter.To = parsed.AddDate(0, 0, 1)
+	}
+
+	if !filter.From.Before(filter.To) {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrDateRangeInvalid))
+		return
+	}
+
+	if articleIDStr := c.Query("article_id"); articleIDStr != "" {
+		articleID, err := strconv.ParseUint(articleIDStr, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrAnalyticsInvalidArticle))
+			return
+		}
+		id := uint(articleID)
+		filter.ArticleID = &id
+	}
+
+	report, err := analyticsService.Stats(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":  filter.From.Format("2006-01-02"),
+		"to":    filter.To.AddDate(0, 0, -1).Format("2006-01-02"),
+		"stats": report,
+	})
+}
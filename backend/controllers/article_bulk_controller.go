@@ -0,0 +1,372 @@
+package controllers
+
+import (
+	"GolangBlog/config"
+	"GolangBlog/locales"
+	"GolangBlog/models"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 單次批次操作最多處理的文章數
+const maxBulkArticles = 500
+
+// 文章批次操作請求結構：以 ids 或 filter 選取文章，在同一個事務中套用所有操作
+type ArticleBulkRequest struct {
+	IDs    []uint             `json:"ids"`
+	Filter *ArticleBulkFilter `json:"filter"` // 未提供 ids 時依條件選取文章
+
+	Status            string `json:"status" binding:"omitempty,oneof=draft published archived"`
+	IsFeatured        *bool  `json:"is_featured"`
+	AddTagIDs         []uint `json:"add_tag_ids"`
+	RemoveTagIDs      []uint `json:"remove_tag_ids"`
+	AddCategoryIDs    []uint `json:"add_category_ids"`
+	RemoveCategoryIDs []uint `json:"remove_category_ids"`
+	Delete            bool   `json:"delete"` // 刪除文章，不能與其他操作同時使用
+
+	DryRun bool `json:"dry_run"` // 只回傳各文章的預計結果，不寫入
+}
+
+// 批次操作的文章篩選條件
+type ArticleBulkFilter struct {
+	Status       string `json:"status"`
+	IsFeatured   *bool  `json:"is_featured"`
+	TagID        uint   `json:"tag_id"`
+	CategoryID   uint   `json:"category_id"`
+	UserID       uint   `json:"user_id"`
+	LanguageCode string `json:"language_code"` // 具有該語言翻譯的文章
+}
+
+// 單篇文章的批次操作結果
+type articleBulkResult struct {
+	ID      uint     `json:"id"`
+	Result  string   `json:"result"`            // updated、unchanged、deleted 或 not_found
+	Changes []string `json:"changes,omitempty"` // 變更的項目：status、featured、tags、categories
+}
+
+// 篩選條件是否為空（避免誤選所有文章）
+func (f *ArticleBulkFilter) empty() bool {
+	return f == nil || (f.Status == "" && f.IsFeatured == nil && f.TagID == 0 && f.CategoryID == 0 && f.UserID == 0 && f.LanguageCode == "")
+}
+
+// 依篩選條件建立文章查詢
+func (f *ArticleBulkFilter) query() *gorm.DB {
+	query := config.DB.Model(&models.Article{})
+	if f.Status != "" {
+		query = query.Where("status = ?", f.Status)
+	}
+	if f.IsFeatured != nil {
+		query = query.Where("is_featured = ?", *f.IsFeatured)
+	}
+	if f.TagID != 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM article_tags WHERE article_tags.article_id = articles.id AND article_tags.tag_id = ?)", f.TagID)
+	}
+	if f.CategoryID != 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM article_categories WHERE article_categories.article_id = articles.id AND article_categories.category_id = ?)", f.CategoryID)
+	}
+	if f.UserID != 0 {
+		query = query.Where("user_id = ?", f.UserID)
+	}
+	if f.LanguageCode != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM article_translations WHERE article_translations.article_id = articles.id "+
+			"AND article_translations.language_code = ? AND article_translations.deleted_at IS NULL)", f.LanguageCode)
+	}
+	return query
+}
+
+// 批次操作文章：變更狀態、精選、加入或移除標籤與分類，或刪除文章；
+// 所有變更在同一個事務中完成，任一步驟失敗時全部復原。dry_run 時只回傳預計結果
+func BulkUpdateArticles(c *gin.Context) {
+	var req ArticleBulkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	// 檢查操作內容
+	hasUpdate := req.Status != "" || req.IsFeatured != nil ||
+		len(req.AddTagIDs) > 0 || len(req.RemoveTagIDs) > 0 || len(req.AddCategoryIDs) > 0 || len(req.RemoveCategoryIDs) > 0
+	if !hasUpdate && !req.Delete {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrArticleBulkOperationMissing))
+		return
+	}
+	if hasUpdate && req.Delete {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrArticleBulkDeleteExclusive))
+		return
+	}
+	if !validateBulkLinks(c, &models.Tag{}, req.AddTagIDs, req.RemoveTagIDs, locales.ErrArticleBulkTagsMissing) ||
+		!validateBulkLinks(c, &models.Category{}, req.AddCategoryIDs, req.RemoveCategoryIDs, locales.ErrArticleBulkCategoriesMissing) {
+		return
+	}
+
+	// 選取文章
+	var ids []uint
+	switch {
+	case len(req.IDs) > 0:
+		ids = uniqueIDs(req.IDs)
+	case !req.Filter.empty():
+		var total int64
+		if err := req.Filter.query().Count(&total).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+			return
+		}
+		if total > maxBulkArticles {
+			c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrArticleBulkTooMany, locales.Params{"count": total, "max": maxBulkArticles}))
+			return
+		}
+		if err := req.Filter.query().Order("id asc").Pluck("id", &ids).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrArticleBulkTargetMissing))
+		return
+	}
+	if len(ids) > maxBulkArticles {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrArticleBulkTooMany, locales.Params{"count": len(ids), "max": maxBulkArticles}))
+		return
+	}
+
+	var articles []models.Article
+	if err := config.DB.Where("id IN ?", ids).Find(&articles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+	byID := make(map[uint]*models.Article, len(articles))
+	for i := range articles {
+		byID[articles[i].ID] = &articles[i]
+	}
+
+	// 目前的標籤與分類關聯
+	tagLinks, err := loadArticleLinks("article_tags", "tag_id", ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+	categoryLinks, err := loadArticleLinks("article_categories", "category_id", ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	// 開始事務（dry_run 時不寫入）
+	var tx *gorm.DB
+	if !req.DryRun {
+		tx = config.DB.Begin()
+	}
+
+	now := time.Now()
+	counts := map[string]int{}
+	results := make([]articleBulkResult, 0, len(ids))
+	var changedIDs []uint
+	for _, id := range ids {
+		article, ok := byID[id]
+		if !ok {
+			results = append(results, articleBulkResult{ID: id, Result: "not_found"})
+			counts["not_found"]++
+			continue
+		}
+
+		result := articleBulkResult{ID: id, Result: "unchanged"}
+		var opErr error
+		if req.Delete {
+			result.Result = "deleted"
+			if tx != nil {
+				opErr = deleteArticleTx(tx, article)
+			}
+		} else {
+			// 文章欄位
+			values := map[string]interface{}{}
+			if req.Status != "" && req.Status != article.Status {
+				values["status"] = req.Status
+				if req.Status == "published" {
+					values["published_at"] = now
+				}
+				result.Changes = append(result.Changes, "status")
+			}
+			if req.IsFeatured != nil && *req.IsFeatured != article.IsFeatured {
+				values["is_featured"] = *req.IsFeatured
+				result.Changes = append(result.Changes, "featured")
+			}
+
+			// 標籤與分類關聯
+			addTags, removeTags := linkChanges(tagLinks[id], req.AddTagIDs, req.RemoveTagIDs)
+			if len(addTags) > 0 || len(removeTags) > 0 {
+				result.Changes = append(result.Changes, "tags")
+			}
+			addCategories, removeCategories := linkChanges(categoryLinks[id], req.AddCategoryIDs, req.RemoveCategoryIDs)
+			if len(addCategories) > 0 || len(removeCategories) > 0 {
+				result.Changes = append(result.Changes, "categories")
+			}
+
+			if len(result.Changes) > 0 {
+				result.Result = "updated"
+			}
+			// 有任何變更時遞增版本號，讓持有舊版本的編輯者在儲存時收到 412
+			if tx != nil && len(result.Changes) > 0 {
+				values["updated_at"] = now
+				values["version"] = gorm.Expr("version + 1")
+				opErr = tx.Model(&models.Article{}).Where("id = ?", id).Updates(values).Error
+			}
+			if tx != nil && opErr == nil {
+				opErr = updateArticleLinks(tx, "article_tags", "tag_id", id, addTags, removeTags)
+			}
+			if tx != nil && opErr == nil {
+				opErr = updateArticleLinks(tx, "article_categories", "category_id", id, addCategories, removeCategories)
+			}
+		}
+
+		if opErr != nil {
+			tx.Rollback()
+			body := errorDetail(c, locales.ErrArticleBulkFailed, opErr)
+			body["article_id"] = id
+			c.JSON(http.StatusInternalServerError, body)
+			return
+		}
+
+		if result.Result != "unchanged" {
+			changedIDs = append(changedIDs, id)
+		}
+		counts[result.Result]++
+		results = append(results, result)
+	}
+
+	if tx != nil {
+		// 提交事務
+		tx.Commit()
+
+		if req.Delete && len(changedIDs) > 0 {
+			// 翻譯內容已變更，清除翻譯記憶快取
+			translationMemory.Invalidate()
+		}
+		// 標籤、分類或狀態已變更，相關文章需重新計算
+		for _, id := range changedIDs {
+			relatedService.Invalidate(id)
+		}
+		// 文章已刪除（編輯鎖一併移除），通知正在查看的編輯者
+		if req.Delete {
+			for _, id := range changedIDs {
+				articlePresence.Notify(id)
+			}
+		}
+	}
+
+	message := "批次操作完成"
+	if req.DryRun {
+		message = "批次操作預覽（未寫入）"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   message,
+		"dry_run":   req.DryRun,
+		"total":     len(ids),
+		"updated":   counts["updated"],
+		"unchanged": counts["unchanged"],
+		"deleted":   counts["deleted"],
+		"not_found": counts["not_found"],
+		"results":   results,
+	})
+}
+
+// 驗證要加入與移除的標籤或分類：皆須存在，且不能同時出現在兩個清單
+func validateBulkLinks(c *gin.Context, model interface{}, add, remove []uint, missingCode string) bool {
+	ids := uniqueIDs(append(append([]uint(nil), add...), remove...))
+	if len(ids) == 0 {
+		return true
+	}
+
+	removing := make(map[uint]bool, len(remove))
+	for _, id := range remove {
+		removing[id] = true
+	}
+	for _, id := range add {
+		if removing[id] {
+			c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, fmt.Errorf("ID %d 同時出現在加入與移除清單", id)))
+			return false
+		}
+	}
+
+	var count int64
+	if err := config.DB.Model(model).Where("id IN ?", ids).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return false
+	}
+	if int(count) != len(ids) {
+		c.JSON(http.StatusBadRequest, errorBody(c, missingCode, locales.Params{"count": len(ids) - int(count)}))
+		return false
+	}
+	return true
+}
+
+// 載入文章的多對多關聯（article_id -> 關聯 ID 集合）
+func loadArticleLinks(table, column string, articleIDs []uint) (map[uint]map[uint]bool, error) {
+	var rows []struct {
+		ArticleID uint
+		LinkID    uint
+	}
+	if err := config.DB.Table(table).Select("article_id, "+column+" AS link_id").
+		Where("article_id IN ?", articleIDs).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	links := make(map[uint]map[uint]bool)
+	for _, row := range rows {
+		if links[row.ArticleID] == nil {
+			links[row.ArticleID] = map[uint]bool{}
+		}
+		links[row.ArticleID][row.LinkID] = true
+	}
+	return links, nil
+}
+
+// 計算需要新增與移除的關聯（已存在的不重複新增，不存在的不需移除）
+func linkChanges(current map[uint]bool, add, remove []uint) ([]uint, []uint) {
+	var toAdd, toRemove []uint
+	for _, id := range uniqueIDs(add) {
+		if !current[id] {
+			toAdd = append(toAdd, id)
+		}
+	}
+	for _, id := range uniqueIDs(remove) {
+		if current[id] {
+			toRemove = append(toRemove, id)
+		}
+	}
+	return toAdd, toRemove
+}
+
+// 寫入文章的多對多關聯變更
+func updateArticleLinks(tx *gorm.DB, table, column string, articleID uint, add, remove []uint) error {
+	if len(remove) > 0 {
+		if err := tx.Exec("DELETE FROM "+table+" WHERE article_id = ? AND "+column+" IN ?", articleID, remove).Error; err != nil {
+			return err
+		}
+	}
+	for _, id := range add {
+		if err := tx.Exec("INSERT INTO "+table+" (article_id, "+column+") VALUES (?, ?)", articleID, id).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 在事務中刪除文章與其標籤、分類關聯及翻譯
+func deleteArticleTx(tx *gorm.DB, article *models.Article) error {
+	if err := tx.Exec("DELETE FROM article_tags WHERE article_id = ?", article.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM article_categories WHERE article_id = ?", article.ID).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("article_id = ?", article.ID).Delete(&models.ArticleTranslation{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("article_id = ?", article.ID).Delete(&models.ArticleEditLock{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(article).Error
+}
@@ -2,13 +2,15 @@ package controllers
 
 import (
 	"GolangBlog/config"
+	"GolangBlog/locales"
 	"GolangBlog/models"
+	"GolangBlog/utils"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gosimple/slug"
 	"gorm.io/gorm"
 )
 
@@ -31,6 +33,19 @@ type ArticleTranslationRequest struct {
 	MetaTitle       string `json:"meta_title"`
 	MetaDescription string `json:"meta_description"`
 	MetaKeywords    string `json:"meta_keywords"`
+	SourceRevision  *int   `json:"source_revision"` // 翻譯依據的來源語言版本，未指定時視為目前版本
+}
+
+// 文章摘要的字數上限（與欄位長度一致）
+const maxExcerptLength = 500
+
+// 取得請求中的翻譯語言
+func requestLanguages(translations []ArticleTranslationRequest) []string {
+	languages := make([]string, 0, len(translations))
+	for _, trans := range translations {
+		languages = append(languages, trans.LanguageCode)
+	}
+	return languages
 }
 
 // 獲取文章列表
@@ -46,11 +61,20 @@ func GetArticles(c *gin.Context) {
 		query = query.Where("status = ?", status)
 	}
 
-	// 語言篩選
-	langCode := c.Query("lang")
+	// 語言篩選（依退回順序，任一語言有翻譯即可）；未指定語言時，訪客只能看到公開語言的翻譯
+	langCode := explicitLanguage(c)
+	var chain []string
+	languages := visibleLanguages(c)
 	if langCode != "" {
-		query = query.Joins("JOIN article_translations ON articles.id = article_translations.article_id").
-			Where("article_translations.language_code = ?", langCode)
+		chain = languageChain(c, langCode)
+		languages = chain
+	}
+	if languages != nil {
+		translations := config.DB.Table("article_translations").Select("1").
+			Where("article_translations.article_id = articles.id AND article_translations.language_code IN ? "+
+				"AND article_translations.deleted_at IS NULL", languages).
+			Scopes(reviewedScope(c))
+		query = query.Where("EXISTS (?)", translations)
 	}
 
 	// 標籤篩選
@@ -68,13 +92,8 @@ func GetArticles(c *gin.Context) {
 	}
 
 	// 預載相關數據
-	query = query.Preload("Translations", func(db *gorm.DB) *gorm.DB {
-		// 如果指定了語言，只載入該語言的翻譯
-		if langCode != "" {
-			return db.Where("language_code = ?", langCode)
-		}
-		return db
-	}).Preload("Tags").Preload("User")
+	// 只載入退回順序內（未指定語言時為可查看語言）的翻譯
+	query = query.Preload("Translations", translationScope(c, languages)).Preload("Tags").Preload("User")
 
 	// 排序
 	orderBy := c.DefaultQuery("order_by", "created_at")
@@ -91,6 +110,13 @@ func GetArticles(c *gin.Context) {
 
 	query.Limit(pageSize).Offset(offset).Find(&articles)
 
+	if chain != nil {
+		localizeArticles(articles, chain)
+	}
+
+	// 填入反應統計
+	attachReactionCounts(articles)
+
 	c.JSON(http.StatusOK, gin.H{
 		"articles": articles,
 		"pagination": gin.H{
@@ -107,27 +133,32 @@ func GetArticle(c *gin.Context) {
 	id := c.Param("id")
 	var article models.Article
 
-	// 預載所有相關數據
-	if err := config.DB.Preload("Translations").
-		Preload("Tags.Translations").
+	// 預載所有相關數據（只包含請求者可查看的語言）
+	if err := config.DB.Preload("Translations", translationScope(c, nil)).
+		Preload("Tags.Translations", translationScope(c, nil)).
 		Preload("User").
 		First(&article, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrArticleNotFound))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		}
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"article": article})
+	// 填入反應統計
+	articles := []models.Article{article}
+	attachReactionCounts(articles)
+
+	setETag(c, "article", article.ID, article.Version)
+	c.JSON(http.StatusOK, gin.H{"article": articles[0]})
 }
 
 // 創建新文章
 func CreateArticle(c *gin.Context) {
 	var req ArticleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
 		return
 	}
 
@@ -152,16 +183,31 @@ func CreateArticle(c *gin.Context) {
 	// 創建文章
 	if err := tx.Create(&article).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "創建文章失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleCreateFailed))
 		return
 	}
 
 	// 處理翻譯
+	tracker, err := newRevisionTracker(tx, &models.ArticleTranslation{}, "article_id", article.ID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrTranslationLoadFailed))
+		return
+	}
+	tracker.sortSourceFirst(req.Translations, func(i int) string { return req.Translations[i].LanguageCode })
+
 	for _, trans := range req.Translations {
-		// 如果沒有提供 slug，則自動生成
-		if trans.Slug == "" {
-			trans.Slug = slug.Make(trans.Title)
+		// 決定 slug：未提供時自動生成並避免重複，提供的 slug 衝突時回傳 409
+		slugValue, conflict, err := resolveSlug(tx, translationTargets["article"], article.ID, trans.LanguageCode, trans.Slug, trans.Title)
+		if err != nil || conflict != nil {
+			tx.Rollback()
+			respondSlugError(c, trans.LanguageCode, conflict, err)
+			return
 		}
+		trans.Slug = slugValue
+
+		// 摘要超過欄位長度時截斷（不切斷組合字元與方向控制）
+		trans.Excerpt = utils.TruncateText(trans.Excerpt, maxExcerptLength)
 
 		translation := models.ArticleTranslation{
 			ArticleID:       article.ID,
@@ -174,10 +220,11 @@ func CreateArticle(c *gin.Context) {
 			MetaDescription: trans.MetaDescription,
 			MetaKeywords:    trans.MetaKeywords,
 		}
+		tracker.apply(trans.LanguageCode, true, trans.SourceRevision, &translation.TranslationStatus)
 
 		if err := tx.Create(&translation).Error; err != nil {
 			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "創建文章翻譯失敗"})
+			c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleTranslationCreateFailed))
 			return
 		}
 	}
@@ -187,7 +234,7 @@ func CreateArticle(c *gin.Context) {
 		for _, tagID := range req.TagIDs {
 			if err := tx.Exec("INSERT INTO article_tags (article_id, tag_id) VALUES (?, ?)", article.ID, tagID).Error; err != nil {
 				tx.Rollback()
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "關聯標籤失敗"})
+				c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleTagsLinkFailed))
 				return
 			}
 		}
@@ -198,7 +245,7 @@ func CreateArticle(c *gin.Context) {
 		for _, catID := range req.CategoryIDs {
 			if err := tx.Exec("INSERT INTO article_categories (article_id, category_id) VALUES (?, ?)", article.ID, catID).Error; err != nil {
 				tx.Rollback()
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "關聯分類失敗"})
+				c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleCategoriesLinkFailed))
 				return
 			}
 		}
@@ -207,13 +254,21 @@ func CreateArticle(c *gin.Context) {
 	// 提交事務
 	tx.Commit()
 
+	// 翻譯內容已變更，清除翻譯記憶快取
+	translationMemory.Invalidate()
+
 	// 獲取完整的文章數據回傳
 	var fullArticle models.Article
 	config.DB.Preload("Translations").Preload("Tags").Preload("User").First(&fullArticle, article.ID)
 
+	// 譯文未使用詞彙表指定的譯法時提出警告（不影響儲存）
+	glossaryWarnings := articleGlossaryWarnings(fullArticle.Translations, requestLanguages(req.Translations))
+
+	setETag(c, "article", fullArticle.ID, fullArticle.Version)
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "文章創建成功",
-		"article": fullArticle,
+		"message":           "文章創建成功",
+		"article":           fullArticle,
+		"glossary_warnings": glossaryWarnings,
 	})
 }
 
@@ -225,22 +280,35 @@ func UpdateArticle(c *gin.Context) {
 	// 確認文章存在
 	if err := config.DB.First(&article, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrArticleNotFound))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		}
 		return
 	}
 
+	// 確認請求者編輯的是最新版本
+	if !checkIfMatch(c, "article", article.ID, article.Version) {
+		return
+	}
+
 	var req ArticleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
 		return
 	}
 
 	// 啟動事務
 	tx := config.DB.Begin()
 
+	// 遞增版本號（檢查後若已被其他請求修改則回傳 412）
+	if ok, err := bumpVersion(tx, &models.Article{}, article.ID, article.Version); !ok {
+		tx.Rollback()
+		respondBumpFailure(c, "article", &models.Article{}, article.ID, err)
+		return
+	}
+	article.Version++
+
 	// 更新文章基本資訊
 	prevStatus := article.Status
 	article.Status = req.Status
@@ -252,18 +320,34 @@ func UpdateArticle(c *gin.Context) {
 		article.PublishedAt = &now
 	}
 
-	if err := tx.Save(&article).Error; err != nil {
+	// 瀏覽數由瀏覽計數服務以累加方式寫入，不可用載入時的舊值覆蓋
+	if err := tx.Omit("view_count").Save(&article).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新文章失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleUpdateFailed))
 		return
 	}
 
 	// 處理翻譯
+	tracker, err := newRevisionTracker(tx, &models.ArticleTranslation{}, "article_id", article.ID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrTranslationLoadFailed))
+		return
+	}
+	tracker.sortSourceFirst(req.Translations, func(i int) string { return req.Translations[i].LanguageCode })
+
 	for _, trans := range req.Translations {
-		// 如果沒有提供 slug，則自動生成
-		if trans.Slug == "" {
-			trans.Slug = slug.Make(trans.Title)
+		// 決定 slug：未提供時自動生成並避免重複，提供的 slug 衝突時回傳 409
+		slugValue, conflict, err := resolveSlug(tx, translationTargets["article"], article.ID, trans.LanguageCode, trans.Slug, trans.Title)
+		if err != nil || conflict != nil {
+			tx.Rollback()
+			respondSlugError(c, trans.LanguageCode, conflict, err)
+			return
 		}
+		trans.Slug = slugValue
+
+		// 摘要超過欄位長度時截斷（不切斷組合字元與方向控制）
+		trans.Excerpt = utils.TruncateText(trans.Excerpt, maxExcerptLength)
 
 		var translation models.ArticleTranslation
 		// 檢查該語言的翻譯是否已存在
@@ -283,20 +367,30 @@ func UpdateArticle(c *gin.Context) {
 					MetaDescription: trans.MetaDescription,
 					MetaKeywords:    trans.MetaKeywords,
 				}
+				tracker.apply(trans.LanguageCode, true, trans.SourceRevision, &translation.TranslationStatus)
 
 				if err := tx.Create(&translation).Error; err != nil {
 					tx.Rollback()
-					c.JSON(http.StatusInternalServerError, gin.H{"error": "創建文章翻譯失敗"})
+					c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleTranslationCreateFailed))
 					return
 				}
 			} else {
 				tx.Rollback()
-				c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+				c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, result.Error))
 				return
 			}
 		} else {
 			// 更新已存在的翻譯
+			changed := translation.Title != trans.Title || translation.Excerpt != trans.Excerpt || translation.Content != trans.Content
+			tracker.apply(trans.LanguageCode, changed, trans.SourceRevision, &translation.TranslationStatus)
+
 			translation.Title = trans.Title
+			// slug 變更時保留舊 slug 作為轉址
+			if err := recordSlugChange(tx, "article", article.ID, trans.LanguageCode, translation.Slug, trans.Slug); err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrArticleTranslationUpdateFailed, err))
+				return
+			}
 			translation.Slug = trans.Slug
 			translation.Excerpt = trans.Excerpt
 			translation.Content = trans.Content
@@ -306,16 +400,23 @@ func UpdateArticle(c *gin.Context) {
 
 			if err := tx.Save(&translation).Error; err != nil {
 				tx.Rollback()
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "更新文章翻譯失敗"})
+				c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleTranslationUpdateFailed))
 				return
 			}
 		}
 	}
 
+	// 來源語言內容變更時，標記其他語言翻譯待更新
+	if err := tracker.markStale(tx, &models.ArticleTranslation{}, "article_id", article.ID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrTranslationStatusUpdateFailed))
+		return
+	}
+
 	// 處理標籤關聯
 	if err := tx.Exec("DELETE FROM article_tags WHERE article_id = ?", article.ID).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "刪除舊標籤關聯失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleTagsUnlinkFailed))
 		return
 	}
 
@@ -323,7 +424,7 @@ func UpdateArticle(c *gin.Context) {
 		for _, tagID := range req.TagIDs {
 			if err := tx.Exec("INSERT INTO article_tags (article_id, tag_id) VALUES (?, ?)", article.ID, tagID).Error; err != nil {
 				tx.Rollback()
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "關聯標籤失敗"})
+				c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleTagsLinkFailed))
 				return
 			}
 		}
@@ -332,7 +433,7 @@ func UpdateArticle(c *gin.Context) {
 	// 處理分類關聯
 	if err := tx.Exec("DELETE FROM article_categories WHERE article_id = ?", article.ID).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "刪除舊分類關聯失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleCategoriesUnlinkFailed))
 		return
 	}
 
@@ -340,7 +441,7 @@ func UpdateArticle(c *gin.Context) {
 		for _, catID := range req.CategoryIDs {
 			if err := tx.Exec("INSERT INTO article_categories (article_id, category_id) VALUES (?, ?)", article.ID, catID).Error; err != nil {
 				tx.Rollback()
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "關聯分類失敗"})
+				c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleCategoriesLinkFailed))
 				return
 			}
 		}
@@ -349,13 +450,24 @@ func UpdateArticle(c *gin.Context) {
 	// 提交事務
 	tx.Commit()
 
+	// 翻譯內容已變更，清除翻譯記憶快取
+	translationMemory.Invalidate()
+
+	// 標籤、分類或內容已變更，相關文章需重新計算
+	relatedService.Invalidate(article.ID)
+
 	// 獲取完整的文章數據回傳
 	var fullArticle models.Article
 	config.DB.Preload("Translations").Preload("Tags").Preload("User").First(&fullArticle, article.ID)
 
+	// 譯文未使用詞彙表指定的譯法時提出警告（不影響儲存）
+	glossaryWarnings := articleGlossaryWarnings(fullArticle.Translations, requestLanguages(req.Translations))
+
+	setETag(c, "article", fullArticle.ID, fullArticle.Version)
 	c.JSON(http.StatusOK, gin.H{
-		"message": "文章更新成功",
-		"article": fullArticle,
+		"message":           "文章更新成功",
+		"article":           fullArticle,
+		"glossary_warnings": glossaryWarnings,
 	})
 }
 
@@ -367,48 +479,73 @@ func DeleteArticle(c *gin.Context) {
 	// 確認文章存在
 	if err := config.DB.First(&article, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrArticleNotFound))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		}
 		return
 	}
 
+	// 確認請求者刪除的是最新版本
+	if !checkIfMatch(c, "article", article.ID, article.Version) {
+		return
+	}
+
 	// 啟動事務
 	tx := config.DB.Begin()
 
+	// 遞增版本號（檢查後若已被其他請求修改則回傳 412）
+	if ok, err := bumpVersion(tx, &models.Article{}, article.ID, article.Version); !ok {
+		tx.Rollback()
+		respondBumpFailure(c, "article", &models.Article{}, article.ID, err)
+		return
+	}
+
 	// 刪除所有相關數據
 	// 刪除標籤關聯
 	if err := tx.Exec("DELETE FROM article_tags WHERE article_id = ?", id).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "刪除標籤關聯失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleTagsUnlinkFailed))
 		return
 	}
 
 	// 刪除分類關聯
 	if err := tx.Exec("DELETE FROM article_categories WHERE article_id = ?", id).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "刪除分類關聯失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleCategoriesUnlinkFailed))
 		return
 	}
 
 	// 刪除翻譯
 	if err := tx.Where("article_id = ?", id).Delete(&models.ArticleTranslation{}).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "刪除文章翻譯失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleTranslationDeleteFailed))
+		return
+	}
+
+	// 釋放編輯鎖
+	if err := tx.Where("article_id = ?", id).Delete(&models.ArticleEditLock{}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrArticleLockFailed, err))
 		return
 	}
 
 	// 刪除文章本身
 	if err := tx.Delete(&article).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "刪除文章失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleDeleteFailed))
 		return
 	}
 
 	// 提交事務
 	tx.Commit()
 
+	// 翻譯內容已變更，清除翻譯記憶快取
+	translationMemory.Invalidate()
+
+	relatedService.Invalidate(article.ID)
+	articlePresence.Notify(article.ID)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "文章刪除成功",
 	})
@@ -417,58 +554,117 @@ func DeleteArticle(c *gin.Context) {
 // 根據 Slug 獲取文章 (前台使用)
 func GetArticleBySlug(c *gin.Context) {
 	slug := c.Param("slug")
-	langCode := c.DefaultQuery("lang", "zh-TW") // 預設語言
+	langCode, chain := requestLanguage(c)
 
-	var articleTranslation models.ArticleTranslation
-	if err := config.DB.Where("slug = ? AND language_code = ?", slug, langCode).First(&articleTranslation).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	// 依語言退回順序尋找符合 slug 的翻譯
+	var matches []models.ArticleTranslation
+	if err := config.DB.Where("slug = ? AND language_code IN ?", slug, chain).Scopes(reviewedScope(c)).Find(&matches).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	codes := make([]string, len(matches))
+	for i, match := range matches {
+		codes[i] = match.LanguageCode
+	}
+	idx := pickLanguage(chain, codes)
+	if idx < 0 {
+		// 舊 slug 以 301 轉址到目前的 slug
+		if redirectOldSlug(c, "article", slug, chain) {
+			return
 		}
+		c.JSON(http.StatusNotFound, errorBody(c, locales.ErrArticleNotFound))
 		return
 	}
+	articleTranslation := matches[idx]
 
 	var article models.Article
-	if err := config.DB.Preload("Translations", func(db *gorm.DB) *gorm.DB {
-		return db.Where("language_code = ?", langCode)
-	}).Preload("Tags.Translations", func(db *gorm.DB) *gorm.DB {
-		return db.Where("language_code = ?", langCode)
-	}).Preload("User").First(&article, articleTranslation.ArticleID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := config.DB.Preload("Translations", translationScope(c, chain)).
+		Preload("Tags.Translations", translationScope(c, chain)).
+		Preload("User").First(&article, articleTranslation.ArticleID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		return
 	}
 
 	// 檢查文章是否已發布
 	if article.Status != "published" {
-		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在或未發布"})
+		c.JSON(http.StatusNotFound, errorBody(c, locales.ErrArticleNotPublished))
 		return
 	}
 
-	// 增加瀏覽次數
-	article.ViewCount++
-	config.DB.Save(&article)
+	// 記錄瀏覽次數（批次寫入，同時累加熱門排行的每小時統計）
+	recordArticleView(c, article.ID)
 
-	c.JSON(http.StatusOK, gin.H{"article": article})
+	// 依退回順序挑選翻譯並填入反應統計
+	articles := []models.Article{article}
+	localizeArticles(articles, chain)
+	attachReactionCounts(articles)
+
+	setETag(c, "article", article.ID, article.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"article":            articles[0],
+		"requested_language": langCode,
+		"served_language":    articles[0].ServedLanguage,
+		"series":             buildSeriesNavigation(c, article.ID, chain),
+	})
 }
 
-// 生成 Slug 的 API
+// 生成 Slug 的 API；同時檢查在該語言是否可用（不寫入資料）
 func GenerateSlug(c *gin.Context) {
 	type SlugRequest struct {
-		Title string `json:"title" binding:"required"`
+		Title        string `json:"title"`
+		Slug         string `json:"slug"`          // 要檢查的 slug，未提供時依標題產生
+		LanguageCode string `json:"language_code"` // 依該語言的 slug 策略產生，未指定時使用預設語言
+		Type         string `json:"type"`          // article（預設）、tag 或 category
+		ID           uint   `json:"id"`            // 編輯既有項目時提供，其原本的 slug 不算衝突
 	}
 
 	var req SlugRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+	if strings.TrimSpace(req.Title) == "" && strings.TrimSpace(req.Slug) == "" {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrInvalidRequest))
 		return
 	}
 
-	generatedSlug := slug.Make(req.Title)
+	if req.Type == "" {
+		req.Type = "article"
+	}
+	target, ok := translationTargets[req.Type]
+	if !ok {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrTranslationTypeUnsupported, locales.Params{"allowed": locales.List{"article", "tag", "category"}}))
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"slug": generatedSlug,
-	})
+	langCode := req.LanguageCode
+	if langCode == "" {
+		langCode = languageRegistry.DefaultCode()
+	}
+
+	generatedSlug := strings.TrimSpace(req.Slug)
+	if generatedSlug == "" {
+		generatedSlug = makeSlug(langCode, req.Title)
+	}
+
+	conflict, err := slugService.Check(config.DB, target.table, target.ownerColumn, req.ID, langCode, generatedSlug)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	response := gin.H{
+		"slug":          generatedSlug,
+		"language_code": langCode,
+		"available":     conflict == nil,
+	}
+	if conflict != nil {
+		response["reason"] = conflict.Reason
+		response["conflict_id"] = conflict.ConflictID
+		response["suggestion"] = conflict.Suggestion
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // 獲取精選文章
@@ -485,19 +681,17 @@ func GetFeaturedArticles(c *gin.Context) {
 		}
 	}
 
-	// 獲取語言參數
-	langCode := c.DefaultQuery("lang", "zh-TW")
+	// 獲取語言參數與退回順序
+	_, chain := requestLanguage(c)
 
 	// 查詢條件：status = 'published' 且 is_featured = true
 	query := config.DB.Model(&models.Article{}).
 		Where("status = ? AND is_featured = ?", "published", true)
 
 	// 關聯翻譯表並按指定語言篩選
-	query = query.Preload("Translations", func(db *gorm.DB) *gorm.DB {
-		return db.Where("language_code = ?", langCode)
-	}).Preload("Tags.Translations", func(db *gorm.DB) *gorm.DB {
-		return db.Where("language_code = ?", langCode)
-	}).Preload("User")
+	query = query.Preload("Translations", translationScope(c, chain)).
+		Preload("Tags.Translations", translationScope(c, chain)).
+		Preload("User")
 
 	// 按發布時間降序排列
 	query = query.Order("published_at DESC")
@@ -507,10 +701,14 @@ func GetFeaturedArticles(c *gin.Context) {
 
 	// 執行查詢
 	if err := query.Find(&articles).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		return
 	}
 
+	// 依退回順序挑選翻譯並填入反應統計
+	localizeArticles(articles, chain)
+	attachReactionCounts(articles)
+
 	c.JSON(http.StatusOK, gin.H{
 		"articles": articles,
 	})
@@ -530,19 +728,17 @@ func GetLatestArticles(c *gin.Context) {
 		}
 	}
 
-	// 獲取語言參數
-	langCode := c.DefaultQuery("lang", "zh-TW")
+	// 獲取語言參數與退回順序
+	_, chain := requestLanguage(c)
 
 	// 查詢條件：status = 'published'
 	query := config.DB.Model(&models.Article{}).
 		Where("status = ?", "published")
 
 	// 關聯翻譯表並按指定語言篩選
-	query = query.Preload("Translations", func(db *gorm.DB) *gorm.DB {
-		return db.Where("language_code = ?", langCode)
-	}).Preload("Tags.Translations", func(db *gorm.DB) *gorm.DB {
-		return db.Where("language_code = ?", langCode)
-	}).Preload("User")
+	query = query.Preload("Translations", translationScope(c, chain)).
+		Preload("Tags.Translations", translationScope(c, chain)).
+		Preload("User")
 
 	// 按發布時間降序排列
 	query = query.Order("published_at DESC")
@@ -552,10 +748,14 @@ func GetLatestArticles(c *gin.Context) {
 
 	// 執行查詢
 	if err := query.Find(&articles).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		return
 	}
 
+	// 依退回順序挑選翻譯並填入反應統計
+	localizeArticles(articles, chain)
+	attachReactionCounts(articles)
+
 	c.JSON(http.StatusOK, gin.H{
 		"articles": articles,
 	})
@@ -576,21 +776,31 @@ func GetArticlesByCategory(c *gin.Context) {
 		}
 	}
 
-	// 獲取語言參數
-	langCode := c.DefaultQuery("lang", "zh-TW")
+	// 獲取語言參數與退回順序
+	_, chain := requestLanguage(c)
+
+	// 查詢條件：依退回順序以分類 slug 找出分類，並篩選已發布文章
+	categoryIDs := config.DB.Model(&models.CategoryTranslation{}).
+		Select("category_id").
+		Where("slug = ? AND language_code IN ?", category, chain).Scopes(reviewedScope(c))
 
-	// 查詢條件：使用 category_translations 表並篩選 slug 和語言
+	// 分類已無此 slug 時，舊 slug 以 301 轉址到目前的 slug
+	var matched int64
+	if err := config.DB.Model(&models.CategoryTranslation{}).Where("slug = ? AND language_code IN ?", category, chain).Scopes(reviewedScope(c)).Count(&matched).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+	if matched == 0 && redirectOldSlug(c, "category", category, chain) {
+		return
+	}
 	query := config.DB.Model(&models.Article{}).
-		Joins("JOIN article_categories ON articles.id = article_categories.article_id").
-		Joins("JOIN category_translations ON article_categories.category_id = category_translations.category_id AND category_translations.language_code = ?", langCode).
-		Where("category_translations.slug = ? AND articles.status = ?", category, "published")
+		Where("articles.status = ? AND EXISTS (SELECT 1 FROM article_categories WHERE article_categories.article_id = articles.id AND article_categories.category_id IN (?))",
+			"published", categoryIDs)
 
 	// 關聯翻譯表並按指定語言篩選
-	query = query.Preload("Translations", func(db *gorm.DB) *gorm.DB {
-		return db.Where("language_code = ?", langCode)
-	}).Preload("Tags.Translations", func(db *gorm.DB) *gorm.DB {
-		return db.Where("language_code = ?", langCode)
-	}).Preload("User")
+	query = query.Preload("Translations", translationScope(c, chain)).
+		Preload("Tags.Translations", translationScope(c, chain)).
+		Preload("User")
 
 	// 按發布時間降序排列
 	query = query.Order("articles.published_at DESC")
@@ -600,10 +810,14 @@ func GetArticlesByCategory(c *gin.Context) {
 
 	// 執行查詢
 	if err := query.Find(&articles).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		return
 	}
 
+	// 依退回順序挑選翻譯並填入反應統計
+	localizeArticles(articles, chain)
+	attachReactionCounts(articles)
+
 	c.JSON(http.StatusOK, gin.H{
 		"articles": articles,
 	})
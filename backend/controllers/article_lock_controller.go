@@ -0,0 +1,396 @@
+package controllers
+
+import (
+	"GolangBlog/config"
+	"GolangBlog/locales"
+	"GolangBlog/models"
+	"GolangBlog/services"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 編輯鎖的有效時間（持有者需在過期前送出心跳續約）
+var editLockTTL = 2 * time.Minute
+
+// 文章的在線狀態追蹤
+var articlePresence = services.NewArticlePresence()
+
+// 文章的在線狀態：目前的編輯鎖與正在查看或編輯的使用者
+type articlePresenceSnapshot struct {
+	ArticleID uint                    `json:"article_id"`
+	Lock      *models.ArticleEditLock `json:"lock"`
+	Users     []services.PresenceUser `json:"users"`
+}
+
+// 初始化編輯鎖設定
+func InitEditLocks() {
+	if ttl, err := time.ParseDuration(os.Getenv("EDIT_LOCK_TTL")); err == nil && ttl > 0 {
+		editLockTTL = ttl
+	}
+}
+
+// 確認文章存在（不限狀態）
+func findLockArticle(c *gin.Context) (*models.Article, bool) {
+	var article models.Article
+	if err := config.DB.First(&article, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrArticleNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return nil, false
+	}
+	return &article, true
+}
+
+// 文章目前有效的編輯鎖（沒有或已過期時回傳 nil）
+func activeArticleLock(articleID uint) (*models.ArticleEditLock, error) {
+	var locks []models.ArticleEditLock
+	if err := config.DB.Preload("User").
+		Where("article_id = ? AND expires_at > ?", articleID, time.Now()).
+		Limit(1).Find(&locks).Error; err != nil {
+		return nil, err
+	}
+	if len(locks) == 0 {
+		return nil, nil
+	}
+	return &locks[0], nil
+}
+
+// 續約自己持有的編輯鎖；鎖已過期但尚未被他人取得時仍可續約
+func renewArticleLock(articleID, userID uint) (bool, error) {
+	now := time.Now()
+	result := config.DB.Model(&models.ArticleEditLock{}).
+		Where("article_id = ? AND user_id = ?", articleID, userID).
+		Updates(map[string]interface{}{"heartbeat_at": now, "expires_at": now.Add(editLockTTL)})
+	return result.RowsAffected > 0, result.Error
+}
+
+// 回傳目前的編輯鎖
+func respondArticleLock(c *gin.Context, message string, articleID uint) {
+	lock, err := activeArticleLock(articleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     message,
+		"lock":        lock,
+		"ttl_seconds": int(editLockTTL.Seconds()),
+	})
+}
+
+// 回傳 409：文章的編輯鎖由其他人持有
+func respondArticleLocked(c *gin.Context, articleID uint) {
+	lock, err := activeArticleLock(articleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	var name string
+	if lock != nil {
+		name = lock.User.Username
+	}
+	body := errorBody(c, locales.ErrArticleLocked, locales.Params{"name": name})
+	body["lock"] = lock
+	c.JSON(http.StatusConflict, body)
+}
+
+// 取得文章的編輯鎖（自己已持有時續約）；鎖為建議性質，不會阻擋其他人儲存，
+// 但其他人取得鎖時會收到 409 與目前持有者
+func AcquireArticleLock(c *gin.Context) {
+	article, ok := findLockArticle(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	now := time.Now()
+
+	// 清除已過期的鎖
+	if err := config.DB.Where("article_id = ? AND expires_at <= ?", article.ID, now).
+		Delete(&models.ArticleEditLock{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrArticleLockFailed, err))
+		return
+	}
+
+	// 每篇文章只有一個鎖（唯一索引），已有人持有時不會建立
+	lock := models.ArticleEditLock{
+		ArticleID:   article.ID,
+		UserID:      userID.(uint),
+		AcquiredAt:  now,
+		HeartbeatAt: now,
+		ExpiresAt:   now.Add(editLockTTL),
+	}
+	result := config.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&lock)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrArticleLockFailed, result.Error))
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		renewed, err := renewArticleLock(article.ID, userID.(uint))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrArticleLockFailed, err))
+			return
+		}
+		if !renewed {
+			respondArticleLocked(c, article.ID)
+			return
+		}
+	}
+
+	articlePresence.Notify(article.ID)
+	respondArticleLock(c, "已取得編輯鎖", article.ID)
+}
+
+// 編輯鎖心跳：延長自己持有的鎖；鎖已被接手時回傳 409
+func RenewArticleLock(c *gin.Context) {
+	article, ok := findLockArticle(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	renewed, err := renewArticleLock(article.ID, userID.(uint))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrArticleLockFailed, err))
+		return
+	}
+	if !renewed {
+		lock, _ := activeArticleLock(article.ID)
+		body := errorBody(c, locales.ErrArticleLockNotHeld)
+		body["lock"] = lock
+		c.JSON(http.StatusConflict, body)
+		return
+	}
+
+	respondArticleLock(c, "編輯鎖已續約", article.ID)
+}
+
+// 釋放自己持有的編輯鎖
+func ReleaseArticleLock(c *gin.Context) {
+	article, ok := findLockArticle(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	result := config.DB.Where("article_id = ? AND user_id = ?", article.ID, userID).
+		Delete(&models.ArticleEditLock{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrArticleLockFailed, result.Error))
+		return
+	}
+
+	// 沒有鎖可釋放：由其他人持有時回傳 409，沒有人持有時視為已釋放
+	if result.RowsAffected == 0 {
+		lock, err := activeArticleLock(article.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+			return
+		}
+		if lock != nil {
+			body := errorBody(c, locales.ErrArticleLockNotHeld)
+			body["lock"] = lock
+			c.JSON(http.StatusConflict, body)
+			return
+		}
+	}
+
+	articlePresence.Notify(article.ID)
+	c.JSON(http.StatusOK, gin.H{
+		"message": "已釋放編輯鎖",
+	})
+}
+
+// 管理員接手文章的編輯鎖，原持有者的下一次心跳會收到 409
+func TakeOverArticleLock(c *gin.Context) {
+	article, ok := findLockArticle(c)
+	if !ok {
+		return
+	}
+
+	previous, err := activeArticleLock(article.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	now := time.Now()
+
+	// 開始事務
+	tx := config.DB.Begin()
+
+	if err := tx.Where("article_id = ?", article.ID).Delete(&models.ArticleEditLock{}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrArticleLockFailed, err))
+		return
+	}
+
+	lock := models.ArticleEditLock{
+		ArticleID:   article.ID,
+		UserID:      userID.(uint),
+		AcquiredAt:  now,
+		HeartbeatAt: now,
+		ExpiresAt:   now.Add(editLockTTL),
+	}
+	if err := tx.Create(&lock).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrArticleLockFailed, err))
+		return
+	}
+
+	// 提交事務
+	tx.Commit()
+
+	articlePresence.Notify(article.ID)
+
+	// 原持有者（接手前沒有有效的鎖時為 null）
+	var previousHolder *models.User
+	if previous != nil && previous.UserID != lock.UserID {
+		previousHolder = &previous.User
+	}
+
+	config.DB.Preload("User").First(&lock, lock.ID)
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "已接手編輯鎖",
+		"lock":            lock,
+		"previous_holder": previousHolder,
+		"ttl_seconds":     int(editLockTTL.Seconds()),
+	})
+}
+
+// 載入文章的在線狀態：串流連線中的查看者，加上編輯鎖的持有者（標記為編輯中）
+func loadArticlePresence(articleID uint) (articlePresenceSnapshot, error) {
+	snapshot := articlePresenceSnapshot{
+		ArticleID: articleID,
+		Users:     articlePresence.Viewers(articleID),
+	}
+
+	lock, err := activeArticleLock(articleID)
+	if err != nil || lock == nil {
+		return snapshot, err
+	}
+	snapshot.Lock = lock
+
+	for i := range snapshot.Users {
+		if snapshot.Users[i].UserID == lock.UserID {
+			snapshot.Users[i].Status = "editing"
+			return snapshot, nil
+		}
+	}
+	snapshot.Users = append(snapshot.Users, services.PresenceUser{
+		UserID:   lock.UserID,
+		Username: lock.User.Username,
+		Status:   "editing",
+		Since:    lock.AcquiredAt,
+	})
+	return snapshot, nil
+}
+
+// 在線狀態的識別值：成員、狀態與鎖的持有者相同時視為未變更（心跳續約不會觸發推送）
+func presenceSignature(snapshot articlePresenceSnapshot) string {
+	var b strings.Builder
+	if snapshot.Lock != nil {
+		fmt.Fprintf(&b, "lock:%d;", snapshot.Lock.ID)
+	}
+	for _, user := range snapshot.Users {
+		fmt.Fprintf(&b, "%d:%s;", user.UserID, user.Status)
+	}
+	return b.String()
+}
+
+// 獲取文章的在線狀態
+func GetArticlePresence(c *gin.Context) {
+	article, ok := findLockArticle(c)
+	if !ok {
+		return
+	}
+
+	snapshot, err := loadArticlePresence(article.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, snapshot)
+}
+
+// 以 Server-Sent Events 推送文章的在線狀態；連線期間請求者列為查看者
+func StreamArticlePresence(c *gin.Context) {
+	article, ok := findLockArticle(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	var user models.User
+	if err := config.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	changes, leave := articlePresence.Join(article.ID, user.ID, user.Username)
+	defer leave()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	// 只在在線狀態變更時送出
+	var lastSignature string
+	send := func(w io.Writer, force bool) {
+		snapshot, err := loadArticlePresence(article.ID)
+		if err != nil {
+			return
+		}
+		signature := presenceSignature(snapshot)
+		if !force && signature == lastSignature {
+			return
+		}
+		lastSignature = signature
+		sse.Encode(w, sse.Event{Event: "presence", Data: snapshot})
+	}
+
+	// 定期檢查編輯鎖是否過期或由其他執行個體變更
+	poll := time.NewTicker(5 * time.Second)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(20 * time.Second)
+	defer heartbeat.Stop()
+
+	first := true
+	c.Stream(func(w io.Writer) bool {
+		if first {
+			first = false
+			sse.Encode(w, sse.Event{Event: "ready", Retry: 3000, Data: gin.H{"article_id": article.ID}})
+			send(w, true)
+			return true
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-changes:
+			send(w, false)
+		case <-poll.C:
+			send(w, false)
+		case <-heartbeat.C:
+			io.WriteString(w, ": ping\n\n")
+		}
+		return true
+	})
+}
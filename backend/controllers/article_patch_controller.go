@@ -0,0 +1,379 @@
+package controllers
+
+import (
+	"GolangBlog/config"
+	"GolangBlog/locales"
+	"GolangBlog/models"
+	"GolangBlog/utils"
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// 部分更新請求的媒體類型（RFC 7396）
+const mergePatchContentType = "application/merge-patch+json"
+
+// 文章的可修補文件：PATCH 的內容依 JSON Merge Patch 合併到此文件，
+// 翻譯以語言代碼為鍵，設為 null 即刪除該語言的翻譯
+type articlePatchDocument struct {
+	Status        string                                   `json:"status" binding:"required,oneof=draft published archived"`
+	FeaturedImage string                                   `json:"featured_image"`
+	IsFeatured    bool                                     `json:"is_featured"`
+	TagIDs        []uint                                   `json:"tag_ids"`
+	CategoryIDs   []uint                                   `json:"category_ids"`
+	Translations  map[string]articleTranslationPatchFields `json:"translations" binding:"dive"`
+}
+
+// 可修補的翻譯欄位；slug 設為 null 時依標題重新產生
+type articleTranslationPatchFields struct {
+	Title           string `json:"title" binding:"required"`
+	Slug            string `json:"slug"`
+	Excerpt         string `json:"excerpt"`
+	Content         string `json:"content" binding:"required"`
+	MetaTitle       string `json:"meta_title"`
+	MetaDescription string `json:"meta_description"`
+	MetaKeywords    string `json:"meta_keywords"`
+	SourceRevision  *int   `json:"source_revision,omitempty"` // 翻譯依據的來源語言版本，只在修補內容中指定
+}
+
+// 以 JSON Merge Patch 部分更新文章：只修改修補內容中出現的欄位，
+// 陣列（tag_ids、category_ids）整個取代，設為 null 的欄位清除或刪除
+func PatchArticle(c *gin.Context) {
+	// 檢查媒體類型（同時接受 application/json）
+	if mediaType, _, err := mime.ParseMediaType(c.ContentType()); err != nil ||
+		(mediaType != mergePatchContentType && mediaType != binding.MIMEJSON) {
+		c.Header("Accept-Patch", mergePatchContentType)
+		c.JSON(http.StatusUnsupportedMediaType, errorBody(c, locales.ErrPatchContentTypeUnsupported))
+		return
+	}
+
+	id := c.Param("id")
+	var article models.Article
+
+	// 確認文章存在
+	if err := config.DB.Preload("Translations").First(&article, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrArticleNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return
+	}
+
+	// 確認請求者修補的是最新版本
+	if !checkIfMatch(c, "article", article.ID, article.Version) {
+		return
+	}
+
+	// 修補內容必須是 JSON 物件
+	var patch map[string]interface{}
+	body, err := io.ReadAll(c.Request.Body)
+	if err == nil {
+		err = json.Unmarshal(body, &patch)
+	}
+	if err != nil || patch == nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	// 目前的文章文件
+	current, err := currentArticleDocument(&article)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	// 合併後重新解析並驗證（不接受未知欄位）
+	doc, err := mergeArticlePatch(current, patch)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	// 新增的翻譯語言必須存在
+	for langCode := range doc.Translations {
+		if _, ok := current.Translations[langCode]; ok {
+			continue
+		}
+		if _, ok := languageRegistry.Get(langCode); !ok {
+			c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrLanguageNotActive))
+			return
+		}
+	}
+
+	// 新增的標籤與分類必須存在
+	addTags, removeTags := idDifference(doc.TagIDs, current.TagIDs), idDifference(current.TagIDs, doc.TagIDs)
+	addCategories, removeCategories := idDifference(doc.CategoryIDs, current.CategoryIDs), idDifference(current.CategoryIDs, doc.CategoryIDs)
+	if !validateBulkLinks(c, &models.Tag{}, addTags, nil, locales.ErrArticleBulkTagsMissing) ||
+		!validateBulkLinks(c, &models.Category{}, addCategories, nil, locales.ErrArticleBulkCategoriesMissing) {
+		return
+	}
+
+	// 啟動事務
+	tx := config.DB.Begin()
+
+	// 遞增版本號（檢查後若已被其他請求修改則回傳 412）
+	if ok, err := bumpVersion(tx, &models.Article{}, article.ID, article.Version); !ok {
+		tx.Rollback()
+		respondBumpFailure(c, "article", &models.Article{}, article.ID, err)
+		return
+	}
+	article.Version++
+
+	// 更新文章基本資訊
+	prevStatus := article.Status
+	article.Status = doc.Status
+	article.FeaturedImage = doc.FeaturedImage
+	article.IsFeatured = doc.IsFeatured
+
+	// 如果狀態從非發布改為發布，設置發布時間
+	if prevStatus != "published" && doc.Status == "published" {
+		now := time.Now()
+		article.PublishedAt = &now
+	}
+
+	// 瀏覽數由瀏覽計數服務以累加方式寫入，不可用載入時的舊值覆蓋
+	if err := tx.Omit("Translations", "view_count").Save(&article).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleUpdateFailed))
+		return
+	}
+
+	// 處理翻譯
+	tracker, err := newRevisionTracker(tx, &models.ArticleTranslation{}, "article_id", article.ID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrTranslationLoadFailed))
+		return
+	}
+
+	existing := make(map[string]*models.ArticleTranslation, len(article.Translations))
+	for i := range article.Translations {
+		existing[article.Translations[i].LanguageCode] = &article.Translations[i]
+	}
+
+	// 刪除修補內容中設為 null 的語言（來源語言不能刪除）；
+	// 直接移除資料列，之後才能以相同語言重新建立翻譯
+	for langCode, translation := range existing {
+		if _, ok := doc.Translations[langCode]; ok {
+			continue
+		}
+		if tracker.isSource(langCode) {
+			tx.Rollback()
+			c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrArticleSourceTranslationDelete, locales.Params{"language": langCode}))
+			return
+		}
+		if err := tx.Unscoped().Delete(translation).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleTranslationDeleteFailed))
+			return
+		}
+	}
+
+	// 來源語言排在最前面，其他語言依代碼排序
+	languages := make([]string, 0, len(doc.Translations))
+	for langCode := range doc.Translations {
+		languages = append(languages, langCode)
+	}
+	sort.Strings(languages)
+	tracker.sortSourceFirst(languages, func(i int) string { return languages[i] })
+
+	var changedLanguages []string
+	for _, langCode := range languages {
+		fields := doc.Translations[langCode]
+		translation, found := existing[langCode]
+		if found && fields == currentTranslationFields(translation) && fields.SourceRevision == nil {
+			continue
+		}
+		changedLanguages = append(changedLanguages, langCode)
+
+		// 決定 slug：未提供時依標題產生並避免重複，提供的 slug 衝突時回傳 409
+		slugValue, conflict, err := resolveSlug(tx, translationTargets["article"], article.ID, langCode, fields.Slug, fields.Title)
+		if err != nil || conflict != nil {
+			tx.Rollback()
+			respondSlugError(c, langCode, conflict, err)
+			return
+		}
+
+		// 摘要超過欄位長度時截斷（不切斷組合字元與方向控制）
+		fields.Excerpt = utils.TruncateText(fields.Excerpt, maxExcerptLength)
+
+		if !found {
+			translation = &models.ArticleTranslation{ArticleID: article.ID, LanguageCode: langCode}
+			tracker.apply(langCode, true, fields.SourceRevision, &translation.TranslationStatus)
+		} else {
+			changed := translation.Title != fields.Title || translation.Excerpt != fields.Excerpt || translation.Content != fields.Content
+			tracker.apply(langCode, changed, fields.SourceRevision, &translation.TranslationStatus)
+
+			// slug 變更時保留舊 slug 作為轉址
+			if err := recordSlugChange(tx, "article", article.ID, langCode, translation.Slug, slugValue); err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrArticleTranslationUpdateFailed, err))
+				return
+			}
+		}
+
+		translation.Title = fields.Title
+		translation.Slug = slugValue
+		translation.Excerpt = fields.Excerpt
+		translation.Content = fields.Content
+		translation.MetaTitle = fields.MetaTitle
+		translation.MetaDescription = fields.MetaDescription
+		translation.MetaKeywords = fields.MetaKeywords
+
+		if !found {
+			// 移除同語言已軟刪除的翻譯，避免違反唯一索引
+			if err := tx.Unscoped().Where("article_id = ? AND language_code = ? AND deleted_at IS NOT NULL", article.ID, langCode).
+				Delete(&models.ArticleTranslation{}).Error; err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleTranslationCreateFailed))
+				return
+			}
+			if err := tx.Create(translation).Error; err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleTranslationCreateFailed))
+				return
+			}
+		} else if err := tx.Save(translation).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleTranslationUpdateFailed))
+			return
+		}
+	}
+
+	// 來源語言內容變更時，標記其他語言翻譯待更新
+	if err := tracker.markStale(tx, &models.ArticleTranslation{}, "article_id", article.ID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrTranslationStatusUpdateFailed))
+		return
+	}
+
+	// 處理標籤與分類關聯（只寫入有變更的部分）
+	if err := updateArticleLinks(tx, "article_tags", "tag_id", article.ID, addTags, removeTags); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleTagsLinkFailed))
+		return
+	}
+
+	if err := updateArticleLinks(tx, "article_categories", "category_id", article.ID, addCategories, removeCategories); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrArticleCategoriesLinkFailed))
+		return
+	}
+
+	// 提交事務
+	tx.Commit()
+
+	// 翻譯內容已變更，清除翻譯記憶快取
+	translationMemory.Invalidate()
+
+	// 標籤、分類或內容已變更，相關文章需重新計算
+	relatedService.Invalidate(article.ID)
+
+	// 獲取完整的文章數據回傳
+	var fullArticle models.Article
+	config.DB.Preload("Translations").Preload("Tags").Preload("User").First(&fullArticle, article.ID)
+
+	// 譯文未使用詞彙表指定的譯法時提出警告（不影響儲存）
+	glossaryWarnings := articleGlossaryWarnings(fullArticle.Translations, changedLanguages)
+
+	setETag(c, "article", fullArticle.ID, fullArticle.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "文章更新成功",
+		"article":           fullArticle,
+		"glossary_warnings": glossaryWarnings,
+	})
+}
+
+// 建立文章目前的可修補文件
+func currentArticleDocument(article *models.Article) (*articlePatchDocument, error) {
+	doc := &articlePatchDocument{
+		Status:        article.Status,
+		FeaturedImage: article.FeaturedImage,
+		IsFeatured:    article.IsFeatured,
+		TagIDs:        []uint{},
+		CategoryIDs:   []uint{},
+		Translations:  make(map[string]articleTranslationPatchFields, len(article.Translations)),
+	}
+	for i := range article.Translations {
+		doc.Translations[article.Translations[i].LanguageCode] = currentTranslationFields(&article.Translations[i])
+	}
+
+	if err := config.DB.Table("article_tags").Where("article_id = ?", article.ID).
+		Order("tag_id asc").Pluck("tag_id", &doc.TagIDs).Error; err != nil {
+		return nil, err
+	}
+	if err := config.DB.Table("article_categories").Where("article_id = ?", article.ID).
+		Order("category_id asc").Pluck("category_id", &doc.CategoryIDs).Error; err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// 翻譯目前的可修補欄位
+func currentTranslationFields(translation *models.ArticleTranslation) articleTranslationPatchFields {
+	return articleTranslationPatchFields{
+		Title:           translation.Title,
+		Slug:            translation.Slug,
+		Excerpt:         translation.Excerpt,
+		Content:         translation.Content,
+		MetaTitle:       translation.MetaTitle,
+		MetaDescription: translation.MetaDescription,
+		MetaKeywords:    translation.MetaKeywords,
+	}
+}
+
+// 將修補內容合併到目前的文件，重新解析並驗證結果
+func mergeArticlePatch(current *articlePatchDocument, patch map[string]interface{}) (*articlePatchDocument, error) {
+	data, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+	var target interface{}
+	if err := json.Unmarshal(data, &target); err != nil {
+		return nil, err
+	}
+
+	merged, err := json.Marshal(utils.MergePatch(target, patch))
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	doc := &articlePatchDocument{}
+	if err := decoder.Decode(doc); err != nil {
+		return nil, err
+	}
+	doc.TagIDs = uniqueIDs(doc.TagIDs)
+	doc.CategoryIDs = uniqueIDs(doc.CategoryIDs)
+
+	if err := binding.Validator.ValidateStruct(doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// 取得在 ids 中但不在 exclude 中的 ID
+func idDifference(ids, exclude []uint) []uint {
+	excluded := make(map[uint]bool, len(exclude))
+	for _, id := range exclude {
+		excluded[id] = true
+	}
+
+	var result []uint
+	for _, id := range ids {
+		if !excluded[id] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
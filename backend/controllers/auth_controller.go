@@ -2,10 +2,13 @@ package controllers
 
 import (
 	"GolangBlog/config"
+	"GolangBlog/locales"
 	"GolangBlog/models"
+	"GolangBlog/services"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -15,11 +18,13 @@ import (
 
 // 註冊請求結構
 type RegisterRequest struct {
-	Username  string `json:"username" binding:"required,min=3,max=50"`
-	Email     string `json:"email" binding:"required,email"`
-	Password  string `json:"password" binding:"required,min=6"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	Username   string `json:"username" binding:"required,min=3,max=50"`
+	Email      string `json:"email" binding:"required,email"`
+	Password   string `json:"password" binding:"required,min=6"`
+	FirstName  string `json:"first_name"`
+	LastName   string `json:"last_name"`
+	Website    string `json:"website"`          // 蜜罐欄位，前端應隱藏且不填寫
+	RenderedAt int64  `json:"form_rendered_at"` // 表單顯示時間（Unix 毫秒）
 }
 
 // 登入請求結構
@@ -39,21 +44,38 @@ type Claims struct {
 func Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
 		return
 	}
 
 	// 檢查使用者是否已存在
 	var existingUser models.User
 	if result := config.DB.Where("email = ?", req.Email).Or("username = ?", req.Username).First(&existingUser); result.RowsAffected > 0 {
-		c.JSON(http.StatusConflict, gin.H{"error": "使用者已存在"})
+		c.JSON(http.StatusConflict, errorBody(c, locales.ErrUserExists))
+		return
+	}
+
+	// 垃圾訊息檢查
+	sub := &services.SpamSubmission{
+		Kind:        services.SpamKindRegister,
+		Content:     strings.TrimSpace(req.Username + " " + req.FirstName + " " + req.LastName),
+		AuthorName:  req.Username,
+		AuthorEmail: req.Email,
+		Honeypot:    req.Website,
+	}
+	if req.RenderedAt > 0 {
+		sub.RenderedAt = time.UnixMilli(req.RenderedAt)
+	}
+	spamResult, spamLog := checkSpam(c, sub)
+	if spamResult.Verdict == services.SpamVerdictSpam {
+		c.JSON(http.StatusForbidden, errorBody(c, locales.ErrRegistrationSpam))
 		return
 	}
 
 	// 密碼雜湊處理
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "密碼處理失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrPasswordHashFailed))
 		return
 	}
 
@@ -68,11 +90,19 @@ func Register(c *gin.Context) {
 		Status:    "active",
 	}
 
+	// 可疑的註冊需等待審核後才能登入
+	if spamResult.Verdict == services.SpamVerdictSuspect {
+		user.Status = "pending"
+	}
+
 	if result := config.DB.Create(&user); result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "建立使用者失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrUserCreateFailed))
 		return
 	}
 
+	// 關聯檢查紀錄與新使用者，供審核時啟用或停用帳號
+	config.DB.Model(spamLog).Update("reference_id", user.ID)
+
 	// 不回傳密碼
 	user.Password = ""
 
@@ -86,26 +116,26 @@ func Register(c *gin.Context) {
 func Login(c *gin.Context) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
 		return
 	}
 
 	// 查詢使用者
 	var user models.User
 	if result := config.DB.Where("email = ?", req.Email).First(&user); result.Error != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "使用者不存在或密碼錯誤"})
+		c.JSON(http.StatusUnauthorized, errorBody(c, locales.ErrInvalidCredentials))
 		return
 	}
 
 	// 驗證密碼
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "使用者不存在或密碼錯誤"})
+		c.JSON(http.StatusUnauthorized, errorBody(c, locales.ErrInvalidCredentials))
 		return
 	}
 
 	// 檢查使用者狀態
 	if user.Status != "active" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "使用者帳號已被停用"})
+		c.JSON(http.StatusForbidden, errorBody(c, locales.ErrUserDisabled))
 		return
 	}
 
@@ -128,7 +158,7 @@ func Login(c *gin.Context) {
 	tokenString, err := token.SignedString(jwtKey)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "無法生成身份驗證令牌"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrTokenGenerateFailed))
 		return
 	}
 
@@ -147,13 +177,13 @@ func GetCurrentUser(c *gin.Context) {
 	// 從 middleware 中獲取使用者 ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "使用者未認證"})
+		c.JSON(http.StatusUnauthorized, errorBody(c, locales.ErrUnauthenticated))
 		return
 	}
 
 	var user models.User
 	if result := config.DB.First(&user, userID); result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "使用者不存在"})
+		c.JSON(http.StatusNotFound, errorBody(c, locales.ErrUserNotFound))
 		return
 	}
 
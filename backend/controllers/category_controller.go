@@ -2,12 +2,12 @@ package controllers
 
 import (
 	"GolangBlog/config"
+	"GolangBlog/locales"
 	"GolangBlog/models"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gosimple/slug"
 	"gorm.io/gorm"
 )
 
@@ -19,10 +19,11 @@ type CategoryRequest struct {
 
 // 分類翻譯請求結構
 type CategoryTranslationRequest struct {
-	LanguageCode string `json:"language_code" binding:"required"`
-	Name         string `json:"name" binding:"required"`
-	Slug         string `json:"slug"`
-	Description  string `json:"description"`
+	LanguageCode   string `json:"language_code" binding:"required"`
+	Name           string `json:"name" binding:"required"`
+	Slug           string `json:"slug"`
+	Description    string `json:"description"`
+	SourceRevision *int   `json:"source_revision"` // 翻譯依據的來源語言版本，未指定時視為目前版本
 }
 
 // 獲取分類列表
@@ -45,21 +46,25 @@ func GetCategories(c *gin.Context) {
 	}
 
 	// 語言篩選
-	langCode := c.Query("lang")
+	langCode := explicitLanguage(c)
+	var chain []string
 
-	// 預載相關數據
+	// 預載相關數據（指定語言時依退回順序）
 	if langCode != "" {
-		query = query.Preload("Translations", func(db *gorm.DB) *gorm.DB {
-			return db.Where("language_code = ?", langCode)
-		})
+		chain = languageChain(c, langCode)
+		query = query.Preload("Translations", translationScope(c, chain))
 	} else {
-		query = query.Preload("Translations")
+		query = query.Preload("Translations", translationScope(c, nil))
 	}
 
 	// 是否包含父分類信息
 	includeParent := c.Query("include_parent")
 	if includeParent == "true" {
-		query = query.Preload("Parent")
+		if chain != nil {
+			query = query.Preload("Parent.Translations", translationScope(c, chain))
+		} else {
+			query = query.Preload("Parent")
+		}
 	}
 
 	// 排序
@@ -77,6 +82,10 @@ func GetCategories(c *gin.Context) {
 
 	query.Limit(pageSize).Offset(offset).Find(&categories)
 
+	if chain != nil {
+		localizeCategories(categories, chain)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"categories": categories,
 		"pagination": gin.H{
@@ -93,32 +102,78 @@ func GetCategory(c *gin.Context) {
 	id := c.Param("id")
 	var category models.Category
 
-	// 預載相關數據
-	query := config.DB.Preload("Translations")
+	// 預載相關數據（只包含請求者可查看的語言）
+	query := config.DB.Preload("Translations", translationScope(c, nil))
 
 	// 是否包含父分類信息
 	includeParent := c.Query("include_parent")
 	if includeParent == "true" {
-		query = query.Preload("Parent.Translations")
+		query = query.Preload("Parent.Translations", translationScope(c, nil))
 	}
 
 	if err := query.First(&category, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "分類不存在"})
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrCategoryNotFound))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		}
 		return
 	}
 
+	setETag(c, "category", category.ID, category.Version)
 	c.JSON(http.StatusOK, gin.H{"category": category})
 }
 
+// 依 slug 獲取分類（依語言退回順序；舊 slug 轉址到目前的 slug）
+func GetCategoryBySlug(c *gin.Context) {
+	categorySlug := c.Param("slug")
+	langCode, chain := requestLanguage(c)
+
+	var matches []models.CategoryTranslation
+	if err := config.DB.Where("slug = ? AND language_code IN ?", categorySlug, chain).Scopes(reviewedScope(c)).Find(&matches).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	codes := make([]string, len(matches))
+	for i, match := range matches {
+		codes[i] = match.LanguageCode
+	}
+	idx := pickLanguage(chain, codes)
+	if idx < 0 {
+		if redirectOldSlug(c, "category", categorySlug, chain) {
+			return
+		}
+		c.JSON(http.StatusNotFound, errorBody(c, locales.ErrCategoryNotFound))
+		return
+	}
+
+	var category models.Category
+	if err := config.DB.Preload("Translations", translationScope(c, chain)).First(&category, matches[idx].CategoryID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrCategoryNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return
+	}
+
+	categories := []models.Category{category}
+	localizeCategories(categories, chain)
+
+	setETag(c, "category", category.ID, category.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"category":           categories[0],
+		"requested_language": langCode,
+		"served_language":    categories[0].ServedLanguage,
+	})
+}
+
 // 創建分類
 func CreateCategory(c *gin.Context) {
 	var req CategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
 		return
 	}
 
@@ -127,9 +182,9 @@ func CreateCategory(c *gin.Context) {
 		var parentCategory models.Category
 		if err := config.DB.First(&parentCategory, *req.ParentID).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "父分類不存在"})
+				c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrParentCategoryNotFound))
 			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 			}
 			return
 		}
@@ -145,16 +200,28 @@ func CreateCategory(c *gin.Context) {
 
 	if err := tx.Create(&category).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "創建分類失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrCategoryCreateFailed))
 		return
 	}
 
 	// 處理翻譯
+	tracker, err := newRevisionTracker(tx, &models.CategoryTranslation{}, "category_id", category.ID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrTranslationLoadFailed))
+		return
+	}
+	tracker.sortSourceFirst(req.Translations, func(i int) string { return req.Translations[i].LanguageCode })
+
 	for _, trans := range req.Translations {
-		// 如果沒有提供 slug，則自動生成
-		if trans.Slug == "" {
-			trans.Slug = slug.Make(trans.Name)
+		// 決定 slug：未提供時自動生成並避免重複，提供的 slug 衝突時回傳 409
+		slugValue, conflict, err := resolveSlug(tx, translationTargets["category"], category.ID, trans.LanguageCode, trans.Slug, trans.Name)
+		if err != nil || conflict != nil {
+			tx.Rollback()
+			respondSlugError(c, trans.LanguageCode, conflict, err)
+			return
 		}
+		trans.Slug = slugValue
 
 		translation := models.CategoryTranslation{
 			CategoryID:   category.ID,
@@ -163,10 +230,11 @@ func CreateCategory(c *gin.Context) {
 			Slug:         trans.Slug,
 			Description:  trans.Description,
 		}
+		tracker.apply(trans.LanguageCode, true, trans.SourceRevision, &translation.TranslationStatus)
 
 		if err := tx.Create(&translation).Error; err != nil {
 			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "創建分類翻譯失敗"})
+			c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrCategoryTranslationCreateFailed))
 			return
 		}
 	}
@@ -174,10 +242,14 @@ func CreateCategory(c *gin.Context) {
 	// 提交事務
 	tx.Commit()
 
+	// 翻譯內容已變更，清除翻譯記憶快取
+	translationMemory.Invalidate()
+
 	// 獲取完整的分類數據回傳
 	var fullCategory models.Category
 	config.DB.Preload("Translations").Preload("Parent.Translations").First(&fullCategory, category.ID)
 
+	setETag(c, "category", fullCategory.ID, fullCategory.Version)
 	c.JSON(http.StatusCreated, gin.H{
 		"message":  "分類創建成功",
 		"category": fullCategory,
@@ -192,16 +264,21 @@ func UpdateCategory(c *gin.Context) {
 	// 確認分類存在
 	if err := config.DB.First(&category, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "分類不存在"})
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrCategoryNotFound))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		}
 		return
 	}
 
+	// 確認請求者編輯的是最新版本
+	if !checkIfMatch(c, "category", category.ID, category.Version) {
+		return
+	}
+
 	var req CategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
 		return
 	}
 
@@ -209,7 +286,7 @@ func UpdateCategory(c *gin.Context) {
 	if req.ParentID != nil {
 		// 不能將分類的父分類設為自己
 		if *req.ParentID == category.ID {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "不能將分類的父分類設為自己"})
+			c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrCategoryParentSelf))
 			return
 		}
 
@@ -217,9 +294,9 @@ func UpdateCategory(c *gin.Context) {
 		var parentCategory models.Category
 		if err := config.DB.First(&parentCategory, *req.ParentID).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "父分類不存在"})
+				c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrParentCategoryNotFound))
 			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 			}
 			return
 		}
@@ -227,12 +304,12 @@ func UpdateCategory(c *gin.Context) {
 		// 不能將分類的父分類設為其子分類
 		var count int64
 		if err := config.DB.Model(&models.Category{}).Where("id = ? AND parent_id = ?", *req.ParentID, category.ID).Count(&count).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 			return
 		}
 
 		if count > 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "不能將分類的父分類設為其子分類"})
+			c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrCategoryParentDescendant))
 			return
 		}
 	}
@@ -240,20 +317,40 @@ func UpdateCategory(c *gin.Context) {
 	// 啟動事務
 	tx := config.DB.Begin()
 
+	// 遞增版本號（檢查後若已被其他請求修改則回傳 412）
+	if ok, err := bumpVersion(tx, &models.Category{}, category.ID, category.Version); !ok {
+		tx.Rollback()
+		respondBumpFailure(c, "category", &models.Category{}, category.ID, err)
+		return
+	}
+	category.Version++
+
 	// 更新分類基本信息
 	category.ParentID = req.ParentID
 	if err := tx.Save(&category).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新分類失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrCategoryUpdateFailed))
 		return
 	}
 
 	// 處理翻譯
+	tracker, err := newRevisionTracker(tx, &models.CategoryTranslation{}, "category_id", category.ID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrTranslationLoadFailed))
+		return
+	}
+	tracker.sortSourceFirst(req.Translations, func(i int) string { return req.Translations[i].LanguageCode })
+
 	for _, trans := range req.Translations {
-		// 如果沒有提供 slug，則自動生成
-		if trans.Slug == "" {
-			trans.Slug = slug.Make(trans.Name)
+		// 決定 slug：未提供時自動生成並避免重複，提供的 slug 衝突時回傳 409
+		slugValue, conflict, err := resolveSlug(tx, translationTargets["category"], category.ID, trans.LanguageCode, trans.Slug, trans.Name)
+		if err != nil || conflict != nil {
+			tx.Rollback()
+			respondSlugError(c, trans.LanguageCode, conflict, err)
+			return
 		}
+		trans.Slug = slugValue
 
 		var translation models.CategoryTranslation
 		// 檢查該語言的翻譯是否已存在
@@ -269,38 +366,59 @@ func UpdateCategory(c *gin.Context) {
 					Slug:         trans.Slug,
 					Description:  trans.Description,
 				}
+				tracker.apply(trans.LanguageCode, true, trans.SourceRevision, &translation.TranslationStatus)
 
 				if err := tx.Create(&translation).Error; err != nil {
 					tx.Rollback()
-					c.JSON(http.StatusInternalServerError, gin.H{"error": "創建分類翻譯失敗"})
+					c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrCategoryTranslationCreateFailed))
 					return
 				}
 			} else {
 				tx.Rollback()
-				c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+				c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, result.Error))
 				return
 			}
 		} else {
 			// 更新已存在的翻譯
+			changed := translation.Name != trans.Name || translation.Description != trans.Description
+			tracker.apply(trans.LanguageCode, changed, trans.SourceRevision, &translation.TranslationStatus)
+
 			translation.Name = trans.Name
+			// slug 變更時保留舊 slug 作為轉址
+			if err := recordSlugChange(tx, "category", category.ID, trans.LanguageCode, translation.Slug, trans.Slug); err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrCategoryTranslationUpdateFailed, err))
+				return
+			}
 			translation.Slug = trans.Slug
 			translation.Description = trans.Description
 
 			if err := tx.Save(&translation).Error; err != nil {
 				tx.Rollback()
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "更新分類翻譯失敗"})
+				c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrCategoryTranslationUpdateFailed))
 				return
 			}
 		}
 	}
 
+	// 來源語言內容變更時，標記其他語言翻譯待更新
+	if err := tracker.markStale(tx, &models.CategoryTranslation{}, "category_id", category.ID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrTranslationStatusUpdateFailed))
+		return
+	}
+
 	// 提交事務
 	tx.Commit()
 
+	// 翻譯內容已變更，清除翻譯記憶快取
+	translationMemory.Invalidate()
+
 	// 獲取完整的分類數據回傳
 	var fullCategory models.Category
 	config.DB.Preload("Translations").Preload("Parent.Translations").First(&fullCategory, category.ID)
 
+	setETag(c, "category", fullCategory.ID, fullCategory.Version)
 	c.JSON(http.StatusOK, gin.H{
 		"message":  "分類更新成功",
 		"category": fullCategory,
@@ -315,57 +433,75 @@ func DeleteCategory(c *gin.Context) {
 	// 確認分類存在
 	if err := config.DB.First(&category, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "分類不存在"})
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrCategoryNotFound))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		}
 		return
 	}
 
+	// 確認請求者刪除的是最新版本
+	if !checkIfMatch(c, "category", category.ID, category.Version) {
+		return
+	}
+
 	// 檢查是否有子分類
 	var childrenCount int64
 	if err := config.DB.Model(&models.Category{}).Where("parent_id = ?", id).Count(&childrenCount).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		return
 	}
 
 	if childrenCount > 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "該分類下有子分類，請先刪除子分類"})
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrCategoryHasChildren))
 		return
 	}
 
 	// 檢查分類是否被使用
 	var articleCount int64
 	if err := config.DB.Model(&models.ArticleCategory{}).Where("category_id = ?", id).Count(&articleCount).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		return
 	}
 
 	if articleCount > 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "該分類已被文章使用，不能刪除"})
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrCategoryInUse))
 		return
 	}
 
 	// 啟動事務
 	tx := config.DB.Begin()
 
+	// 遞增版本號（檢查後若已被其他請求修改則回傳 412）
+	if ok, err := bumpVersion(tx, &models.Category{}, category.ID, category.Version); !ok {
+		tx.Rollback()
+		respondBumpFailure(c, "category", &models.Category{}, category.ID, err)
+		return
+	}
+
 	// 刪除翻譯
 	if err := tx.Where("category_id = ?", id).Delete(&models.CategoryTranslation{}).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "刪除分類翻譯失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrCategoryTranslationDeleteFailed))
 		return
 	}
 
 	// 刪除分類本身
 	if err := tx.Delete(&category).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "刪除分類失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrCategoryDeleteFailed))
 		return
 	}
 
 	// 提交事務
 	tx.Commit()
 
+	// 翻譯內容已變更，清除翻譯記憶快取
+	translationMemory.Invalidate()
+
+	// 文章的分類關聯已移除，相關文章需重新計算
+	relatedService.Clear()
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "分類刪除成功",
 	})
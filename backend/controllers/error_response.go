@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"GolangBlog/locales"
+	"GolangBlog/middlewares"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 產生在地化的錯誤回應
+func errorBody(c *gin.Context, code string, params ...locales.Params) gin.H {
+	return middlewares.ErrorBody(c, code, params...)
+}
+
+// 產生附帶原始錯誤細節的錯誤回應；帶有錯誤代碼的錯誤直接使用其代碼
+func errorDetail(c *gin.Context, code string, err error) gin.H {
+	var codedErr *locales.Error
+	if errors.As(err, &codedErr) {
+		return errorBody(c, codedErr.Code, codedErr.Params)
+	}
+
+	body := errorBody(c, code)
+	body["detail"] = err.Error()
+	return body
+}
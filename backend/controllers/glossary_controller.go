@@ -0,0 +1,389 @@
+package controllers
+
+import (
+	"GolangBlog/config"
+	"GolangBlog/locales"
+	"GolangBlog/models"
+	"GolangBlog/services"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 翻譯記憶服務
+var translationMemory *services.TranslationMemoryService
+
+// 單次查詢翻譯記憶的段落上限
+const maxMemorySegments = 100
+
+// 詞彙請求結構
+type GlossaryTermRequest struct {
+	SourceLanguage string `json:"source_language"` // 未指定時使用預設語言
+	SourceTerm     string `json:"source_term" binding:"required,max=100"`
+	TargetLanguage string `json:"target_language" binding:"required"`
+	TargetTerm     string `json:"target_term" binding:"required,max=100"`
+	CaseSensitive  bool   `json:"case_sensitive"`
+	Note           string `json:"note" binding:"max=255"`
+}
+
+// 翻譯記憶查詢請求結構
+type TranslationMemoryRequest struct {
+	SourceLanguage string   `json:"source_language"` // 未指定時使用預設語言
+	TargetLanguage string   `json:"target_language" binding:"required"`
+	Segments       []string `json:"segments" binding:"required,min=1,dive,max=2000"` // 每個段落最多 2000 字元
+	Limit          int      `json:"limit"`                                           // 每個段落的建議數量，預設 5
+	MinScore       float64  `json:"min_score"`                                       // 最低相似度（0-1），未指定時使用預設值
+}
+
+// 詞彙表檢查請求結構
+type GlossaryCheckRequest struct {
+	SourceLanguage string `json:"source_language"` // 未指定時使用預設語言
+	TargetLanguage string `json:"target_language" binding:"required"`
+	Source         string `json:"source" binding:"required"`
+	Target         string `json:"target" binding:"required"`
+}
+
+// 文章翻譯的詞彙表警告
+type glossaryWarning struct {
+	LanguageCode string `json:"language_code"`
+	services.GlossaryViolation
+}
+
+// 初始化翻譯記憶服務（需在資料庫連接後呼叫）
+func InitTranslationMemory(db *gorm.DB) {
+	minScore, err := strconv.ParseFloat(os.Getenv("TRANSLATION_MEMORY_MIN_SCORE"), 64)
+	if err != nil {
+		minScore = 0.7
+	}
+	cacheTTL, err := time.ParseDuration(os.Getenv("TRANSLATION_MEMORY_CACHE_TTL"))
+	if err != nil {
+		cacheTTL = 10 * time.Minute
+	}
+
+	translationMemory = services.NewTranslationMemoryService(services.TranslationMemoryConfig{
+		MinScore: minScore,
+		CacheTTL: cacheTTL,
+	}, db)
+}
+
+// 查詢翻譯記憶：依既有翻譯提供每個段落的模糊比對建議
+func SuggestTranslations(c *gin.Context) {
+	var req TranslationMemoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	sourceLang, ok := glossaryLanguages(req.SourceLanguage, req.TargetLanguage)
+	if !ok {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrGlossaryLanguagesInvalid))
+		return
+	}
+
+	segments := req.Segments
+	if len(segments) > maxMemorySegments {
+		segments = segments[:maxMemorySegments]
+	}
+	limit := 5
+	if req.Limit > 0 {
+		limit = min(req.Limit, 20)
+	}
+
+	results := make([]gin.H, 0, len(segments))
+	for _, segment := range segments {
+		suggestions, err := translationMemory.Suggest(segment, sourceLang, req.TargetLanguage, limit, req.MinScore)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrTranslationMemoryFailed, err))
+			return
+		}
+		results = append(results, gin.H{
+			"segment":     segment,
+			"suggestions": suggestions,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"source_language": sourceLang,
+		"target_language": req.TargetLanguage,
+		"results":         results,
+	})
+}
+
+// 獲取詞彙表
+func GetGlossaryTerms(c *gin.Context) {
+	var terms []models.GlossaryTerm
+	query := config.DB.Model(&models.GlossaryTerm{})
+
+	// 語言篩選
+	if sourceLang := c.Query("source_language"); sourceLang != "" {
+		query = query.Where("source_language = ?", sourceLang)
+	}
+	if targetLang := c.Query("target_language"); targetLang != "" {
+		query = query.Where("target_language = ?", targetLang)
+	}
+
+	// 關鍵字搜尋
+	if keyword := strings.TrimSpace(c.Query("q")); keyword != "" {
+		pattern := "%" + strings.ToLower(keyword) + "%"
+		query = query.Where("LOWER(source_term) LIKE ? OR LOWER(target_term) LIKE ?", pattern, pattern)
+	}
+
+	// 分頁
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+	offset := (page - 1) * pageSize
+
+	var total int64
+	query.Count(&total)
+
+	if err := query.Order("source_language, target_language, source_term").Limit(pageSize).Offset(offset).Find(&terms).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"terms": terms,
+		"pagination": gin.H{
+			"total":      total,
+			"page":       page,
+			"page_size":  pageSize,
+			"total_page": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// 新增詞彙（已刪除的相同詞彙會被還原）
+func CreateGlossaryTerm(c *gin.Context) {
+	var req GlossaryTermRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	term, ok := bindGlossaryTerm(c, req)
+	if !ok {
+		return
+	}
+
+	// 唯一索引包含已刪除的詞彙
+	var existing models.GlossaryTerm
+	result := config.DB.Unscoped().
+		Where("source_language = ? AND source_term = ? AND target_language = ?", term.SourceLanguage, term.SourceTerm, term.TargetLanguage).
+		Limit(1).Find(&existing)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, result.Error))
+		return
+	}
+	if result.RowsAffected > 0 {
+		if !existing.DeletedAt.Valid {
+			c.JSON(http.StatusConflict, errorBody(c, locales.ErrGlossaryTermExists))
+			return
+		}
+		term.ID = existing.ID
+		term.CreatedAt = existing.CreatedAt
+	}
+
+	if err := config.DB.Unscoped().Save(&term).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "詞彙新增成功",
+		"term":    term,
+	})
+}
+
+// 更新詞彙
+func UpdateGlossaryTerm(c *gin.Context) {
+	var term models.GlossaryTerm
+	if err := config.DB.First(&term, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrGlossaryTermNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return
+	}
+
+	var req GlossaryTermRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	updated, ok := bindGlossaryTerm(c, req)
+	if !ok {
+		return
+	}
+
+	// 檢查是否與其他詞彙重複（包含已刪除的詞彙）
+	var count int64
+	if err := config.DB.Unscoped().Model(&models.GlossaryTerm{}).
+		Where("source_language = ? AND source_term = ? AND target_language = ? AND id <> ?", updated.SourceLanguage, updated.SourceTerm, updated.TargetLanguage, term.ID).
+		Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, errorBody(c, locales.ErrGlossaryTermExists))
+		return
+	}
+
+	term.SourceLanguage = updated.SourceLanguage
+	term.SourceTerm = updated.SourceTerm
+	term.TargetLanguage = updated.TargetLanguage
+	term.TargetTerm = updated.TargetTerm
+	term.CaseSensitive = updated.CaseSensitive
+	term.Note = updated.Note
+
+	if err := config.DB.Save(&term).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "詞彙更新成功",
+		"term":    term,
+	})
+}
+
+// 刪除詞彙
+func DeleteGlossaryTerm(c *gin.Context) {
+	result := config.DB.Delete(&models.GlossaryTerm{}, c.Param("id"))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, result.Error))
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, errorBody(c, locales.ErrGlossaryTermNotFound))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "詞彙刪除成功",
+	})
+}
+
+// 檢查譯文是否符合詞彙表
+func CheckGlossary(c *gin.Context) {
+	var req GlossaryCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	sourceLang, ok := glossaryLanguages(req.SourceLanguage, req.TargetLanguage)
+	if !ok {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrGlossaryLanguagesInvalid))
+		return
+	}
+
+	var terms []models.GlossaryTerm
+	if err := config.DB.Where("source_language = ? AND target_language = ?", sourceLang, req.TargetLanguage).Find(&terms).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"source_language": sourceLang,
+		"target_language": req.TargetLanguage,
+		"violations":      services.CheckGlossary(req.Source, req.Target, terms),
+	})
+}
+
+// 檢查文章各語言翻譯是否符合詞彙表；只檢查 languages 中的語言，來源語言為預設語言
+func articleGlossaryWarnings(translations []models.ArticleTranslation, languages []string) []glossaryWarning {
+	warnings := []glossaryWarning{}
+
+	sourceLang := languageRegistry.DefaultCode()
+	var source *models.ArticleTranslation
+	for i := range translations {
+		if translations[i].LanguageCode == sourceLang {
+			source = &translations[i]
+		}
+	}
+	if source == nil {
+		return warnings
+	}
+
+	requested := make(map[string]bool, len(languages))
+	for _, langCode := range languages {
+		requested[langCode] = true
+	}
+
+	var terms []models.GlossaryTerm
+	if err := config.DB.Where("source_language = ? AND target_language IN ?", sourceLang, languages).Find(&terms).Error; err != nil || len(terms) == 0 {
+		return warnings
+	}
+	termsByLang := map[string][]models.GlossaryTerm{}
+	for _, term := range terms {
+		termsByLang[term.TargetLanguage] = append(termsByLang[term.TargetLanguage], term)
+	}
+
+	for _, translation := range translations {
+		langTerms := termsByLang[translation.LanguageCode]
+		if !requested[translation.LanguageCode] || len(langTerms) == 0 {
+			continue
+		}
+
+		fields := []struct {
+			name           string
+			source, target string
+		}{
+			{"title", source.Title, translation.Title},
+			{"excerpt", source.Excerpt, translation.Excerpt},
+			{"content", source.Content, translation.Content},
+		}
+		for _, field := range fields {
+			for _, violation := range services.CheckGlossary(field.source, field.target, langTerms) {
+				violation.Field = field.name
+				warnings = append(warnings, glossaryWarning{LanguageCode: translation.LanguageCode, GlossaryViolation: violation})
+			}
+		}
+	}
+
+	return warnings
+}
+
+// 驗證詞彙請求並轉為模型
+func bindGlossaryTerm(c *gin.Context, req GlossaryTermRequest) (models.GlossaryTerm, bool) {
+	sourceLang, ok := glossaryLanguages(req.SourceLanguage, req.TargetLanguage)
+	if !ok {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrGlossaryLanguagesInvalid))
+		return models.GlossaryTerm{}, false
+	}
+
+	term := models.GlossaryTerm{
+		SourceLanguage: sourceLang,
+		SourceTerm:     strings.TrimSpace(req.SourceTerm),
+		TargetLanguage: req.TargetLanguage,
+		TargetTerm:     strings.TrimSpace(req.TargetTerm),
+		CaseSensitive:  req.CaseSensitive,
+		Note:           req.Note,
+	}
+	if term.SourceTerm == "" || term.TargetTerm == "" {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrInvalidRequest))
+		return models.GlossaryTerm{}, false
+	}
+	return term, true
+}
+
+// 確認來源與目標語言皆已設定且不相同；未指定來源語言時使用預設語言
+func glossaryLanguages(sourceLang, targetLang string) (string, bool) {
+	if sourceLang == "" {
+		sourceLang = languageRegistry.DefaultCode()
+	}
+	if _, ok := languageRegistry.Get(sourceLang); !ok {
+		return "", false
+	}
+	if _, ok := languageRegistry.Get(targetLang); !ok {
+		return "", false
+	}
+	return sourceLang, sourceLang != targetLang
+}
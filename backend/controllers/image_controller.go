@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"GolangBlog/config"
+	"GolangBlog/locales"
 	"GolangBlog/models"
 	"GolangBlog/services"
 	"net/http"
@@ -58,7 +59,7 @@ func UploadImage(c *gin.Context) {
 	// 獲取用戶ID
 	userID, exists := c.Get("userID")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "未認證的用戶"})
+		c.JSON(http.StatusUnauthorized, errorBody(c, locales.ErrUnauthenticated))
 		return
 	}
 
@@ -72,14 +73,14 @@ func UploadImage(c *gin.Context) {
 	// 獲取上傳的檔案
 	file, err := c.FormFile("file")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "獲取上傳檔案失敗"})
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrImageFileMissing))
 		return
 	}
 
 	// 使用上傳服務處理檔案
 	image, err := uploadService.UploadFile(file, userID.(uint), usage)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "上傳圖片失敗: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrImageUploadFailed, locales.Params{"reason": err.Error()}))
 		return
 	}
 
@@ -89,7 +90,7 @@ func UploadImage(c *gin.Context) {
 
 	// 儲存圖片資訊到資料庫
 	if err := config.DB.Create(image).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "儲存圖片資訊失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrImageSaveFailed))
 		return
 	}
 
@@ -154,9 +155,9 @@ func GetImage(c *gin.Context) {
 
 	if err := config.DB.Preload("User").First(&image, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "圖片不存在"})
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrImageNotFound))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		}
 		return
 	}
@@ -172,9 +173,9 @@ func UpdateImage(c *gin.Context) {
 	// 確認圖片存在
 	if err := config.DB.First(&image, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "圖片不存在"})
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrImageNotFound))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		}
 		return
 	}
@@ -183,7 +184,7 @@ func UpdateImage(c *gin.Context) {
 	userID, _ := c.Get("userID")
 	role, _ := c.Get("role")
 	if image.UserID != userID.(uint) && role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "無權更新此圖片"})
+		c.JSON(http.StatusForbidden, errorBody(c, locales.ErrImageUpdateForbidden))
 		return
 	}
 
@@ -196,7 +197,7 @@ func UpdateImage(c *gin.Context) {
 
 	var req UpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
 		return
 	}
 
@@ -212,13 +213,13 @@ func UpdateImage(c *gin.Context) {
 	}
 
 	if err := config.DB.Model(&image).Updates(updates).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新圖片資訊失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrImageUpdateFailed))
 		return
 	}
 
 	// 重新獲取更新後的圖片資訊
 	if err := config.DB.Preload("User").First(&image, id).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "獲取更新後的圖片資訊失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrImageReloadFailed))
 		return
 	}
 
@@ -236,9 +237,9 @@ func DeleteImage(c *gin.Context) {
 	// 確認圖片存在
 	if err := config.DB.First(&image, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "圖片不存在"})
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrImageNotFound))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		}
 		return
 	}
@@ -247,13 +248,13 @@ func DeleteImage(c *gin.Context) {
 	userID, _ := c.Get("userID")
 	role, _ := c.Get("role")
 	if image.UserID != userID.(uint) && role != "admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "無權刪除此圖片"})
+		c.JSON(http.StatusForbidden, errorBody(c, locales.ErrImageDeleteForbidden))
 		return
 	}
 
 	// 刪除圖片記錄
 	if err := config.DB.Delete(&image).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "刪除圖片記錄失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrImageDeleteFailed))
 		return
 	}
 
@@ -2,9 +2,14 @@ package controllers
 
 import (
 	"GolangBlog/config"
+	"GolangBlog/locales"
+	"GolangBlog/middlewares"
 	"GolangBlog/models"
+	"GolangBlog/services"
+	"GolangBlog/utils"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -16,9 +21,75 @@ type LanguageRequest struct {
 	Name       string `json:"name" binding:"required"`
 	NativeName string `json:"native_name" binding:"required"`
 	IsActive   bool   `json:"is_active"`
+	IsPreview  bool   `json:"is_preview"` // 僅預覽：編輯者與管理員可見，訪客看不到
 	IsDefault  bool   `json:"is_default"`
-	Direction  string `json:"direction"`
+	Direction  string `json:"direction" binding:"omitempty,oneof=ltr rtl"`
 	SortOrder  int    `json:"sort_order"`
+	Fallbacks  string `json:"fallbacks"` // 以逗號分隔的退回語言代碼，依序嘗試
+
+	SlugStrategy string `json:"slug_strategy" binding:"omitempty,oneof=transliterate native"` // 未指定時，rtl 語言保留原文字，其他語言轉寫為拉丁字母
+}
+
+// 取消其他語言的預設標誌時更新的欄位（同時遞增其版本號）
+func defaultResetValues() map[string]interface{} {
+	return map[string]interface{}{
+		"is_default": false,
+		"version":    gorm.Expr("version + 1"),
+	}
+}
+
+// 驗證並正規化退回語言設定
+func normalizeFallbacks(code string, fallbacks string) (string, error) {
+	codes := uniqueStrings(services.ParseLanguageList(fallbacks))
+	if len(codes) == 0 {
+		return "", nil
+	}
+
+	for _, fallback := range codes {
+		if fallback == code {
+			return "", locales.NewError(locales.ErrLanguageFallbackSelf)
+		}
+	}
+
+	var count int64
+	config.DB.Model(&models.Language{}).Where("code IN ?", codes).Distinct("code").Count(&count)
+	if int(count) != len(codes) {
+		return "", locales.NewError(locales.ErrLanguageFallbackUnknown)
+	}
+
+	return strings.Join(codes, ","), nil
+}
+
+// 文字方向的預設 slug 策略：rtl 語言保留原文字，避免轉寫成無意義的拉丁字母
+func defaultSlugStrategy(direction string) string {
+	if direction == "rtl" {
+		return utils.SlugNative
+	}
+	return utils.SlugTransliterate
+}
+
+// 去除重複字串並保留原順序
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// 訪客只能查看公開的語言（啟用且非僅預覽），編輯者與管理員可查看所有語言
+func visibleLanguageScope(c *gin.Context) func(db *gorm.DB) *gorm.DB {
+	staff := middlewares.IsStaff(c)
+	return func(db *gorm.DB) *gorm.DB {
+		if staff {
+			return db
+		}
+		return db.Where("is_active = ? AND is_preview = ?", true, false)
+	}
 }
 
 // 獲取所有語言
@@ -34,6 +105,9 @@ func GetLanguages(c *gin.Context) {
 		query = query.Where("is_active = ?", true)
 	}
 
+	// 訪客只能看到公開的語言
+	query = query.Scopes(visibleLanguageScope(c))
+
 	// 排序
 	orderBy := c.DefaultQuery("order_by", "sort_order")
 	orderDir := c.DefaultQuery("order_dir", "asc")
@@ -41,7 +115,7 @@ func GetLanguages(c *gin.Context) {
 
 	// 執行查詢
 	if err := query.Find(&languages).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		return
 	}
 
@@ -53,15 +127,16 @@ func GetLanguage(c *gin.Context) {
 	id := c.Param("id")
 	var language models.Language
 
-	if err := config.DB.First(&language, id).Error; err != nil {
+	if err := config.DB.Scopes(visibleLanguageScope(c)).First(&language, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "語言不存在"})
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrLanguageNotFound))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		}
 		return
 	}
 
+	setETag(c, "language", language.ID, language.Version)
 	c.JSON(http.StatusOK, gin.H{"language": language})
 }
 
@@ -70,15 +145,16 @@ func GetLanguageByCode(c *gin.Context) {
 	code := c.Param("code")
 	var language models.Language
 
-	if err := config.DB.Where("code = ?", code).First(&language).Error; err != nil {
+	if err := config.DB.Scopes(visibleLanguageScope(c)).Where("code = ?", code).First(&language).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "語言不存在"})
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrLanguageNotFound))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		}
 		return
 	}
 
+	setETag(c, "language", language.ID, language.Version)
 	c.JSON(http.StatusOK, gin.H{"language": language})
 }
 
@@ -86,14 +162,27 @@ func GetLanguageByCode(c *gin.Context) {
 func CreateLanguage(c *gin.Context) {
 	var req LanguageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
 		return
 	}
 
 	// 檢查語言代碼是否已存在
 	var existingLanguage models.Language
 	if result := config.DB.Where("code = ?", req.Code).First(&existingLanguage); result.RowsAffected > 0 {
-		c.JSON(http.StatusConflict, gin.H{"error": "語言代碼已存在"})
+		c.JSON(http.StatusConflict, errorBody(c, locales.ErrLanguageCodeExists))
+		return
+	}
+
+	// 預設語言必須公開
+	if req.IsDefault && req.IsPreview {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrLanguageDefaultPreview))
+		return
+	}
+
+	// 驗證退回語言
+	fallbacks, err := normalizeFallbacks(req.Code, req.Fallbacks)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
 		return
 	}
 
@@ -102,9 +191,9 @@ func CreateLanguage(c *gin.Context) {
 
 	// 如果設置為預設語言，則將其他語言的預設標誌設置為 false
 	if req.IsDefault {
-		if err := tx.Model(&models.Language{}).Where("is_default = ?", true).Update("is_default", false).Error; err != nil {
+		if err := tx.Model(&models.Language{}).Where("is_default = ?", true).Updates(defaultResetValues()).Error; err != nil {
 			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "更新其他語言的預設標誌失敗"})
+			c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrLanguageDefaultResetFailed))
 			return
 		}
 	}
@@ -115,9 +204,13 @@ func CreateLanguage(c *gin.Context) {
 		Name:       req.Name,
 		NativeName: req.NativeName,
 		IsActive:   req.IsActive,
+		IsPreview:  req.IsPreview,
 		IsDefault:  req.IsDefault,
 		Direction:  req.Direction,
 		SortOrder:  req.SortOrder,
+		Fallbacks:  fallbacks,
+
+		SlugStrategy: req.SlugStrategy,
 	}
 
 	// 如果未提供方向，則設置為預設值 "ltr"
@@ -125,15 +218,22 @@ func CreateLanguage(c *gin.Context) {
 		language.Direction = "ltr"
 	}
 
+	// 如果未提供 slug 策略，依文字方向決定
+	if language.SlugStrategy == "" {
+		language.SlugStrategy = defaultSlugStrategy(language.Direction)
+	}
+
 	if err := tx.Create(&language).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "創建語言失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrLanguageCreateFailed))
 		return
 	}
 
 	// 提交事務
 	tx.Commit()
+	languageRegistry.Invalidate()
 
+	setETag(c, "language", language.ID, language.Version)
 	c.JSON(http.StatusCreated, gin.H{
 		"message":  "語言創建成功",
 		"language": language,
@@ -148,16 +248,21 @@ func UpdateLanguage(c *gin.Context) {
 	// 確認語言存在
 	if err := config.DB.First(&language, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "語言不存在"})
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrLanguageNotFound))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		}
 		return
 	}
 
+	// 確認請求者編輯的是最新版本
+	if !checkIfMatch(c, "language", language.ID, language.Version) {
+		return
+	}
+
 	var req LanguageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
 		return
 	}
 
@@ -165,19 +270,40 @@ func UpdateLanguage(c *gin.Context) {
 	if req.Code != language.Code {
 		var existingLanguage models.Language
 		if result := config.DB.Where("code = ? AND id != ?", req.Code, id).First(&existingLanguage); result.RowsAffected > 0 {
-			c.JSON(http.StatusConflict, gin.H{"error": "語言代碼已被其他語言使用"})
+			c.JSON(http.StatusConflict, errorBody(c, locales.ErrLanguageCodeTaken))
 			return
 		}
 	}
 
+	// 預設語言必須公開
+	if req.IsDefault && req.IsPreview {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrLanguageDefaultPreview))
+		return
+	}
+
+	// 驗證退回語言
+	fallbacks, err := normalizeFallbacks(req.Code, req.Fallbacks)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
 	// 開始事務
 	tx := config.DB.Begin()
 
+	// 遞增版本號（檢查後若已被其他請求修改則回傳 412）
+	if ok, err := bumpVersion(tx, &models.Language{}, language.ID, language.Version); !ok {
+		tx.Rollback()
+		respondBumpFailure(c, "language", &models.Language{}, language.ID, err)
+		return
+	}
+	language.Version++
+
 	// 如果設置為預設語言，則將其他語言的預設標誌設置為 false
 	if req.IsDefault && !language.IsDefault {
-		if err := tx.Model(&models.Language{}).Where("id != ?", id).Update("is_default", false).Error; err != nil {
+		if err := tx.Model(&models.Language{}).Where("id != ? AND is_default = ?", id, true).Updates(defaultResetValues()).Error; err != nil {
 			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "更新其他語言的預設標誌失敗"})
+			c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrLanguageDefaultResetFailed))
 			return
 		}
 	}
@@ -187,24 +313,34 @@ func UpdateLanguage(c *gin.Context) {
 	language.Name = req.Name
 	language.NativeName = req.NativeName
 	language.IsActive = req.IsActive
+	language.IsPreview = req.IsPreview
 	language.IsDefault = req.IsDefault
 	language.Direction = req.Direction
 	language.SortOrder = req.SortOrder
+	language.Fallbacks = fallbacks
+	language.SlugStrategy = req.SlugStrategy
 
 	// 如果未提供方向，則設置為預設值 "ltr"
 	if language.Direction == "" {
 		language.Direction = "ltr"
 	}
 
+	// 如果未提供 slug 策略，依文字方向決定
+	if language.SlugStrategy == "" {
+		language.SlugStrategy = defaultSlugStrategy(language.Direction)
+	}
+
 	if err := tx.Save(&language).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新語言失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrLanguageUpdateFailed))
 		return
 	}
 
 	// 提交事務
 	tx.Commit()
+	languageRegistry.Invalidate()
 
+	setETag(c, "language", language.ID, language.Version)
 	c.JSON(http.StatusOK, gin.H{
 		"message":  "語言更新成功",
 		"language": language,
@@ -219,16 +355,21 @@ func DeleteLanguage(c *gin.Context) {
 	// 確認語言存在
 	if err := config.DB.First(&language, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "語言不存在"})
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrLanguageNotFound))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		}
 		return
 	}
 
+	// 確認請求者刪除的是最新版本
+	if !checkIfMatch(c, "language", language.ID, language.Version) {
+		return
+	}
+
 	// 檢查是否為預設語言
 	if language.IsDefault {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "預設語言不能刪除，請先設置其他語言為預設語言"})
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrLanguageDefaultDelete))
 		return
 	}
 
@@ -236,42 +377,48 @@ func DeleteLanguage(c *gin.Context) {
 	// 1. 檢查文章翻譯
 	var articleTransCount int64
 	if err := config.DB.Model(&models.ArticleTranslation{}).Where("language_code = ?", language.Code).Count(&articleTransCount).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		return
 	}
 
 	// 2. 檢查標籤翻譯
 	var tagTransCount int64
 	if err := config.DB.Model(&models.TagTranslation{}).Where("language_code = ?", language.Code).Count(&tagTransCount).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		return
 	}
 
 	// 3. 檢查分類翻譯
 	var categoryTransCount int64
 	if err := config.DB.Model(&models.CategoryTranslation{}).Where("language_code = ?", language.Code).Count(&categoryTransCount).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		return
 	}
 
 	// 如果語言已被使用，則不允許刪除
 	if articleTransCount > 0 || tagTransCount > 0 || categoryTransCount > 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "該語言已被使用，不能刪除，相關統計如下",
-			"usage": gin.H{
-				"articles":   articleTransCount,
-				"tags":       tagTransCount,
-				"categories": categoryTransCount,
-			},
-		})
+		body := errorBody(c, locales.ErrLanguageInUse)
+		body["usage"] = gin.H{
+			"articles":   articleTransCount,
+			"tags":       tagTransCount,
+			"categories": categoryTransCount,
+		}
+		c.JSON(http.StatusBadRequest, body)
+		return
+	}
+
+	// 遞增版本號（檢查後若已被其他請求修改則回傳 412）
+	if ok, err := bumpVersion(config.DB, &models.Language{}, language.ID, language.Version); !ok {
+		respondBumpFailure(c, "language", &models.Language{}, language.ID, err)
 		return
 	}
 
 	// 刪除語言
 	if err := config.DB.Delete(&language).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "刪除語言失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrLanguageDeleteFailed))
 		return
 	}
+	languageRegistry.Invalidate()
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "語言刪除成功",
@@ -286,21 +433,31 @@ func SetDefaultLanguage(c *gin.Context) {
 	// 確認語言存在
 	if err := config.DB.First(&language, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "語言不存在"})
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrLanguageNotFound))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		}
 		return
 	}
 
-	// 確認語言是否啟用
+	// 確認請求者編輯的是最新版本
+	if !checkIfMatch(c, "language", language.ID, language.Version) {
+		return
+	}
+
+	// 確認語言是否啟用且公開
 	if !language.IsActive {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "非啟用狀態的語言不能設為預設語言"})
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrLanguageInactiveDefault))
+		return
+	}
+	if language.IsPreview {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrLanguagePreviewDefault))
 		return
 	}
 
 	// 如果該語言已經是預設語言，則無需操作
 	if language.IsDefault {
+		setETag(c, "language", language.ID, language.Version)
 		c.JSON(http.StatusOK, gin.H{
 			"message":  "該語言已經是預設語言",
 			"language": language,
@@ -311,10 +468,18 @@ func SetDefaultLanguage(c *gin.Context) {
 	// 開始事務
 	tx := config.DB.Begin()
 
+	// 遞增版本號（檢查後若已被其他請求修改則回傳 412）
+	if ok, err := bumpVersion(tx, &models.Language{}, language.ID, language.Version); !ok {
+		tx.Rollback()
+		respondBumpFailure(c, "language", &models.Language{}, language.ID, err)
+		return
+	}
+	language.Version++
+
 	// 將其他語言的預設標誌設置為 false
-	if err := tx.Model(&models.Language{}).Update("is_default", false).Error; err != nil {
+	if err := tx.Model(&models.Language{}).Where("is_default = ?", true).Updates(defaultResetValues()).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新其他語言的預設標誌失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrLanguageDefaultResetFailed))
 		return
 	}
 
@@ -322,13 +487,15 @@ func SetDefaultLanguage(c *gin.Context) {
 	language.IsDefault = true
 	if err := tx.Save(&language).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "設置預設語言失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrLanguageSetDefaultFailed))
 		return
 	}
 
 	// 提交事務
 	tx.Commit()
+	languageRegistry.Invalidate()
 
+	setETag(c, "language", language.ID, language.Version)
 	c.JSON(http.StatusOK, gin.H{
 		"message":  "預設語言設置成功",
 		"language": language,
@@ -341,29 +508,40 @@ func UpdateLanguageOrder(c *gin.Context) {
 		Orders []struct {
 			ID        uint `json:"id" binding:"required"`
 			SortOrder int  `json:"sort_order" binding:"required"`
+			Version   uint `json:"version" binding:"required"` // 讀取時的版本號，與其他修改衝突時回傳 412
 		} `json:"orders" binding:"required"`
 	}
 
 	var req LanguageOrderRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
 		return
 	}
 
 	// 開始事務
 	tx := config.DB.Begin()
 
-	// 更新每個語言的排序
+	// 更新每個語言的排序，只在版本號仍為請求中的版本時更新
 	for _, order := range req.Orders {
-		if err := tx.Model(&models.Language{}).Where("id = ?", order.ID).Update("sort_order", order.SortOrder).Error; err != nil {
+		result := tx.Model(&models.Language{}).Where("id = ? AND version = ?", order.ID, order.Version).Updates(map[string]interface{}{
+			"sort_order": order.SortOrder,
+			"version":    gorm.Expr("version + 1"),
+		})
+		if result.Error != nil {
 			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "更新語言排序失敗"})
+			c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrLanguageOrderUpdateFailed))
+			return
+		}
+		if result.RowsAffected == 0 {
+			tx.Rollback()
+			respondBumpFailure(c, "language", &models.Language{}, order.ID, nil)
 			return
 		}
 	}
 
 	// 提交事務
 	tx.Commit()
+	languageRegistry.Invalidate()
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "語言排序更新成功",
@@ -378,33 +556,100 @@ func ToggleLanguageStatus(c *gin.Context) {
 	// 確認語言存在
 	if err := config.DB.First(&language, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "語言不存在"})
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrLanguageNotFound))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		}
 		return
 	}
 
+	// 確認請求者編輯的是最新版本
+	if !checkIfMatch(c, "language", language.ID, language.Version) {
+		return
+	}
+
 	// 如果是預設語言並且嘗試禁用，則不允許
 	if language.IsDefault && language.IsActive {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "預設語言不能被禁用，請先設置其他語言為預設語言"})
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrLanguageDefaultDisable))
 		return
 	}
 
+	// 遞增版本號（檢查後若已被其他請求修改則回傳 412）
+	if ok, err := bumpVersion(config.DB, &models.Language{}, language.ID, language.Version); !ok {
+		respondBumpFailure(c, "language", &models.Language{}, language.ID, err)
+		return
+	}
+	language.Version++
+
 	// 切換狀態
 	language.IsActive = !language.IsActive
 	if err := config.DB.Save(&language).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新語言狀態失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrLanguageStatusUpdateFailed))
 		return
 	}
+	languageRegistry.Invalidate()
 
 	statusText := "啟用"
 	if !language.IsActive {
 		statusText = "禁用"
 	}
 
+	setETag(c, "language", language.ID, language.Version)
 	c.JSON(http.StatusOK, gin.H{
 		"message":  fmt.Sprintf("語言已%s", statusText),
 		"language": language,
 	})
 }
+
+// 切換語言的僅預覽狀態：僅預覽的語言只有編輯者與管理員可見，可在上線前準備翻譯
+func ToggleLanguagePreview(c *gin.Context) {
+	id := c.Param("id")
+	var language models.Language
+
+	// 確認語言存在
+	if err := config.DB.First(&language, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrLanguageNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return
+	}
+
+	// 確認請求者編輯的是最新版本
+	if !checkIfMatch(c, "language", language.ID, language.Version) {
+		return
+	}
+
+	// 預設語言必須公開
+	if language.IsDefault && !language.IsPreview {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrLanguageDefaultPreview))
+		return
+	}
+
+	// 遞增版本號（檢查後若已被其他請求修改則回傳 412）
+	if ok, err := bumpVersion(config.DB, &models.Language{}, language.ID, language.Version); !ok {
+		respondBumpFailure(c, "language", &models.Language{}, language.ID, err)
+		return
+	}
+	language.Version++
+
+	// 切換狀態
+	language.IsPreview = !language.IsPreview
+	if err := config.DB.Save(&language).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrLanguageStatusUpdateFailed))
+		return
+	}
+	languageRegistry.Invalidate()
+
+	statusText := "已公開"
+	if language.IsPreview {
+		statusText = "設為僅預覽"
+	}
+
+	setETag(c, "language", language.ID, language.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"message":  fmt.Sprintf("語言%s", statusText),
+		"language": language,
+	})
+}
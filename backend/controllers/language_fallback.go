@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"GolangBlog/middlewares"
+	"GolangBlog/models"
+	"GolangBlog/services"
+	"GolangBlog/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 語言設定快取
+var languageRegistry *services.LanguageRegistry
+
+// 取得語言設定快取（供語言協商中間件使用）
+func LanguageRegistry() *services.LanguageRegistry {
+	return languageRegistry
+}
+
+// 初始化語言設定快取（需在資料庫連接後呼叫）
+func InitLanguageRegistry(db *gorm.DB) {
+	languageRegistry = services.NewLanguageRegistry(db)
+
+	// 翻譯查詢後依語言設定填入文字方向
+	models.DirectionResolver = func(langCode string) string {
+		if language, ok := languageRegistry.Get(langCode); ok {
+			return language.Direction
+		}
+		return ""
+	}
+}
+
+// 依語言設定的 slug 策略產生 slug
+func makeSlug(langCode, text string) string {
+	language, _ := languageRegistry.Get(langCode)
+	return utils.MakeSlug(text, language.SlugStrategy)
+}
+
+// 取得明確指定的語言（查詢參數或路徑前綴），未指定或請求者無法查看該語言時回傳空字串
+func explicitLanguage(c *gin.Context) string {
+	if c.GetBool(middlewares.LocaleExplicitKey) {
+		return c.GetString(middlewares.LocaleKey)
+	}
+	if langCode := c.Query("lang"); middlewares.IsStaff(c) || languageRegistry.IsPublic(langCode) {
+		return langCode
+	}
+	return ""
+}
+
+// 取得請求語言：明確指定的語言，其次為語言協商結果，最後為預設語言
+func currentLanguage(c *gin.Context) string {
+	if langCode := explicitLanguage(c); langCode != "" {
+		return langCode
+	}
+	if locale := c.GetString(middlewares.LocaleKey); locale != "" {
+		return locale
+	}
+	return languageRegistry.DefaultCode()
+}
+
+// 取得請求語言與其退回順序
+func requestLanguage(c *gin.Context) (string, []string) {
+	langCode := currentLanguage(c)
+	return langCode, languageChain(c, langCode)
+}
+
+// 取得語言的退回順序，只保留請求者可查看的語言：
+// 訪客只能看到公開語言，編輯者與管理員可看到停用與僅預覽語言的內容
+func languageChain(c *gin.Context, langCode string) []string {
+	chain := languageRegistry.Chain(langCode)
+	if middlewares.IsStaff(c) {
+		return chain
+	}
+
+	visible := make([]string, 0, len(chain))
+	for _, code := range chain {
+		if languageRegistry.IsPublic(code) || code == languageRegistry.DefaultCode() {
+			visible = append(visible, code)
+		}
+	}
+	return visible
+}
+
+// 取得請求者可查看的語言：訪客為公開語言，編輯者與管理員不受限制（回傳 nil）
+func visibleLanguages(c *gin.Context) []string {
+	if middlewares.IsStaff(c) {
+		return nil
+	}
+	return languageRegistry.PublicCodes()
+}
+
+// 只預載請求者可查看的語言翻譯（未指定語言時使用）
+func visibleScope(c *gin.Context) func(db *gorm.DB) *gorm.DB {
+	languages := visibleLanguages(c)
+	return func(db *gorm.DB) *gorm.DB {
+		if languages == nil {
+			return db
+		}
+		return chainScope(languages)(db)
+	}
+}
+
+// 是否只提供已審閱的翻譯：訪客看不到待審閱的機器翻譯，編輯者與管理員可預覽
+func reviewedOnly(c *gin.Context) bool {
+	return !middlewares.IsStaff(c)
+}
+
+// 排除請求者不可查看的待審閱機器翻譯（文章、標籤與分類翻譯使用）
+func reviewedScope(c *gin.Context) func(db *gorm.DB) *gorm.DB {
+	onlyReviewed := reviewedOnly(c)
+	return func(db *gorm.DB) *gorm.DB {
+		if !onlyReviewed {
+			return db
+		}
+		return db.Where(services.ReviewedTranslationFilter(""))
+	}
+}
+
+// 預載文章、標籤與分類的翻譯：限定語言範圍（nil 時為請求者可查看的語言），
+// 並排除請求者不可查看的待審閱機器翻譯
+func translationScope(c *gin.Context, languages []string) func(db *gorm.DB) *gorm.DB {
+	if languages == nil {
+		languages = visibleLanguages(c)
+	}
+	reviewed := reviewedScope(c)
+	return func(db *gorm.DB) *gorm.DB {
+		if languages != nil {
+			db = chainScope(languages)(db)
+		}
+		return reviewed(db)
+	}
+}
+
+// 只預載退回順序內的翻譯
+func chainScope(chain []string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("language_code IN ?", chain)
+	}
+}
+
+// 依退回順序在已載入翻譯的語言中挑選，回傳索引（找不到時為 -1）
+func pickLanguage(chain []string, codes []string) int {
+	for _, code := range chain {
+		for i, available := range codes {
+			if available == code {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// 依退回順序只保留文章與其標籤的單一翻譯
+func localizeArticles(articles []models.Article, chain []string) {
+	for i := range articles {
+		article := &articles[i]
+
+		codes := make([]string, len(article.Translations))
+		for j, translation := range article.Translations {
+			codes[j] = translation.LanguageCode
+		}
+
+		if idx := pickLanguage(chain, codes); idx >= 0 {
+			article.Translations = article.Translations[idx : idx+1]
+			article.ServedLanguage = codes[idx]
+		} else {
+			article.Translations = []models.ArticleTranslation{}
+		}
+
+		localizeTags(article.Tags, chain)
+	}
+}
+
+// 依退回順序只保留標籤的單一翻譯
+func localizeTags(tags []models.Tag, chain []string) {
+	for i := range tags {
+		tag := &tags[i]
+
+		codes := make([]string, len(tag.Translations))
+		for j, translation := range tag.Translations {
+			codes[j] = translation.LanguageCode
+		}
+
+		if idx := pickLanguage(chain, codes); idx >= 0 {
+			tag.Translations = tag.Translations[idx : idx+1]
+			tag.ServedLanguage = codes[idx]
+		} else {
+			tag.Translations = []models.TagTranslation{}
+		}
+	}
+}
+
+// 依退回順序只保留分類（含父分類）的單一翻譯
+func localizeCategories(categories []models.Category, chain []string) {
+	for i := range categories {
+		category := &categories[i]
+
+		codes := make([]string, len(category.Translations))
+		for j, translation := range category.Translations {
+			codes[j] = translation.LanguageCode
+		}
+
+		if idx := pickLanguage(chain, codes); idx >= 0 {
+			category.Translations = category.Translations[idx : idx+1]
+			category.ServedLanguage = codes[idx]
+		} else {
+			category.Translations = []models.CategoryTranslation{}
+		}
+
+		if category.Parent != nil {
+			parent := []models.Category{*category.Parent}
+			localizeCategories(parent, chain)
+			category.Parent = &parent[0]
+		}
+	}
+}
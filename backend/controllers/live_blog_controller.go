@@ -0,0 +1,446 @@
+package controllers
+
+import (
+	"GolangBlog/config"
+	"GolangBlog/locales"
+	"GolangBlog/models"
+	"GolangBlog/services"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 直播更新分派器
+var liveBlogBroker = services.NewLiveBlogBroker()
+
+// 直播更新請求結構
+type LiveBlogEntryRequest struct {
+	LanguageCode string `json:"language_code" binding:"required"`
+	Title        string `json:"title"`
+	Content      string `json:"content" binding:"required"`
+	IsPinned     bool   `json:"is_pinned"`
+}
+
+// 直播更新編輯請求結構
+type LiveBlogEntryUpdateRequest struct {
+	Title   string `json:"title"`
+	Content string `json:"content" binding:"required"`
+}
+
+// 記錄直播更新事件（事務提交後再推送給訂閱者）
+func recordLiveBlogEvent(tx *gorm.DB, entry *models.LiveBlogEntry, eventType string) (*models.LiveBlogEvent, error) {
+	event := &models.LiveBlogEvent{
+		ArticleID:    entry.ArticleID,
+		LanguageCode: entry.LanguageCode,
+		EntryID:      entry.ID,
+		Type:         eventType,
+	}
+	if err := tx.Create(event).Error; err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// 寫入單一 SSE 事件
+func writeLiveBlogEvent(w io.Writer, msg services.LiveBlogMessage) {
+	data := gin.H{
+		"type":     msg.Event.Type,
+		"entry_id": msg.Event.EntryID,
+	}
+	if msg.Entry != nil && msg.Event.Type != "deleted" {
+		data["entry"] = msg.Entry
+	}
+
+	sse.Encode(w, sse.Event{
+		Id:    strconv.FormatUint(uint64(msg.Event.ID), 10),
+		Event: msg.Event.Type,
+		Data:  data,
+	})
+}
+
+// 事件 ID 在寫入時即分配，較晚提交的交易可能產生比已送出事件更小的 ID；
+// 串流補抓時會回溯這個範圍內的 ID，並略過已送出的事件
+const liveBlogReplayWindow = 500
+
+// 從資料庫讀取指定事件之後的更新（略過 skip 中已送出的事件）
+func loadLiveBlogEventsSince(articleID uint, lang string, lastID uint, skip []uint) []services.LiveBlogMessage {
+	query := config.DB.Where("article_id = ? AND language_code = ? AND id > ?", articleID, lang, lastID)
+	if len(skip) > 0 {
+		query = query.Where("id NOT IN ?", skip)
+	}
+
+	var events []models.LiveBlogEvent
+	query.Order("id asc").Limit(200).Find(&events)
+	if len(events) == 0 {
+		return nil
+	}
+
+	entryIDs := make([]uint, 0, len(events))
+	for _, e := range events {
+		entryIDs = append(entryIDs, e.EntryID)
+	}
+
+	var entries []models.LiveBlogEntry
+	config.DB.Preload("User").Where("id IN ?", entryIDs).Find(&entries)
+	entryMap := make(map[uint]*models.LiveBlogEntry, len(entries))
+	for i := range entries {
+		entryMap[entries[i].ID] = &entries[i]
+	}
+
+	messages := make([]services.LiveBlogMessage, 0, len(events))
+	for _, e := range events {
+		messages = append(messages, services.LiveBlogMessage{Event: e, Entry: entryMap[e.EntryID]})
+	}
+	return messages
+}
+
+// 獲取直播更新列表（置頂優先，其次由新到舊）
+func GetLiveBlogEntries(c *gin.Context) {
+	article, ok := findPublishedArticle(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	langCode := currentLanguage(c)
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	query := config.DB.Model(&models.LiveBlogEntry{}).
+		Where("article_id = ? AND language_code = ?", article.ID, langCode)
+
+	// 以游標載入更早的更新
+	if beforeID := c.Query("before_id"); beforeID != "" {
+		query = query.Where("id < ? AND is_pinned = ?", beforeID, false)
+	}
+
+	var entries []models.LiveBlogEntry
+	if err := query.Preload("User").Order("is_pinned desc, id desc").Limit(limit).Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	// 最新事件 ID，客戶端以此作為串流的起點
+	var lastEventID uint
+	config.DB.Model(&models.LiveBlogEvent{}).
+		Where("article_id = ? AND language_code = ?", article.ID, langCode).
+		Select("COALESCE(MAX(id), 0)").Scan(&lastEventID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries":       entries,
+		"last_event_id": lastEventID,
+	})
+}
+
+// 以 Server-Sent Events 推送直播更新，支援 Last-Event-ID 續傳
+func StreamLiveBlog(c *gin.Context) {
+	article, ok := findPublishedArticle(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	langCode := currentLanguage(c)
+
+	// 瀏覽器重新連線時會帶上 Last-Event-ID 標頭
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	parsed, _ := strconv.ParseUint(lastEventID, 10, 64)
+	lastID := uint(parsed)
+
+	// 未指定起點時從目前最新的事件開始
+	if lastID == 0 {
+		config.DB.Model(&models.LiveBlogEvent{}).
+			Where("article_id = ? AND language_code = ?", article.ID, langCode).
+			Select("COALESCE(MAX(id), 0)").Scan(&lastID)
+	}
+
+	messages, cancel := liveBlogBroker.Subscribe(article.ID, langCode)
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	// 起點（含）之前的事件視為已送出；之後以集合記錄已送出的事件 ID，
+	// 較晚提交而 ID 較小的事件仍會送出，不會因為已送出更新的事件而遺漏
+	floor := lastID
+	delivered := make(map[uint]struct{})
+	send := func(w io.Writer, msg services.LiveBlogMessage) {
+		id := msg.Event.ID
+		if id <= floor {
+			return
+		}
+		if _, ok := delivered[id]; ok {
+			return
+		}
+		writeLiveBlogEvent(w, msg)
+		delivered[id] = struct{}{}
+		if id > lastID {
+			lastID = id
+		}
+
+		// 超出回溯範圍的事件不會再被查詢，移出集合並提高起點
+		if lastID > liveBlogReplayWindow && lastID-liveBlogReplayWindow > floor {
+			floor = lastID - liveBlogReplayWindow
+			for seen := range delivered {
+				if seen <= floor {
+					delete(delivered, seen)
+				}
+			}
+		}
+	}
+	// 補送斷線期間、其他執行個體發布或較晚提交的事件
+	catchUp := func(w io.Writer) {
+		skip := make([]uint, 0, len(delivered))
+		for id := range delivered {
+			skip = append(skip, id)
+		}
+		for _, msg := range loadLiveBlogEventsSince(article.ID, langCode, floor, skip) {
+			send(w, msg)
+		}
+	}
+
+	// 定期從資料庫補抓事件
+	poll := time.NewTicker(5 * time.Second)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(20 * time.Second)
+	defer heartbeat.Stop()
+
+	first := true
+	c.Stream(func(w io.Writer) bool {
+		if first {
+			first = false
+			sse.Encode(w, sse.Event{Event: "ready", Retry: 3000, Data: gin.H{"last_event_id": lastID}})
+			catchUp(w)
+			return true
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case msg := <-messages:
+			send(w, msg)
+		case <-poll.C:
+			catchUp(w)
+		case <-heartbeat.C:
+			io.WriteString(w, ": ping\n\n")
+		}
+		return true
+	})
+}
+
+// 找出文章下的直播更新
+func findLiveBlogEntry(c *gin.Context) (*models.LiveBlogEntry, bool) {
+	var entry models.LiveBlogEntry
+	if err := config.DB.Where("article_id = ?", c.Param("id")).First(&entry, c.Param("entry_id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrLiveEntryNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return nil, false
+	}
+	return &entry, true
+}
+
+// 新增直播更新
+func CreateLiveBlogEntry(c *gin.Context) {
+	var article models.Article
+	if err := config.DB.First(&article, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrArticleNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return
+	}
+
+	var req LiveBlogEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	// 只接受已啟用的語言，否則沒有任何公開串流會提供這些更新
+	if language, ok := languageRegistry.Get(req.LanguageCode); !ok || !language.IsActive {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrLanguageNotActive))
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	entry := models.LiveBlogEntry{
+		ArticleID:    article.ID,
+		LanguageCode: req.LanguageCode,
+		UserID:       userID.(uint),
+		Title:        req.Title,
+		Content:      req.Content,
+		IsPinned:     req.IsPinned,
+	}
+
+	// 啟動事務
+	tx := config.DB.Begin()
+
+	if err := tx.Create(&entry).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrLiveEntryCreateFailed))
+		return
+	}
+
+	tx.Preload("User").First(&entry, entry.ID)
+
+	event, err := recordLiveBlogEvent(tx, &entry, "created")
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrLiveEventRecordFailed))
+		return
+	}
+
+	// 提交事務
+	tx.Commit()
+
+	liveBlogBroker.Publish(services.LiveBlogMessage{Event: *event, Entry: &entry})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "直播更新新增成功",
+		"entry":   entry,
+	})
+}
+
+// 編輯直播更新
+func UpdateLiveBlogEntry(c *gin.Context) {
+	entry, ok := findLiveBlogEntry(c)
+	if !ok {
+		return
+	}
+
+	var req LiveBlogEntryUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	entry.Title = req.Title
+	entry.Content = req.Content
+
+	// 啟動事務
+	tx := config.DB.Begin()
+
+	if err := tx.Save(entry).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrLiveEntryUpdateFailed))
+		return
+	}
+
+	tx.Preload("User").First(entry, entry.ID)
+
+	event, err := recordLiveBlogEvent(tx, entry, "updated")
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrLiveEventRecordFailed))
+		return
+	}
+
+	// 提交事務
+	tx.Commit()
+
+	liveBlogBroker.Publish(services.LiveBlogMessage{Event: *event, Entry: entry})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "直播更新編輯成功",
+		"entry":   entry,
+	})
+}
+
+// 置頂或取消置頂直播更新
+func PinLiveBlogEntry(c *gin.Context) {
+	type PinRequest struct {
+		IsPinned bool `json:"is_pinned"`
+	}
+
+	entry, ok := findLiveBlogEntry(c)
+	if !ok {
+		return
+	}
+
+	var req PinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	eventType := "pinned"
+	if !req.IsPinned {
+		eventType = "unpinned"
+	}
+
+	// 啟動事務
+	tx := config.DB.Begin()
+
+	if err := tx.Model(entry).Update("is_pinned", req.IsPinned).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrLiveEntryPinFailed))
+		return
+	}
+
+	tx.Preload("User").First(entry, entry.ID)
+
+	event, err := recordLiveBlogEvent(tx, entry, eventType)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrLiveEventRecordFailed))
+		return
+	}
+
+	// 提交事務
+	tx.Commit()
+
+	liveBlogBroker.Publish(services.LiveBlogMessage{Event: *event, Entry: entry})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "置頂狀態更新成功",
+		"entry":   entry,
+	})
+}
+
+// 刪除直播更新
+func DeleteLiveBlogEntry(c *gin.Context) {
+	entry, ok := findLiveBlogEntry(c)
+	if !ok {
+		return
+	}
+
+	// 啟動事務
+	tx := config.DB.Begin()
+
+	if err := tx.Delete(entry).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrLiveEntryDeleteFailed))
+		return
+	}
+
+	event, err := recordLiveBlogEvent(tx, entry, "deleted")
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrLiveEventRecordFailed))
+		return
+	}
+
+	// 提交事務
+	tx.Commit()
+
+	liveBlogBroker.Publish(services.LiveBlogMessage{Event: *event, Entry: entry})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "直播更新刪除成功",
+	})
+}
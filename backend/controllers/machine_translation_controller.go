@@ -0,0 +1,328 @@
+package controllers
+
+import (
+	"GolangBlog/config"
+	"GolangBlog/locales"
+	"GolangBlog/services"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 機器翻譯服務（未設定時為 nil）
+var translator services.Translator
+
+// 機器翻譯請求結構
+type MachineTranslateRequest struct {
+	Type            string   `json:"type" binding:"required"`
+	ID              uint     `json:"id" binding:"required"`
+	SourceLanguage  string   `json:"source_language"`  // 未指定時使用預設語言
+	TargetLanguages []string `json:"target_languages"` // 未指定時翻譯為所有啟用中的語言
+	Overwrite       bool     `json:"overwrite"`        // 是否覆寫尚未審閱的機器翻譯
+}
+
+// 翻譯審閱請求結構
+type ReviewTranslationRequest struct {
+	Type         string `json:"type" binding:"required"`
+	ID           uint   `json:"id" binding:"required"`
+	LanguageCode string `json:"language_code" binding:"required"`
+}
+
+// 單一語言的機器翻譯結果
+type machineTranslationResult struct {
+	LanguageCode string `json:"language_code"`
+	Status       string `json:"status"`           // created、updated 或 skipped
+	Reason       string `json:"reason,omitempty"` // 略過的原因：exists、human_translation 或 reviewed
+}
+
+// 目標語言已存在的翻譯
+type existingTranslation struct {
+	ID                uint
+	LanguageCode      string
+	Slug              string
+	MachineTranslated bool
+	ReviewStatus      string
+	DeletedAt         gorm.DeletedAt
+}
+
+// 初始化機器翻譯服務（未設定 TRANSLATOR_STRATEGY 時停用）
+func InitMachineTranslator() {
+	strategy := os.Getenv("TRANSLATOR_STRATEGY")
+	if strategy == "" {
+		return
+	}
+
+	timeout, err := time.ParseDuration(os.Getenv("TRANSLATOR_TIMEOUT"))
+	if err != nil {
+		timeout = 30 * time.Second
+	}
+
+	translator, err = services.NewTranslator(services.TranslatorConfig{
+		Strategy: strategy,
+		APIKey:   os.Getenv("TRANSLATOR_API_KEY"),
+		Endpoint: os.Getenv("TRANSLATOR_ENDPOINT"),
+		Timeout:  timeout,
+	})
+	if err != nil {
+		log.Fatalf("初始化機器翻譯服務失敗: %v", err)
+	}
+}
+
+// 以機器翻譯產生文章、標籤或分類的翻譯草稿（標記為待審閱）
+func MachineTranslate(c *gin.Context) {
+	var req MachineTranslateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	target, ok := translationTargets[req.Type]
+	if !ok {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrTranslationTypeUnsupported, locales.Params{"allowed": locales.List{"article", "tag", "category"}}))
+		return
+	}
+
+	if translator == nil {
+		c.JSON(http.StatusServiceUnavailable, errorBody(c, locales.ErrMachineTranslationDisabled))
+		return
+	}
+
+	// 確認項目存在
+	var ownerCount int64
+	if err := config.DB.Table(target.ownerTable).Where("id = ? AND deleted_at IS NULL", req.ID).Count(&ownerCount).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+	if ownerCount == 0 {
+		c.JSON(http.StatusNotFound, errorBody(c, target.notFound))
+		return
+	}
+
+	// 載入來源語言翻譯
+	sourceLang := req.SourceLanguage
+	if sourceLang == "" {
+		sourceLang = languageRegistry.DefaultCode()
+	}
+
+	columns := append([]string{"slug", "source_revision"}, target.textColumns...)
+	var sources []map[string]interface{}
+	if err := config.DB.Table(target.table).Select(columns).
+		Where(target.ownerColumn+" = ? AND language_code = ? AND deleted_at IS NULL", req.ID, sourceLang).
+		Limit(1).Find(&sources).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+	if len(sources) == 0 {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrTranslationSourceMissing, locales.Params{"language": sourceLang}))
+		return
+	}
+	source := sources[0]
+
+	// 確認目標語言皆已啟用
+	targets := req.TargetLanguages
+	if len(targets) == 0 {
+		targets = languageRegistry.ActiveCodes()
+	}
+	var targetLangs []string
+	for _, langCode := range uniqueStrings(targets) {
+		if language, ok := languageRegistry.Get(langCode); !ok || !language.IsActive {
+			c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrLanguageNotActive))
+			return
+		}
+		if langCode != sourceLang {
+			targetLangs = append(targetLangs, langCode)
+		}
+	}
+
+	// 已存在的翻譯（包含已刪除的，以免違反唯一索引）
+	var existingRows []existingTranslation
+	if err := config.DB.Unscoped().Table(target.table).
+		Select("id, language_code, slug, machine_translated, review_status, deleted_at").
+		Where(target.ownerColumn+" = ? AND language_code IN ?", req.ID, targetLangs).
+		Find(&existingRows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+	existing := make(map[string]existingTranslation, len(existingRows))
+	for _, row := range existingRows {
+		existing[row.LanguageCode] = row
+	}
+
+	fields := make([]string, len(target.textColumns))
+	for i, column := range target.textColumns {
+		fields[i] = columnString(source[column])
+	}
+
+	// 先完成所有翻譯，再於同一個事務中寫入
+	results := make([]machineTranslationResult, 0, len(targetLangs))
+	drafts := make(map[string][]string)
+	for _, langCode := range targetLangs {
+		if row, ok := existing[langCode]; ok && !row.DeletedAt.Valid {
+			reason := ""
+			switch {
+			case !row.MachineTranslated:
+				reason = "human_translation"
+			case row.ReviewStatus != "pending":
+				reason = "reviewed"
+			case !req.Overwrite:
+				reason = "exists"
+			}
+			if reason != "" {
+				results = append(results, machineTranslationResult{LanguageCode: langCode, Status: "skipped", Reason: reason})
+				continue
+			}
+		}
+
+		translated, err := services.TranslateFields(c.Request.Context(), translator, fields, sourceLang, langCode, 0)
+		if err != nil {
+			c.JSON(http.StatusBadGateway, errorDetail(c, locales.ErrMachineTranslationFailed, err))
+			return
+		}
+		drafts[langCode] = translated
+	}
+
+	// 開始事務
+	tx := config.DB.Begin()
+
+	now := time.Now()
+	for _, langCode := range targetLangs {
+		translated, ok := drafts[langCode]
+		if !ok {
+			continue
+		}
+
+		values := map[string]interface{}{
+			"source_revision":    source["source_revision"],
+			"needs_update":       false,
+			"machine_translated": true,
+			"translation_engine": translator.Name(),
+			"review_status":      "pending",
+			"updated_at":         now,
+		}
+		for i, column := range target.textColumns {
+			values[column] = translated[i]
+		}
+
+		slugValue, err := uniqueTranslationSlug(tx, target, req.ID, langCode, translated[0], columnString(source["slug"]))
+		if err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+			return
+		}
+		values["slug"] = slugValue
+
+		status := "created"
+		if row, ok := existing[langCode]; ok {
+			status = "updated"
+			values["deleted_at"] = nil
+			err = recordSlugChange(tx, req.Type, req.ID, langCode, row.Slug, slugValue)
+			if err == nil {
+				err = tx.Table(target.table).Where("id = ?", row.ID).Updates(values).Error
+			}
+		} else {
+			values[target.ownerColumn] = req.ID
+			values["language_code"] = langCode
+			values["created_at"] = now
+			err = tx.Table(target.table).Create(values).Error
+		}
+		if err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+			return
+		}
+
+		results = append(results, machineTranslationResult{LanguageCode: langCode, Status: status})
+	}
+
+	// 翻譯已變更，遞增文章、標籤或分類的版本號
+	if len(drafts) > 0 {
+		if err := touchVersions(tx, target.ownerTable, req.ID); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+			return
+		}
+	}
+
+	// 提交事務
+	tx.Commit()
+
+	if req.Type == "article" {
+		relatedService.Invalidate(req.ID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"type":            req.Type,
+		"id":              req.ID,
+		"source_language": sourceLang,
+		"translator":      translator.Name(),
+		"results":         results,
+	})
+}
+
+// 將機器翻譯標記為已審閱
+func ReviewTranslation(c *gin.Context) {
+	var req ReviewTranslationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	target, ok := translationTargets[req.Type]
+	if !ok {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrTranslationTypeUnsupported, locales.Params{"allowed": locales.List{"article", "tag", "category"}}))
+		return
+	}
+
+	result := config.DB.Table(target.table).
+		Where(target.ownerColumn+" = ? AND language_code = ? AND review_status = ? AND deleted_at IS NULL", req.ID, req.LanguageCode, "pending").
+		Updates(map[string]interface{}{"review_status": "reviewed", "updated_at": time.Now()})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, result.Error))
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, errorBody(c, locales.ErrTranslationNotPending))
+		return
+	}
+
+	// 審閱狀態已變更，遞增文章、標籤或分類的版本號
+	if err := touchVersions(config.DB, target.ownerTable, req.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	// 審閱後的翻譯納入翻譯記憶
+	translationMemory.Invalidate()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "翻譯已標記為已審閱",
+	})
+}
+
+// 依翻譯後的標題產生同語言內不重複的 slug；無法產生時沿用來源語言的 slug
+func uniqueTranslationSlug(tx *gorm.DB, target translationTarget, ownerID uint, langCode, title, sourceSlug string) (string, error) {
+	base := makeSlug(langCode, title)
+	if base == "" {
+		base = sourceSlug
+	}
+	return slugService.Unique(tx, target.table, target.ownerColumn, ownerID, langCode, base)
+}
+
+// 將資料庫欄位值轉為字串
+func columnString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return strings.TrimSpace(fmt.Sprint(value))
+}
@@ -0,0 +1,434 @@
+package controllers
+
+import (
+	"GolangBlog/config"
+	"GolangBlog/locales"
+	"GolangBlog/models"
+	"GolangBlog/services"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 電子報服務
+var newsletterService *services.NewsletterService
+
+// 訂閱請求結構
+type SubscribeRequest struct {
+	Email        string `json:"email" binding:"required,email"`
+	LanguageCode string `json:"language_code" binding:"required"`
+	CategoryIDs  []uint `json:"category_ids"`
+}
+
+// 初始化電子報服務並啟動排程（需在資料庫連接後呼叫）
+func InitNewsletterService(db *gorm.DB) {
+	smtpPort, _ := strconv.Atoi(os.Getenv("MAIL_SMTP_PORT"))
+
+	mailer, err := services.NewMailer(services.MailConfig{
+		Strategy:     os.Getenv("MAIL_STRATEGY"),
+		From:         os.Getenv("MAIL_FROM"),
+		FileDir:      os.Getenv("MAIL_FILE_DIR"),
+		SMTPHost:     os.Getenv("MAIL_SMTP_HOST"),
+		SMTPPort:     smtpPort,
+		SMTPUsername: os.Getenv("MAIL_SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("MAIL_SMTP_PASSWORD"),
+	})
+	if err != nil {
+		log.Fatalf("初始化郵件服務失敗: %v", err)
+	}
+
+	digestHour, err := strconv.Atoi(os.Getenv("NEWSLETTER_DIGEST_HOUR"))
+	if err != nil {
+		digestHour = 8 // 預設早上 8 點
+	}
+
+	digestWeekday := time.Monday
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(d.String(), os.Getenv("NEWSLETTER_DIGEST_WEEKDAY")) {
+			digestWeekday = d
+		}
+	}
+
+	confirmInterval, err := time.ParseDuration(os.Getenv("NEWSLETTER_CONFIRM_INTERVAL"))
+	if err != nil {
+		confirmInterval = 10 * time.Minute
+	}
+
+	confirmTTL, err := time.ParseDuration(os.Getenv("NEWSLETTER_CONFIRM_TTL"))
+	if err != nil {
+		confirmTTL = 48 * time.Hour
+	}
+
+	newsletterService = services.NewNewsletterService(services.NewsletterConfig{
+		SiteName:      os.Getenv("NEWSLETTER_SITE_NAME"),
+		SiteURL:       os.Getenv("NEWSLETTER_SITE_URL"),
+		APIURL:        os.Getenv("NEWSLETTER_API_URL"),
+		DigestWeekday: digestWeekday,
+		DigestHour:    digestHour,
+
+		ConfirmInterval: confirmInterval,
+		ConfirmTTL:      confirmTTL,
+	}, db, mailer)
+
+	if os.Getenv("NEWSLETTER_SCHEDULER") != "false" {
+		newsletterService.StartScheduler()
+	}
+}
+
+// 將 ID 列表轉為以逗號分隔的字串
+func formatIDList(ids []uint) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range uniqueIDs(ids) {
+		parts = append(parts, strconv.FormatUint(uint64(id), 10))
+	}
+	return strings.Join(parts, ",")
+}
+
+// 解析以逗號分隔的 ID 列表（略過無效的值）
+func parseIDList(value string) []uint {
+	var ids []uint
+	for _, idStr := range strings.Split(value, ",") {
+		if id, err := strconv.ParseUint(strings.TrimSpace(idStr), 10, 64); err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
+
+// 訂閱電子報（需透過確認信完成雙重確認）
+func Subscribe(c *gin.Context) {
+	var req SubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	// 確認語言存在且已啟用
+	var language models.Language
+	if err := config.DB.Where("code = ? AND is_active = ? AND is_preview = ?", req.LanguageCode, true, false).First(&language).Error; err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrLanguageNotActive))
+		return
+	}
+
+	// 確認分類存在（重複的 ID 只計一次）
+	req.CategoryIDs = uniqueIDs(req.CategoryIDs)
+	var categories []models.Category
+	if len(req.CategoryIDs) > 0 {
+		config.DB.Where("id IN ?", req.CategoryIDs).Find(&categories)
+		if len(categories) != len(req.CategoryIDs) {
+			c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrCategoryNotFound))
+			return
+		}
+	}
+
+	var subscriber models.NewsletterSubscriber
+	result := config.DB.Where("email = ? AND language_code = ?", email, req.LanguageCode).First(&subscriber)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, result.Error))
+		return
+	}
+
+	if result.Error == gorm.ErrRecordNotFound {
+		unsubscribeToken, err := services.GenerateToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrSubscriptionTokenFailed))
+			return
+		}
+		subscriber = models.NewsletterSubscriber{
+			Email:            email,
+			LanguageCode:     req.LanguageCode,
+			Status:           services.SubscriberPending,
+			UnsubscribeToken: unsubscribeToken,
+		}
+	}
+
+	// 短時間內已寄過確認信時不再寄送（回應相同，不透露訂閱狀態）
+	if newsletterService.ConfirmationThrottled(&subscriber) {
+		c.JSON(http.StatusAccepted, gin.H{"message": "確認信已寄出，請至信箱完成訂閱"})
+		return
+	}
+
+	// 新訂閱與分類變更都要透過確認信中的連結才會生效；已確認的訂閱在確認前維持原本的分類
+	confirmToken, err := services.GenerateToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrSubscriptionTokenFailed))
+		return
+	}
+	if subscriber.Status != services.SubscriberConfirmed {
+		subscriber.Status = services.SubscriberPending
+	}
+	now := time.Now()
+	subscriber.ConfirmToken = confirmToken
+	subscriber.ConfirmSentAt = &now
+	subscriber.PendingCategories = formatIDList(req.CategoryIDs)
+
+	if err := config.DB.Save(&subscriber).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrSubscriptionSaveFailed))
+		return
+	}
+
+	if err := newsletterService.SendConfirmation(&subscriber); err != nil {
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrConfirmationEmailFailed))
+		return
+	}
+
+	// 不論信箱是否已訂閱都回傳相同的回應，避免透露訂閱者名單
+	c.JSON(http.StatusAccepted, gin.H{"message": "確認信已寄出，請至信箱完成訂閱"})
+}
+
+// 確認訂閱
+func ConfirmSubscription(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrConfirmTokenMissing))
+		return
+	}
+
+	var subscriber models.NewsletterSubscriber
+	if err := config.DB.Where("confirm_token = ?", token).First(&subscriber).Error; err != nil {
+		c.JSON(http.StatusNotFound, errorBody(c, locales.ErrConfirmTokenInvalid))
+		return
+	}
+
+	// 過期的確認連結不可再套用待確認的分類
+	if newsletterService.ConfirmationExpired(&subscriber) {
+		c.JSON(http.StatusGone, errorBody(c, locales.ErrConfirmTokenExpired))
+		return
+	}
+
+	// 套用訂閱時選擇的分類（已刪除的分類略過）
+	var categories []models.Category
+	if categoryIDs := parseIDList(subscriber.PendingCategories); len(categoryIDs) > 0 {
+		if err := config.DB.Where("id IN ?", categoryIDs).Find(&categories).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+			return
+		}
+	}
+
+	now := time.Now()
+	if subscriber.Status != services.SubscriberConfirmed {
+		subscriber.ConfirmedAt = &now
+	}
+	subscriber.Status = services.SubscriberConfirmed
+	subscriber.ConfirmToken = ""
+	subscriber.ConfirmSentAt = nil
+	subscriber.PendingCategories = ""
+	subscriber.UnsubscribedAt = nil
+
+	// 啟動事務
+	tx := config.DB.Begin()
+
+	if err := tx.Save(&subscriber).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrSubscriptionConfirmFailed))
+		return
+	}
+
+	if err := tx.Model(&subscriber).Association("Categories").Replace(categories); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrSubscriptionCategoriesUpdateFailed))
+		return
+	}
+
+	// 提交事務
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{"message": "訂閱確認成功"})
+}
+
+// 依退訂令牌找出訂閱者
+func findUnsubscribeSubscriber(c *gin.Context) (*models.NewsletterSubscriber, bool) {
+	token := c.Query("token")
+	if token == "" {
+		token = c.PostForm("token")
+	}
+	if token == "" {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrUnsubscribeTokenMissing))
+		return nil, false
+	}
+
+	var subscriber models.NewsletterSubscriber
+	if err := config.DB.Where("unsubscribe_token = ?", token).First(&subscriber).Error; err != nil {
+		c.JSON(http.StatusNotFound, errorBody(c, locales.ErrUnsubscribeTokenInvalid))
+		return nil, false
+	}
+	return &subscriber, true
+}
+
+// 回應退訂頁面
+func renderUnsubscribePage(c *gin.Context, subscriber *models.NewsletterSubscriber) {
+	page, err := newsletterService.RenderUnsubscribePage(subscriber)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+}
+
+// 顯示退訂確認頁面（不變更訂閱狀態，避免郵件掃描或預先載入連結時誤退訂）
+func UnsubscribePage(c *gin.Context) {
+	subscriber, ok := findUnsubscribeSubscriber(c)
+	if !ok {
+		return
+	}
+	renderUnsubscribePage(c, subscriber)
+}
+
+// 取消訂閱（確認頁面的表單與郵件客戶端的一鍵退訂皆以 POST 送出）
+func Unsubscribe(c *gin.Context) {
+	subscriber, ok := findUnsubscribeSubscriber(c)
+	if !ok {
+		return
+	}
+
+	if subscriber.Status != services.SubscriberUnsubscribed {
+		now := time.Now()
+		subscriber.Status = services.SubscriberUnsubscribed
+		subscriber.UnsubscribedAt = &now
+
+		if err := config.DB.Save(subscriber).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrUnsubscribeFailed))
+			return
+		}
+	}
+
+	// 從確認頁面送出時顯示結果頁面
+	if c.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+		renderUnsubscribePage(c, subscriber)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "已取消訂閱"})
+}
+
+// 獲取訂閱者列表
+func GetSubscribers(c *gin.Context) {
+	var subscribers []models.NewsletterSubscriber
+
+	query := config.DB.Model(&models.NewsletterSubscriber{})
+
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if langCode := c.Query("lang"); langCode != "" {
+		query = query.Where("language_code = ?", langCode)
+	}
+
+	query = query.Preload("Categories.Translations").Order("created_at desc")
+
+	// 分頁
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	offset := (page - 1) * pageSize
+
+	var total int64
+	query.Count(&total)
+
+	query.Limit(pageSize).Offset(offset).Find(&subscribers)
+
+	c.JSON(http.StatusOK, gin.H{
+		"subscribers": subscribers,
+		"pagination": gin.H{
+			"total":      total,
+			"page":       page,
+			"page_size":  pageSize,
+			"total_page": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// 獲取電子報發送紀錄
+func GetNewsletterLogs(c *gin.Context) {
+	var logs []models.NewsletterSendLog
+
+	query := config.DB.Model(&models.NewsletterSendLog{})
+
+	if kind := c.Query("kind"); kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if digestKey := c.Query("digest_key"); digestKey != "" {
+		query = query.Where("digest_key = ?", digestKey)
+	}
+
+	query = query.Order("created_at desc")
+
+	// 分頁
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	offset := (page - 1) * pageSize
+
+	var total int64
+	query.Count(&total)
+
+	query.Limit(pageSize).Offset(offset).Find(&logs)
+
+	c.JSON(http.StatusOK, gin.H{
+		"logs": logs,
+		"pagination": gin.H{
+			"total":      total,
+			"page":       page,
+			"page_size":  pageSize,
+			"total_page": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// 預覽電子報內容
+func PreviewDigest(c *gin.Context) {
+	langCode := c.DefaultQuery("lang", languageRegistry.DefaultCode())
+
+	categoryIDs := parseIDList(c.Query("category_ids"))
+
+	until := time.Now().Truncate(time.Hour)
+	digest, err := newsletterService.BuildDigest(langCode, categoryIDs, until.AddDate(0, 0, -7), until)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	htmlBody, textBody, err := newsletterService.RenderDigest(digest, "preview")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	if c.Query("format") == "html" {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(htmlBody))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"digest_key":    digest.Key,
+		"article_count": len(digest.Articles),
+		"html":          htmlBody,
+		"text":          textBody,
+	})
+}
+
+// 立即發送本期電子報
+func SendDigest(c *gin.Context) {
+	report, err := newsletterService.SendWeeklyDigest(time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrNewsletterSendFailed))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "電子報發送完成",
+		"report":  report,
+	})
+}
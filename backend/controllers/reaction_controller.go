@@ -0,0 +1,420 @@
+package controllers
+
+import (
+	"GolangBlog/config"
+	"GolangBlog/locales"
+	"GolangBlog/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 允許的反應類型
+var reactionTypes = []string{"like", "love", "haha", "wow", "sad", "angry"}
+
+// 反應請求結構
+type ReactionRequest struct {
+	Type string `json:"type" binding:"required"`
+}
+
+// 收藏請求結構
+type BookmarkRequest struct {
+	ArticleID     uint   `json:"article_id" binding:"required"`
+	ReadingListID *uint  `json:"reading_list_id"`
+	Note          string `json:"note"`
+}
+
+// 閱讀清單請求結構
+type ReadingListRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description"`
+}
+
+// 檢查反應類型是否有效
+func isValidReactionType(t string) bool {
+	for _, rt := range reactionTypes {
+		if rt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// 批次填入文章的反應數量
+func attachReactionCounts(articles []models.Article) {
+	if len(articles) == 0 {
+		return
+	}
+
+	ids := make([]uint, 0, len(articles))
+	for _, a := range articles {
+		ids = append(ids, a.ID)
+	}
+
+	type reactionCount struct {
+		ArticleID uint
+		Type      string
+		Count     int64
+	}
+
+	var counts []reactionCount
+	config.DB.Model(&models.ArticleReaction{}).
+		Select("article_id, type, COUNT(*) AS count").
+		Where("article_id IN ?", ids).
+		Group("article_id, type").
+		Scan(&counts)
+
+	byArticle := make(map[uint]map[string]int64)
+	for _, rc := range counts {
+		if byArticle[rc.ArticleID] == nil {
+			byArticle[rc.ArticleID] = make(map[string]int64)
+		}
+		byArticle[rc.ArticleID][rc.Type] = rc.Count
+	}
+
+	for i := range articles {
+		articles[i].ReactionCounts = byArticle[articles[i].ID]
+		if articles[i].ReactionCounts == nil {
+			articles[i].ReactionCounts = map[string]int64{}
+		}
+	}
+}
+
+// 確認文章存在且已發布
+func findPublishedArticle(c *gin.Context, id interface{}) (*models.Article, bool) {
+	var article models.Article
+	if err := config.DB.Where("status = ?", "published").First(&article, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrArticleNotPublished))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return nil, false
+	}
+	return &article, true
+}
+
+// 獲取文章的反應統計
+func GetArticleReactions(c *gin.Context) {
+	article, ok := findPublishedArticle(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	articles := []models.Article{*article}
+	attachReactionCounts(articles)
+
+	c.JSON(http.StatusOK, gin.H{
+		"article_id":      article.ID,
+		"reaction_counts": articles[0].ReactionCounts,
+	})
+}
+
+// 新增對文章的反應
+func AddArticleReaction(c *gin.Context) {
+	article, ok := findPublishedArticle(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req ReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	if !isValidReactionType(req.Type) {
+		body := errorBody(c, locales.ErrReactionTypeInvalid)
+		body["allowed"] = reactionTypes
+		c.JSON(http.StatusBadRequest, body)
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	reaction := models.ArticleReaction{
+		ArticleID: article.ID,
+		UserID:    userID.(uint),
+		Type:      req.Type,
+	}
+
+	// 重複的反應直接忽略
+	if err := config.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&reaction).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrReactionAddFailed))
+		return
+	}
+
+	articles := []models.Article{*article}
+	attachReactionCounts(articles)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "反應新增成功",
+		"reaction_counts": articles[0].ReactionCounts,
+	})
+}
+
+// 移除對文章的反應
+func RemoveArticleReaction(c *gin.Context) {
+	article, ok := findPublishedArticle(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	if err := config.DB.Where("article_id = ? AND user_id = ? AND type = ?", article.ID, userID, c.Param("type")).
+		Delete(&models.ArticleReaction{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrReactionRemoveFailed))
+		return
+	}
+
+	articles := []models.Article{*article}
+	attachReactionCounts(articles)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "反應移除成功",
+		"reaction_counts": articles[0].ReactionCounts,
+	})
+}
+
+// 獲取當前使用者的收藏
+func GetBookmarks(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var bookmarks []models.Bookmark
+
+	query := config.DB.Model(&models.Bookmark{}).Where("user_id = ?", userID)
+
+	// 閱讀清單篩選，"none" 表示未分類
+	listID := c.Query("reading_list_id")
+	if listID == "none" {
+		query = query.Where("reading_list_id IS NULL")
+	} else if listID != "" {
+		query = query.Where("reading_list_id = ?", listID)
+	}
+
+	// 語言篩選（依退回順序）
+	langCode := explicitLanguage(c)
+	var chain []string
+	if langCode != "" {
+		chain = languageChain(c, langCode)
+	}
+
+	query = query.Preload("Article.Translations", translationScope(c, chain)).Preload("ReadingList").Order("created_at desc")
+
+	// 分頁
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	offset := (page - 1) * pageSize
+
+	var total int64
+	query.Count(&total)
+
+	query.Limit(pageSize).Offset(offset).Find(&bookmarks)
+
+	if chain != nil {
+		for i := range bookmarks {
+			articles := []models.Article{bookmarks[i].Article}
+			localizeArticles(articles, chain)
+			bookmarks[i].Article = articles[0]
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"bookmarks": bookmarks,
+		"pagination": gin.H{
+			"total":      total,
+			"page":       page,
+			"page_size":  pageSize,
+			"total_page": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// 新增或更新收藏
+func SaveBookmark(c *gin.Context) {
+	var req BookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	if _, ok := findPublishedArticle(c, req.ArticleID); !ok {
+		return
+	}
+
+	userID, _ := c.Get("userID")
+
+	// 確認閱讀清單屬於當前使用者
+	if req.ReadingListID != nil {
+		var list models.ReadingList
+		if err := config.DB.Where("user_id = ?", userID).First(&list, *req.ReadingListID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrReadingListNotFound))
+			return
+		}
+	}
+
+	bookmark := models.Bookmark{
+		UserID:        userID.(uint),
+		ArticleID:     req.ArticleID,
+		ReadingListID: req.ReadingListID,
+		Note:          req.Note,
+	}
+
+	// 已收藏的文章更新清單與備註
+	if err := config.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "article_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reading_list_id", "note", "updated_at"}),
+	}).Create(&bookmark).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrBookmarkAddFailed))
+		return
+	}
+
+	var fullBookmark models.Bookmark
+	config.DB.Preload("Article.Translations").Preload("ReadingList").
+		Where("user_id = ? AND article_id = ?", userID, req.ArticleID).First(&fullBookmark)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "文章收藏成功",
+		"bookmark": fullBookmark,
+	})
+}
+
+// 取消收藏
+func DeleteBookmark(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	result := config.DB.Where("user_id = ? AND article_id = ?", userID, c.Param("article_id")).Delete(&models.Bookmark{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrBookmarkRemoveFailed))
+		return
+	}
+
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, errorBody(c, locales.ErrBookmarkNotFound))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "取消收藏成功",
+	})
+}
+
+// 獲取當前使用者的閱讀清單
+func GetReadingLists(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	type readingListWithCount struct {
+		models.ReadingList
+		BookmarkCount int64 `json:"bookmark_count"`
+	}
+
+	var lists []readingListWithCount
+	if err := config.DB.Model(&models.ReadingList{}).
+		Select("reading_lists.*, (SELECT COUNT(*) FROM bookmarks WHERE bookmarks.reading_list_id = reading_lists.id) AS bookmark_count").
+		Where("user_id = ?", userID).
+		Order("created_at asc").
+		Scan(&lists).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reading_lists": lists})
+}
+
+// 創建閱讀清單
+func CreateReadingList(c *gin.Context) {
+	var req ReadingListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	list := models.ReadingList{
+		UserID:      userID.(uint),
+		Name:        req.Name,
+		Description: req.Description,
+	}
+
+	if err := config.DB.Create(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrReadingListCreateFailed))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "閱讀清單創建成功",
+		"reading_list": list,
+	})
+}
+
+// 更新閱讀清單
+func UpdateReadingList(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var list models.ReadingList
+
+	if err := config.DB.Where("user_id = ?", userID).First(&list, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrReadingListNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return
+	}
+
+	var req ReadingListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	list.Name = req.Name
+	list.Description = req.Description
+
+	if err := config.DB.Save(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrReadingListUpdateFailed))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "閱讀清單更新成功",
+		"reading_list": list,
+	})
+}
+
+// 刪除閱讀清單（清單內的收藏改為未分類）
+func DeleteReadingList(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	var list models.ReadingList
+
+	if err := config.DB.Where("user_id = ?", userID).First(&list, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrReadingListNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return
+	}
+
+	// 啟動事務
+	tx := config.DB.Begin()
+
+	if err := tx.Model(&models.Bookmark{}).Where("reading_list_id = ?", list.ID).Update("reading_list_id", nil).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrBookmarkUpdateFailed))
+		return
+	}
+
+	if err := tx.Delete(&list).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrReadingListDeleteFailed))
+		return
+	}
+
+	// 提交事務
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "閱讀清單刪除成功",
+	})
+}
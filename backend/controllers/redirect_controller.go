@@ -0,0 +1,316 @@
+package controllers
+
+import (
+	"GolangBlog/config"
+	"GolangBlog/locales"
+	"GolangBlog/middlewares"
+	"GolangBlog/models"
+	"GolangBlog/services"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 自訂轉址請求結構
+type SlugRedirectRequest struct {
+	Type         string `json:"type" binding:"required"`
+	LanguageCode string `json:"language_code" binding:"required"`
+	OldSlug      string `json:"old_slug" binding:"required,max=255"`
+	TargetID     uint   `json:"target_id" binding:"required"`
+}
+
+// 記錄 slug 變更：保留舊 slug 作為轉址，並移除與新 slug 相同的舊轉址（新 slug 已重新使用）
+func recordSlugChange(tx *gorm.DB, kind string, ownerID uint, langCode, oldSlug, newSlug string) error {
+	if oldSlug == "" || oldSlug == newSlug {
+		return nil
+	}
+
+	if err := tx.Unscoped().Where("type = ? AND language_code = ? AND old_slug = ?", kind, langCode, newSlug).
+		Delete(&models.SlugRedirect{}).Error; err != nil {
+		return err
+	}
+
+	redirect := models.SlugRedirect{Type: kind, LanguageCode: langCode, OldSlug: oldSlug, TargetID: ownerID}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "type"}, {Name: "language_code"}, {Name: "old_slug"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"target_id": ownerID, "is_custom": false, "updated_at": time.Now()}),
+	}).Create(&redirect).Error
+}
+
+// 以舊 slug 尋找轉址：找到時回傳 301 並指向目前的 slug，回傳是否已處理
+func redirectOldSlug(c *gin.Context, kind, oldSlug string, chain []string) bool {
+	var redirects []models.SlugRedirect
+	if err := config.DB.Where("type = ? AND old_slug = ? AND language_code IN ?", kind, oldSlug, chain).
+		Find(&redirects).Error; err != nil || len(redirects) == 0 {
+		return false
+	}
+
+	codes := make([]string, len(redirects))
+	for i, redirect := range redirects {
+		codes[i] = redirect.LanguageCode
+	}
+	redirect := redirects[pickLanguage(chain, codes)]
+
+	// 未發布的文章不透露目前的 slug
+	if kind == "article" {
+		var count int64
+		config.DB.Model(&models.Article{}).Where("id = ? AND status = ?", redirect.TargetID, "published").Count(&count)
+		if count == 0 {
+			return false
+		}
+	}
+
+	// 優先使用舊 slug 所屬語言的目前 slug，該語言翻譯已刪除時依退回順序挑選
+	langCode, canonical := canonicalSlug(kind, redirect.TargetID, append([]string{redirect.LanguageCode}, chain...), reviewedOnly(c))
+	if canonical == "" || canonical == oldSlug {
+		return false
+	}
+
+	now := time.Now()
+	config.DB.Model(&models.SlugRedirect{}).Where("id = ?", redirect.ID).
+		Updates(map[string]interface{}{"hit_count": gorm.Expr("hit_count + 1"), "last_hit_at": now})
+
+	location := middlewares.LocalePath(c, path.Join(path.Dir(c.Request.URL.Path), url.PathEscape(canonical)))
+	if c.Request.URL.RawQuery != "" {
+		location += "?" + c.Request.URL.RawQuery
+	}
+
+	c.Header("Location", location)
+	c.JSON(http.StatusMovedPermanently, gin.H{
+		"redirect": gin.H{
+			"type":          kind,
+			"id":            redirect.TargetID,
+			"slug":          canonical,
+			"language_code": langCode,
+		},
+		"location": location,
+	})
+	return true
+}
+
+// 取得項目目前的 slug（依語言順序挑選），找不到時回傳空字串
+func canonicalSlug(kind string, ownerID uint, languages []string, onlyReviewed bool) (string, string) {
+	target := translationTargets[kind]
+
+	var rows []struct {
+		LanguageCode string
+		Slug         string
+	}
+	query := config.DB.Table(target.table+" AS t").Select("t.language_code, t.slug").
+		Joins("JOIN "+target.ownerTable+" AS o ON o.id = t."+target.ownerColumn+" AND o.deleted_at IS NULL").
+		Where("t."+target.ownerColumn+" = ? AND t.language_code IN ? AND t.deleted_at IS NULL", ownerID, languages)
+	if onlyReviewed {
+		query = query.Where(services.ReviewedTranslationFilter("t"))
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return "", ""
+	}
+
+	codes := make([]string, len(rows))
+	for i, row := range rows {
+		codes[i] = row.LanguageCode
+	}
+	if idx := pickLanguage(languages, codes); idx >= 0 {
+		return rows[idx].LanguageCode, rows[idx].Slug
+	}
+	return "", ""
+}
+
+// 獲取轉址列表（包含自動記錄的舊 slug 與自訂轉址）
+func GetSlugRedirects(c *gin.Context) {
+	var redirects []models.SlugRedirect
+	query := config.DB.Model(&models.SlugRedirect{})
+
+	// 篩選條件
+	if kind := c.Query("type"); kind != "" {
+		query = query.Where("type = ?", kind)
+	}
+	if langCode := c.Query("language_code"); langCode != "" {
+		query = query.Where("language_code = ?", langCode)
+	}
+	if targetID := c.Query("target_id"); targetID != "" {
+		query = query.Where("target_id = ?", targetID)
+	}
+	if custom := c.Query("custom"); custom != "" {
+		query = query.Where("is_custom = ?", custom == "true")
+	}
+	if keyword := strings.TrimSpace(c.Query("q")); keyword != "" {
+		query = query.Where("old_slug LIKE ?", "%"+keyword+"%")
+	}
+
+	// 分頁
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	offset := (page - 1) * pageSize
+
+	var total int64
+	query.Count(&total)
+
+	if err := query.Order("created_at DESC").Limit(pageSize).Offset(offset).Find(&redirects).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	// 填入目前的 slug
+	for i := range redirects {
+		_, redirects[i].CanonicalSlug = canonicalSlug(redirects[i].Type, redirects[i].TargetID, []string{redirects[i].LanguageCode}, false)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"redirects": redirects,
+		"pagination": gin.H{
+			"total":      total,
+			"page":       page,
+			"page_size":  pageSize,
+			"total_page": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// 新增自訂轉址
+func CreateSlugRedirect(c *gin.Context) {
+	var req SlugRedirectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+	req.OldSlug = strings.TrimSpace(req.OldSlug)
+
+	if !validateSlugRedirect(c, req, 0) {
+		return
+	}
+
+	redirect := models.SlugRedirect{
+		Type:         req.Type,
+		LanguageCode: req.LanguageCode,
+		OldSlug:      req.OldSlug,
+		TargetID:     req.TargetID,
+		IsCustom:     true,
+	}
+	if err := config.DB.Create(&redirect).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+	_, redirect.CanonicalSlug = canonicalSlug(redirect.Type, redirect.TargetID, []string{redirect.LanguageCode}, false)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "轉址新增成功",
+		"redirect": redirect,
+	})
+}
+
+// 更新轉址
+func UpdateSlugRedirect(c *gin.Context) {
+	var redirect models.SlugRedirect
+	if err := config.DB.First(&redirect, c.Param("id")).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrRedirectNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return
+	}
+
+	var req SlugRedirectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+	req.OldSlug = strings.TrimSpace(req.OldSlug)
+
+	if !validateSlugRedirect(c, req, redirect.ID) {
+		return
+	}
+
+	redirect.Type = req.Type
+	redirect.LanguageCode = req.LanguageCode
+	redirect.OldSlug = req.OldSlug
+	redirect.TargetID = req.TargetID
+	redirect.IsCustom = true
+
+	if err := config.DB.Save(&redirect).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+	_, redirect.CanonicalSlug = canonicalSlug(redirect.Type, redirect.TargetID, []string{redirect.LanguageCode}, false)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "轉址更新成功",
+		"redirect": redirect,
+	})
+}
+
+// 刪除轉址
+func DeleteSlugRedirect(c *gin.Context) {
+	result := config.DB.Unscoped().Delete(&models.SlugRedirect{}, c.Param("id"))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, result.Error))
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, errorBody(c, locales.ErrRedirectNotFound))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "轉址刪除成功",
+	})
+}
+
+// 驗證自訂轉址：類型與語言有效、目標存在、舊 slug 未被使用且未重複
+func validateSlugRedirect(c *gin.Context, req SlugRedirectRequest, redirectID uint) bool {
+	target, ok := translationTargets[req.Type]
+	if !ok {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrTranslationTypeUnsupported, locales.Params{"allowed": locales.List{"article", "tag", "category"}}))
+		return false
+	}
+	if _, ok := languageRegistry.Get(req.LanguageCode); !ok {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrLanguageNotActive))
+		return false
+	}
+	if req.OldSlug == "" || strings.ContainsAny(req.OldSlug, "/?#") {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrSlugInvalid))
+		return false
+	}
+
+	var count int64
+	if err := config.DB.Table(target.ownerTable).Where("id = ? AND deleted_at IS NULL", req.TargetID).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return false
+	}
+	if count == 0 {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrRedirectTargetNotFound))
+		return false
+	}
+
+	// 目前使用中的 slug 會優先於轉址，設為轉址不會生效
+	if err := config.DB.Table(target.table).Where("slug = ? AND language_code = ? AND deleted_at IS NULL", req.OldSlug, req.LanguageCode).
+		Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return false
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, errorBody(c, locales.ErrRedirectSlugInUse, locales.Params{"slug": req.OldSlug}))
+		return false
+	}
+
+	if err := config.DB.Model(&models.SlugRedirect{}).
+		Where("type = ? AND language_code = ? AND old_slug = ? AND id <> ?", req.Type, req.LanguageCode, req.OldSlug, redirectID).
+		Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return false
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, errorBody(c, locales.ErrRedirectExists))
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"GolangBlog/config"
+	"GolangBlog/locales"
+	"GolangBlog/models"
+	"GolangBlog/services"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 相關文章推薦服務
+var relatedService *services.RelatedService
+
+// 初始化相關文章推薦服務（需在資料庫連接後呼叫）
+func InitRelatedService(db *gorm.DB) {
+	cacheTTL, err := time.ParseDuration(os.Getenv("RELATED_CACHE_TTL"))
+	if err != nil {
+		cacheTTL = 15 * time.Minute
+	}
+
+	relatedService = services.NewRelatedService(services.RelatedConfig{
+		CacheTTL: cacheTTL,
+	}, db)
+}
+
+// 獲取相關文章
+func GetRelatedArticles(c *gin.Context) {
+	article, ok := findPublishedArticle(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	langCode, chain := requestLanguage(c)
+
+	limit := 5 // 默認返回5篇相關文章
+	if parsedLimit, err := strconv.Atoi(c.Query("limit")); err == nil && parsedLimit > 0 {
+		limit = min(parsedLimit, 20)
+	}
+
+	related, err := relatedService.Related(article.ID, langCode, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	ids := make([]uint, 0, len(related))
+	for _, r := range related {
+		ids = append(ids, r.ArticleID)
+	}
+
+	var found []models.Article
+	if len(ids) > 0 {
+		query := config.DB.Where("id IN ? AND status = ?", ids, "published")
+		query = query.Preload("Translations", translationScope(c, chain)).
+			Preload("Tags.Translations", translationScope(c, chain)).
+			Preload("User")
+
+		if err := query.Find(&found).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+			return
+		}
+	}
+
+	// 依推薦分數排序
+	byID := make(map[uint]models.Article, len(found))
+	for _, a := range found {
+		byID[a.ID] = a
+	}
+	articles := make([]models.Article, 0, len(found))
+	scores := make([]services.RelatedArticle, 0, len(found))
+	for _, r := range related {
+		if a, ok := byID[r.ArticleID]; ok {
+			articles = append(articles, a)
+			scores = append(scores, r)
+		}
+	}
+
+	// 依退回順序挑選翻譯並填入反應統計
+	localizeArticles(articles, chain)
+	attachReactionCounts(articles)
+
+	c.JSON(http.StatusOK, gin.H{
+		"articles": articles,
+		"scores":   scores,
+	})
+}
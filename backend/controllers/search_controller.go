@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"GolangBlog/locales"
 	"net/http"
 	"strings"
 
@@ -44,7 +45,7 @@ type SearchResults struct {
 func (sc *SearchController) Search(c *gin.Context) {
 	var req SearchRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid search parameters"})
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrSearchInvalidParams))
 		return
 	}
 
@@ -100,7 +101,7 @@ func (sc *SearchController) Search(c *gin.Context) {
 		Limit(req.PageSize)
 
 	if err := query.Find(&articles).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search articles"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrSearchFailed))
 		return
 	}
 
@@ -0,0 +1,515 @@
+package controllers
+
+import (
+	"GolangBlog/config"
+	"GolangBlog/locales"
+	"GolangBlog/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 文章系列請求結構
+type SeriesRequest struct {
+	Status       string                     `json:"status" binding:"omitempty,oneof=draft published"`
+	CoverImage   string                     `json:"cover_image"`
+	Translations []SeriesTranslationRequest `json:"translations" binding:"required,min=1"`
+}
+
+// 文章系列翻譯請求結構
+type SeriesTranslationRequest struct {
+	LanguageCode string `json:"language_code" binding:"required"`
+	Title        string `json:"title" binding:"required"`
+	Slug         string `json:"slug"`
+	Description  string `json:"description"`
+}
+
+// 文章系列排序請求結構
+type SeriesArticlesRequest struct {
+	ArticleIDs []uint `json:"article_ids"`
+}
+
+// 系列導覽中的相鄰文章
+type SeriesNavItem struct {
+	ArticleID uint   `json:"article_id"`
+	Position  int    `json:"position"`
+	Title     string `json:"title"`
+	Slug      string `json:"slug"`
+}
+
+// 文章在系列中的位置與前後篇導覽
+type SeriesNavigation struct {
+	SeriesID uint           `json:"series_id"`
+	Title    string         `json:"title"`
+	Slug     string         `json:"slug"`
+	Position int            `json:"position"`
+	Total    int            `json:"total"`
+	Previous *SeriesNavItem `json:"previous"`
+	Next     *SeriesNavItem `json:"next"`
+}
+
+// 取得文章所屬已發布系列的前後篇導覽；系列與文章標題依語言退回順序挑選，
+// 所有系列及其文章以批次查詢載入
+func buildSeriesNavigation(c *gin.Context, articleID uint, chain []string) []SeriesNavigation {
+	navigations := []SeriesNavigation{}
+
+	var seriesIDs []uint
+	config.DB.Model(&models.SeriesArticle{}).
+		Joins("JOIN series ON series.id = series_articles.series_id AND series.deleted_at IS NULL").
+		Where("series_articles.article_id = ? AND series.status = ?", articleID, "published").
+		Order("series_articles.series_id asc").
+		Pluck("series_articles.series_id", &seriesIDs)
+	if len(seriesIDs) == 0 {
+		return navigations
+	}
+
+	var seriesList []models.Series
+	if err := config.DB.Preload("Translations", chainScope(chain)).Order("id asc").Find(&seriesList, seriesIDs).Error; err != nil {
+		return navigations
+	}
+
+	// 只計入已發布的文章
+	var items []models.SeriesArticle
+	if err := config.DB.Joins("JOIN articles ON articles.id = series_articles.article_id AND articles.deleted_at IS NULL").
+		Where("series_articles.series_id IN ? AND articles.status = ?", seriesIDs, "published").
+		Preload("Article.Translations", translationScope(c, chain)).
+		Order("series_articles.series_id asc, series_articles.position asc").
+		Find(&items).Error; err != nil {
+		return navigations
+	}
+	itemsBySeries := make(map[uint][]models.SeriesArticle, len(seriesIDs))
+	for _, item := range items {
+		itemsBySeries[item.SeriesID] = append(itemsBySeries[item.SeriesID], item)
+	}
+
+	for _, series := range seriesList {
+		seriesItems := itemsBySeries[series.ID]
+		nav := SeriesNavigation{SeriesID: series.ID, Total: len(seriesItems)}
+
+		codes := make([]string, len(series.Translations))
+		for i, translation := range series.Translations {
+			codes[i] = translation.LanguageCode
+		}
+		if idx := pickLanguage(chain, codes); idx >= 0 {
+			nav.Title = series.Translations[idx].Title
+			nav.Slug = series.Translations[idx].Slug
+		}
+
+		for i, item := range seriesItems {
+			if item.ArticleID != articleID {
+				continue
+			}
+			nav.Position = i + 1
+			if i > 0 {
+				nav.Previous = seriesNavItem(seriesItems[i-1], i, chain)
+			}
+			if i+1 < len(seriesItems) {
+				nav.Next = seriesNavItem(seriesItems[i+1], i+2, chain)
+			}
+		}
+
+		navigations = append(navigations, nav)
+	}
+
+	return navigations
+}
+
+// 轉換為系列導覽項目（依語言退回順序挑選標題）
+func seriesNavItem(item models.SeriesArticle, position int, chain []string) *SeriesNavItem {
+	navItem := &SeriesNavItem{ArticleID: item.ArticleID, Position: position}
+
+	codes := make([]string, len(item.Article.Translations))
+	for i, translation := range item.Article.Translations {
+		codes[i] = translation.LanguageCode
+	}
+	if idx := pickLanguage(chain, codes); idx >= 0 {
+		navItem.Title = item.Article.Translations[idx].Title
+		navItem.Slug = item.Article.Translations[idx].Slug
+	}
+	return navItem
+}
+
+// 預載系列內的文章（依順序，可限定已發布文章與翻譯的語言範圍，scope 為 nil 時載入所有翻譯）
+func preloadSeriesItems(query *gorm.DB, scope func(db *gorm.DB) *gorm.DB, onlyPublished bool) *gorm.DB {
+	return query.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		db = db.Joins("JOIN articles ON articles.id = series_articles.article_id AND articles.deleted_at IS NULL")
+		if onlyPublished {
+			db = db.Where("articles.status = ?", "published")
+		}
+		return db.Order("series_articles.position asc")
+	}).Preload("Items.Article.Translations", func(db *gorm.DB) *gorm.DB {
+		if scope != nil {
+			return scope(db)
+		}
+		return db
+	})
+}
+
+// 獲取文章系列列表
+func GetSeriesList(c *gin.Context) {
+	var seriesList []models.Series
+
+	query := config.DB.Model(&models.Series{}).Where("status = ?", "published")
+
+	// 語言篩選
+	langCode := explicitLanguage(c)
+	if langCode != "" {
+		query = query.Preload("Translations", "language_code = ?", langCode)
+	} else {
+		query = query.Preload("Translations", visibleScope(c))
+	}
+
+	query = query.Order("created_at desc")
+
+	// 分頁
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	// 無效的分頁參數改用預設值，避免負數位移與除以零
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	offset := (page - 1) * pageSize
+
+	var total int64
+	query.Count(&total)
+
+	query.Limit(pageSize).Offset(offset).Find(&seriesList)
+
+	c.JSON(http.StatusOK, gin.H{
+		"series": seriesList,
+		"pagination": gin.H{
+			"total":      total,
+			"page":       page,
+			"page_size":  pageSize,
+			"total_page": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// 獲取單個文章系列（包含依序排列的文章）
+func GetSeries(c *gin.Context) {
+	id := c.Param("id")
+	langCode := explicitLanguage(c)
+	var series models.Series
+
+	query := config.DB.Where("status = ?", "published")
+	if langCode != "" {
+		query = query.Preload("Translations", "language_code = ?", langCode)
+	} else {
+		query = query.Preload("Translations", visibleScope(c))
+	}
+	scope := translationScope(c, nil)
+	if langCode != "" {
+		scope = translationScope(c, []string{langCode})
+	}
+	query = preloadSeriesItems(query, scope, true)
+
+	if err := query.First(&series, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrSeriesNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"series": series})
+}
+
+// 根據 Slug 獲取文章系列
+func GetSeriesBySlug(c *gin.Context) {
+	seriesSlug := c.Param("slug")
+	langCode := currentLanguage(c)
+
+	var translation models.SeriesTranslation
+	if err := config.DB.Where("slug = ? AND language_code = ?", seriesSlug, langCode).First(&translation).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrSeriesNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return
+	}
+
+	var series models.Series
+	query := config.DB.Where("status = ?", "published").Preload("Translations", "language_code = ?", langCode)
+	query = preloadSeriesItems(query, translationScope(c, []string{langCode}), true)
+
+	if err := query.First(&series, translation.SeriesID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrSeriesNotPublished))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"series": series})
+}
+
+// 獲取單個文章系列（管理用，包含未發布文章）
+func GetSeriesAdmin(c *gin.Context) {
+	id := c.Param("id")
+	var series models.Series
+
+	query := preloadSeriesItems(config.DB.Preload("Translations"), nil, false)
+	if err := query.First(&series, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrSeriesNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"series": series})
+}
+
+// 文章系列翻譯的 slug 檢查目標（系列不支援翻譯工作流程，因此不在 translationTargets 中）
+var seriesSlugTarget = translationTarget{ownerTable: "series", table: "series_translations", ownerColumn: "series_id", titleColumn: "title",
+	textColumns: []string{"title", "description"}, notFound: locales.ErrSeriesNotFound}
+
+// 儲存文章系列的翻譯（不存在則創建），失敗時回應錯誤並回傳 false，由呼叫者回滾事務
+func saveSeriesTranslations(c *gin.Context, tx *gorm.DB, seriesID uint, translations []SeriesTranslationRequest, failCode string) bool {
+	for _, trans := range translations {
+		// 未提供 slug 時自動生成不重複的 slug；指定的 slug 衝突或為保留字時回傳 409
+		slugValue, conflict, err := resolveSlug(tx, seriesSlugTarget, seriesID, trans.LanguageCode, trans.Slug, trans.Title)
+		if err != nil || conflict != nil {
+			respondSlugError(c, trans.LanguageCode, conflict, err)
+			return false
+		}
+
+		var translation models.SeriesTranslation
+		result := tx.Where("series_id = ? AND language_code = ?", seriesID, trans.LanguageCode).First(&translation)
+		if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+			c.JSON(http.StatusInternalServerError, errorBody(c, failCode))
+			return false
+		}
+
+		translation.SeriesID = seriesID
+		translation.LanguageCode = trans.LanguageCode
+		translation.Title = trans.Title
+		translation.Slug = slugValue
+		translation.Description = trans.Description
+
+		if err := tx.Save(&translation).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, errorBody(c, failCode))
+			return false
+		}
+	}
+	return true
+}
+
+// 創建文章系列
+func CreateSeries(c *gin.Context) {
+	var req SeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	series := models.Series{
+		Status:     req.Status,
+		CoverImage: req.CoverImage,
+	}
+	if series.Status == "" {
+		series.Status = "draft"
+	}
+
+	// 啟動事務
+	tx := config.DB.Begin()
+
+	if err := tx.Create(&series).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrSeriesCreateFailed))
+		return
+	}
+
+	if !saveSeriesTranslations(c, tx, series.ID, req.Translations, locales.ErrSeriesTranslationCreateFailed) {
+		tx.Rollback()
+		return
+	}
+
+	// 提交事務
+	tx.Commit()
+
+	var fullSeries models.Series
+	config.DB.Preload("Translations").First(&fullSeries, series.ID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "文章系列創建成功",
+		"series":  fullSeries,
+	})
+}
+
+// 更新文章系列
+func UpdateSeries(c *gin.Context) {
+	id := c.Param("id")
+	var series models.Series
+
+	if err := config.DB.First(&series, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrSeriesNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return
+	}
+
+	var req SeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	if req.Status != "" {
+		series.Status = req.Status
+	}
+	series.CoverImage = req.CoverImage
+
+	// 啟動事務
+	tx := config.DB.Begin()
+
+	if err := tx.Save(&series).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrSeriesUpdateFailed))
+		return
+	}
+
+	if !saveSeriesTranslations(c, tx, series.ID, req.Translations, locales.ErrSeriesTranslationUpdateFailed) {
+		tx.Rollback()
+		return
+	}
+
+	// 提交事務
+	tx.Commit()
+
+	var fullSeries models.Series
+	config.DB.Preload("Translations").First(&fullSeries, series.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "文章系列更新成功",
+		"series":  fullSeries,
+	})
+}
+
+// 刪除文章系列
+func DeleteSeries(c *gin.Context) {
+	id := c.Param("id")
+	var series models.Series
+
+	if err := config.DB.First(&series, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrSeriesNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return
+	}
+
+	// 啟動事務
+	tx := config.DB.Begin()
+
+	// 刪除文章關聯
+	if err := tx.Where("series_id = ?", series.ID).Delete(&models.SeriesArticle{}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrSeriesArticlesUnlinkFailed))
+		return
+	}
+
+	// 刪除翻譯
+	if err := tx.Where("series_id = ?", series.ID).Delete(&models.SeriesTranslation{}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrSeriesTranslationDeleteFailed))
+		return
+	}
+
+	if err := tx.Delete(&series).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrSeriesDeleteFailed))
+		return
+	}
+
+	// 提交事務
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "文章系列刪除成功",
+	})
+}
+
+// 設定文章系列的文章與順序（以傳入的順序完整取代）
+func SetSeriesArticles(c *gin.Context) {
+	id := c.Param("id")
+	var series models.Series
+
+	if err := config.DB.First(&series, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrSeriesNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return
+	}
+
+	var req SeriesArticlesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	// 檢查重複的文章
+	seen := make(map[uint]bool)
+	for _, articleID := range req.ArticleIDs {
+		if seen[articleID] {
+			c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrSeriesDuplicateArticle, locales.Params{"article_id": articleID}))
+			return
+		}
+		seen[articleID] = true
+	}
+
+	// 確認文章皆存在
+	if len(req.ArticleIDs) > 0 {
+		var count int64
+		config.DB.Model(&models.Article{}).Where("id IN ?", req.ArticleIDs).Count(&count)
+		if int(count) != len(req.ArticleIDs) {
+			missing := len(req.ArticleIDs) - int(count)
+			c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrSeriesArticlesMissing, locales.Params{"count": missing}))
+			return
+		}
+	}
+
+	// 啟動事務
+	tx := config.DB.Begin()
+
+	if err := tx.Where("series_id = ?", series.ID).Delete(&models.SeriesArticle{}).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrSeriesArticlesUnlinkFailed))
+		return
+	}
+
+	for i, articleID := range req.ArticleIDs {
+		item := models.SeriesArticle{SeriesID: series.ID, ArticleID: articleID, Position: i + 1}
+		if err := tx.Create(&item).Error; err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrSeriesArticlesLinkFailed))
+			return
+		}
+	}
+
+	// 提交事務
+	tx.Commit()
+
+	var fullSeries models.Series
+	preloadSeriesItems(config.DB.Preload("Translations"), nil, false).First(&fullSeries, series.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "系列文章順序更新成功",
+		"series":  fullSeries,
+	})
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"GolangBlog/locales"
+	"GolangBlog/services"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Slug 服務
+var slugService *services.SlugService
+
+// 初始化 Slug 服務；SLUG_RESERVED 以逗號分隔，未設定時使用預設的保留 slug
+func InitSlugService() {
+	config := services.SlugConfig{}
+	if value, ok := os.LookupEnv("SLUG_RESERVED"); ok {
+		config.Reserved = strings.Split(value, ",")
+	}
+	slugService = services.NewSlugService(config)
+}
+
+// 決定翻譯的 slug：未提供時依標題產生，重複時自動加上流水號；
+// 提供的 slug 已被使用、為保留字或無效時回傳衝突資訊
+func resolveSlug(tx *gorm.DB, target translationTarget, ownerID uint, langCode, requested, title string) (string, *services.SlugConflict, error) {
+	requested = strings.TrimSpace(requested)
+	if requested == "" {
+		slugValue, err := slugService.Unique(tx, target.table, target.ownerColumn, ownerID, langCode, makeSlug(langCode, title))
+		return slugValue, nil, err
+	}
+
+	conflict, err := slugService.Check(tx, target.table, target.ownerColumn, ownerID, langCode, requested)
+	return requested, conflict, err
+}
+
+// 回傳 slug 衝突（409）或查詢失敗的錯誤
+func respondSlugError(c *gin.Context, langCode string, conflict *services.SlugConflict, err error) {
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	code := locales.ErrSlugTaken
+	switch conflict.Reason {
+	case services.SlugReserved:
+		code = locales.ErrSlugReserved
+	case services.SlugInvalid:
+		code = locales.ErrSlugInvalid
+	}
+
+	body := errorBody(c, code, locales.Params{"slug": conflict.Slug, "suggestion": conflict.Suggestion})
+	body["conflict"] = gin.H{
+		"language_code": langCode,
+		"slug":          conflict.Slug,
+		"reason":        conflict.Reason,
+		"conflict_id":   conflict.ConflictID,
+		"suggestion":    conflict.Suggestion,
+	}
+	c.JSON(http.StatusConflict, body)
+}
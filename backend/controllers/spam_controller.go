@@ -0,0 +1,240 @@
+package controllers
+
+import (
+	"GolangBlog/config"
+	"GolangBlog/locales"
+	"GolangBlog/models"
+	"GolangBlog/services"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 垃圾訊息過濾服務
+var spamService *services.SpamService
+
+// 初始化垃圾訊息過濾服務（需在資料庫連接後呼叫）
+func InitSpamService(db *gorm.DB) {
+	maxLinks, err := strconv.Atoi(os.Getenv("SPAM_MAX_LINKS"))
+	if err != nil {
+		maxLinks = 2 // 預設最多 2 個連結
+	}
+
+	velocityLimit, err := strconv.Atoi(os.Getenv("SPAM_VELOCITY_LIMIT"))
+	if err != nil {
+		velocityLimit = 5 // 預設每個時間窗 5 次
+	}
+
+	velocityWindow, err := time.ParseDuration(os.Getenv("SPAM_VELOCITY_WINDOW"))
+	if err != nil {
+		velocityWindow = 10 * time.Minute
+	}
+
+	minSubmit, err := time.ParseDuration(os.Getenv("SPAM_MIN_SUBMIT_DURATION"))
+	if err != nil {
+		minSubmit = 3 * time.Second
+	}
+
+	spamService = services.NewSpamService(services.SpamConfig{
+		MaxLinks:          maxLinks,
+		BlockedWords:      splitEnvList("SPAM_BLOCKED_WORDS"),
+		BlockedDomains:    splitEnvList("SPAM_BLOCKED_DOMAINS"),
+		MinSubmitDuration: minSubmit,
+		VelocityLimit:     velocityLimit,
+		VelocityWindow:    velocityWindow,
+	}, db)
+}
+
+// 讀取以逗號分隔的環境變數
+func splitEnvList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// 檢查使用者提交的內容並寫入檢查紀錄
+func checkSpam(c *gin.Context, sub *services.SpamSubmission) (services.SpamResult, *models.SpamLog) {
+	sub.IP = c.ClientIP()
+	sub.UserAgent = c.Request.UserAgent()
+
+	result := spamService.Check(sub)
+
+	signals, _ := json.Marshal(result.Signals)
+	userAgent := sub.UserAgent
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	spamLog := &models.SpamLog{
+		Kind:        sub.Kind,
+		IP:          sub.IP,
+		UserAgent:   userAgent,
+		AuthorName:  sub.AuthorName,
+		AuthorEmail: sub.AuthorEmail,
+		Content:     sub.Content,
+		Score:       result.Score,
+		Verdict:     result.Verdict,
+		Signals:     string(signals),
+		Decision:    "pending",
+	}
+	config.DB.Create(spamLog)
+
+	return result, spamLog
+}
+
+// 獲取垃圾訊息檢查紀錄
+func GetSpamLogs(c *gin.Context) {
+	var logs []models.SpamLog
+
+	query := config.DB.Model(&models.SpamLog{})
+
+	// 類型篩選
+	if kind := c.Query("kind"); kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	// 判定結果篩選
+	if verdict := c.Query("verdict"); verdict != "" {
+		query = query.Where("verdict = ?", verdict)
+	}
+
+	// 審核狀態篩選
+	if decision := c.Query("decision"); decision != "" {
+		query = query.Where("decision = ?", decision)
+	}
+
+	query = query.Order("created_at desc")
+
+	// 分頁
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	offset := (page - 1) * pageSize
+
+	var total int64
+	query.Count(&total)
+
+	query.Limit(pageSize).Offset(offset).Find(&logs)
+
+	c.JSON(http.StatusOK, gin.H{
+		"logs": logs,
+		"pagination": gin.H{
+			"total":      total,
+			"page":       page,
+			"page_size":  pageSize,
+			"total_page": (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// 審核結果已被其他請求變更
+var errSpamDecisionChanged = errors.New("審核結果已被變更")
+
+// 審核垃圾訊息檢查紀錄，並以審核結果訓練分類器
+func UpdateSpamDecision(c *gin.Context) {
+	type DecisionRequest struct {
+		Decision string `json:"decision" binding:"required,oneof=spam ham"`
+	}
+
+	id := c.Param("id")
+	var spamLog models.SpamLog
+
+	if err := config.DB.First(&spamLog, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrSpamCheckNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return
+	}
+
+	var req DecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	if spamLog.Decision == req.Decision {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "審核結果未變更",
+			"log":     spamLog,
+		})
+		return
+	}
+
+	text := spamLog.Content + " " + spamLog.AuthorName
+	previous := spamLog.Decision
+
+	userID, _ := c.Get("userID")
+	decidedBy := userID.(uint)
+	now := time.Now()
+
+	spamLog.Decision = req.Decision
+	spamLog.DecidedBy = &decidedBy
+	spamLog.DecidedAt = &now
+
+	// 審核結果、分類器訓練與帳號狀態在同一個事務中寫入；
+	// 只在審核結果仍為讀取時的值才更新，避免同時審核時重複訓練
+	failCode := locales.ErrSpamReviewUpdateFailed
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.SpamLog{}).Where("id = ? AND decision = ?", spamLog.ID, previous).
+			Updates(map[string]interface{}{
+				"decision":   spamLog.Decision,
+				"decided_by": spamLog.DecidedBy,
+				"decided_at": spamLog.DecidedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errSpamDecisionChanged
+		}
+
+		// 撤銷先前的訓練結果
+		failCode = locales.ErrSpamClassifierUpdateFailed
+		if previous == services.SpamVerdictSpam || previous == services.SpamVerdictHam {
+			if err := spamService.Train(tx, text, previous == services.SpamVerdictSpam, -1); err != nil {
+				return err
+			}
+		}
+		if err := spamService.Train(tx, text, req.Decision == services.SpamVerdictSpam, 1); err != nil {
+			return err
+		}
+
+		// 可疑註冊的帳號依審核結果啟用或停用
+		failCode = locales.ErrSpamReviewUpdateFailed
+		if spamLog.Kind == services.SpamKindRegister && spamLog.ReferenceID != nil {
+			status := "active"
+			if req.Decision == services.SpamVerdictSpam {
+				status = "disabled"
+			}
+			if err := tx.Model(&models.User{}).Where("id = ?", *spamLog.ReferenceID).Update("status", status).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errSpamDecisionChanged) {
+		c.JSON(http.StatusConflict, errorBody(c, locales.ErrSpamDecisionConflict))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorBody(c, failCode))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "審核結果更新成功",
+		"log":     spamLog,
+	})
+}
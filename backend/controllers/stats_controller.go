@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"GolangBlog/config"
+	"GolangBlog/locales"
+	"GolangBlog/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 依狀態統計的文章數
+type articleStatusCount struct {
+	Status string `json:"status"`
+	Count  int64  `json:"count"`
+}
+
+// 依語言與狀態統計的文章數
+type articleLanguageCount struct {
+	LanguageCode string `json:"language_code"`
+	Status       string `json:"status"`
+	Count        int64  `json:"count"`
+}
+
+// 各語言的翻譯覆蓋率
+type translationCoverage struct {
+	LanguageCode string  `json:"language_code"`
+	Name         string  `json:"name"`
+	Translated   int64   `json:"translated"`
+	Total        int64   `json:"total"`
+	Coverage     float64 `json:"coverage"`
+}
+
+// 熱門文章
+type topArticle struct {
+	ID        uint   `json:"id"`
+	Title     string `json:"title"`
+	Slug      string `json:"slug"`
+	Status    string `json:"status"`
+	ViewCount int    `json:"view_count"`
+}
+
+// 依檔案類型統計的儲存空間
+type storageUsage struct {
+	ContentType string `json:"content_type"`
+	Files       int64  `json:"files"`
+	Bytes       int64  `json:"bytes"`
+}
+
+// 每日註冊數
+type registrationCount struct {
+	Day   string `json:"day"`
+	Count int64  `json:"count"`
+}
+
+// 獲取後台總覽統計
+func GetAdminStats(c *gin.Context) {
+	langCode := c.DefaultQuery("lang", languageRegistry.DefaultCode())
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days <= 0 || days > 365 {
+		days = 30
+	}
+
+	// 文章狀態統計
+	var byStatus []articleStatusCount
+	if err := config.DB.Model(&models.Article{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").Order("status").
+		Scan(&byStatus).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	var totalArticles int64
+	for _, s := range byStatus {
+		totalArticles += s.Count
+	}
+
+	// 各語言的文章狀態統計
+	var byLanguage []articleLanguageCount
+	if err := config.DB.Model(&models.ArticleTranslation{}).
+		Select("article_translations.language_code, articles.status, COUNT(*) AS count").
+		Joins("JOIN articles ON articles.id = article_translations.article_id AND articles.deleted_at IS NULL").
+		Group("article_translations.language_code, articles.status").
+		Order("article_translations.language_code, articles.status").
+		Scan(&byLanguage).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	// 各語言的翻譯覆蓋率
+	var coverage []translationCoverage
+	if err := config.DB.Model(&models.Language{}).
+		Select("languages.code AS language_code, languages.name, COUNT(articles.id) AS translated").
+		Joins("LEFT JOIN article_translations ON article_translations.language_code = languages.code AND article_translations.deleted_at IS NULL").
+		Joins("LEFT JOIN articles ON articles.id = article_translations.article_id AND articles.deleted_at IS NULL").
+		Group("languages.code, languages.name, languages.sort_order").
+		Order("languages.sort_order").
+		Scan(&coverage).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+	for i := range coverage {
+		coverage[i].Total = totalArticles
+		if totalArticles > 0 {
+			coverage[i].Coverage = float64(coverage[i].Translated) / float64(totalArticles)
+		}
+	}
+
+	// 瀏覽數最高的文章
+	var topArticles []topArticle
+	if err := config.DB.Model(&models.Article{}).
+		Select("articles.id, article_translations.title, article_translations.slug, articles.status, articles.view_count").
+		Joins("LEFT JOIN article_translations ON article_translations.article_id = articles.id AND article_translations.language_code = ? AND article_translations.deleted_at IS NULL", langCode).
+		Order("articles.view_count DESC").Limit(10).
+		Scan(&topArticles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	// 最近上傳的圖片
+	var recentUploads []models.Image
+	if err := config.DB.Preload("User").Order("created_at desc").Limit(10).Find(&recentUploads).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	// 儲存空間使用量
+	var storage []storageUsage
+	if err := config.DB.Model(&models.Image{}).
+		Select("content_type, COUNT(*) AS files, COALESCE(SUM(file_size), 0) AS bytes").
+		Group("content_type").Order("bytes DESC").
+		Scan(&storage).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	var storageFiles, storageBytes int64
+	for _, s := range storage {
+		storageFiles += s.Files
+		storageBytes += s.Bytes
+	}
+
+	// 使用者註冊趨勢
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+	var registrations []registrationCount
+	if err := config.DB.Model(&models.User{}).
+		Select("TO_CHAR(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, COUNT(*) AS count").
+		Where("created_at >= ?", since).
+		Group("day").Order("day").
+		Scan(&registrations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	var totalUsers int64
+	if err := config.DB.Model(&models.User{}).Count(&totalUsers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"articles": gin.H{
+			"total":       totalArticles,
+			"by_status":   byStatus,
+			"by_language": byLanguage,
+		},
+		"translation_coverage": coverage,
+		"top_articles":         topArticles,
+		"recent_uploads":       recentUploads,
+		"storage": gin.H{
+			"files":           storageFiles,
+			"bytes":           storageBytes,
+			"by_content_type": storage,
+		},
+		"users": gin.H{
+			"total":         totalUsers,
+			"since":         since.Format("2006-01-02"),
+			"registrations": registrations,
+		},
+	})
+}
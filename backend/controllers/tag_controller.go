@@ -2,12 +2,12 @@ package controllers
 
 import (
 	"GolangBlog/config"
+	"GolangBlog/locales"
 	"GolangBlog/models"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gosimple/slug"
 	"gorm.io/gorm"
 )
 
@@ -18,9 +18,10 @@ type TagRequest struct {
 
 // 標籤翻譯請求結構
 type TagTranslationRequest struct {
-	LanguageCode string `json:"language_code" binding:"required"`
-	Name         string `json:"name" binding:"required"`
-	Slug         string `json:"slug"`
+	LanguageCode   string `json:"language_code" binding:"required"`
+	Name           string `json:"name" binding:"required"`
+	Slug           string `json:"slug"`
+	SourceRevision *int   `json:"source_revision"` // 翻譯依據的來源語言版本，未指定時視為目前版本
 }
 
 // 獲取標籤列表
@@ -31,15 +32,15 @@ func GetTags(c *gin.Context) {
 	query := config.DB.Model(&models.Tag{})
 
 	// 語言篩選
-	langCode := c.Query("lang")
+	langCode := explicitLanguage(c)
+	var chain []string
 
-	// 預載翻譯
+	// 預載翻譯（指定語言時依退回順序）
 	if langCode != "" {
-		query = query.Preload("Translations", func(db *gorm.DB) *gorm.DB {
-			return db.Where("language_code = ?", langCode)
-		})
+		chain = languageChain(c, langCode)
+		query = query.Preload("Translations", translationScope(c, chain))
 	} else {
-		query = query.Preload("Translations")
+		query = query.Preload("Translations", translationScope(c, nil))
 	}
 
 	// 排序
@@ -57,6 +58,10 @@ func GetTags(c *gin.Context) {
 
 	query.Limit(pageSize).Offset(offset).Find(&tags)
 
+	if chain != nil {
+		localizeTags(tags, chain)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"tags": tags,
 		"pagination": gin.H{
@@ -73,23 +78,69 @@ func GetTag(c *gin.Context) {
 	id := c.Param("id")
 	var tag models.Tag
 
-	if err := config.DB.Preload("Translations").First(&tag, id).Error; err != nil {
+	if err := config.DB.Preload("Translations", translationScope(c, nil)).First(&tag, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "標籤不存在"})
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrTagNotFound))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		}
 		return
 	}
 
+	setETag(c, "tag", tag.ID, tag.Version)
 	c.JSON(http.StatusOK, gin.H{"tag": tag})
 }
 
+// 依 slug 獲取標籤（依語言退回順序；舊 slug 轉址到目前的 slug）
+func GetTagBySlug(c *gin.Context) {
+	tagSlug := c.Param("slug")
+	langCode, chain := requestLanguage(c)
+
+	var matches []models.TagTranslation
+	if err := config.DB.Where("slug = ? AND language_code IN ?", tagSlug, chain).Scopes(reviewedScope(c)).Find(&matches).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	codes := make([]string, len(matches))
+	for i, match := range matches {
+		codes[i] = match.LanguageCode
+	}
+	idx := pickLanguage(chain, codes)
+	if idx < 0 {
+		if redirectOldSlug(c, "tag", tagSlug, chain) {
+			return
+		}
+		c.JSON(http.StatusNotFound, errorBody(c, locales.ErrTagNotFound))
+		return
+	}
+
+	var tag models.Tag
+	if err := config.DB.Preload("Translations", translationScope(c, chain)).First(&tag, matches[idx].TagID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrTagNotFound))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		}
+		return
+	}
+
+	tags := []models.Tag{tag}
+	localizeTags(tags, chain)
+
+	setETag(c, "tag", tag.ID, tag.Version)
+	c.JSON(http.StatusOK, gin.H{
+		"tag":                tags[0],
+		"requested_language": langCode,
+		"served_language":    tags[0].ServedLanguage,
+	})
+}
+
 // 創建標籤
 func CreateTag(c *gin.Context) {
 	var req TagRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
 		return
 	}
 
@@ -100,16 +151,28 @@ func CreateTag(c *gin.Context) {
 	tag := models.Tag{}
 	if err := tx.Create(&tag).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "創建標籤失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrTagCreateFailed))
 		return
 	}
 
 	// 處理翻譯
+	tracker, err := newRevisionTracker(tx, &models.TagTranslation{}, "tag_id", tag.ID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrTranslationLoadFailed))
+		return
+	}
+	tracker.sortSourceFirst(req.Translations, func(i int) string { return req.Translations[i].LanguageCode })
+
 	for _, trans := range req.Translations {
-		// 如果沒有提供 slug，則自動生成
-		if trans.Slug == "" {
-			trans.Slug = slug.Make(trans.Name)
+		// 決定 slug：未提供時自動生成並避免重複，提供的 slug 衝突時回傳 409
+		slugValue, conflict, err := resolveSlug(tx, translationTargets["tag"], tag.ID, trans.LanguageCode, trans.Slug, trans.Name)
+		if err != nil || conflict != nil {
+			tx.Rollback()
+			respondSlugError(c, trans.LanguageCode, conflict, err)
+			return
 		}
+		trans.Slug = slugValue
 
 		translation := models.TagTranslation{
 			TagID:        tag.ID,
@@ -117,10 +180,11 @@ func CreateTag(c *gin.Context) {
 			Name:         trans.Name,
 			Slug:         trans.Slug,
 		}
+		tracker.apply(trans.LanguageCode, true, trans.SourceRevision, &translation.TranslationStatus)
 
 		if err := tx.Create(&translation).Error; err != nil {
 			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "創建標籤翻譯失敗"})
+			c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrTagTranslationCreateFailed))
 			return
 		}
 	}
@@ -128,10 +192,14 @@ func CreateTag(c *gin.Context) {
 	// 提交事務
 	tx.Commit()
 
+	// 翻譯內容已變更，清除翻譯記憶快取
+	translationMemory.Invalidate()
+
 	// 獲取完整的標籤數據回傳
 	var fullTag models.Tag
 	config.DB.Preload("Translations").First(&fullTag, tag.ID)
 
+	setETag(c, "tag", fullTag.ID, fullTag.Version)
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "標籤創建成功",
 		"tag":     fullTag,
@@ -146,28 +214,52 @@ func UpdateTag(c *gin.Context) {
 	// 確認標籤存在
 	if err := config.DB.First(&tag, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "標籤不存在"})
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrTagNotFound))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		}
 		return
 	}
 
+	// 確認請求者編輯的是最新版本
+	if !checkIfMatch(c, "tag", tag.ID, tag.Version) {
+		return
+	}
+
 	var req TagRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
 		return
 	}
 
 	// 啟動事務
 	tx := config.DB.Begin()
 
+	// 遞增版本號（檢查後若已被其他請求修改則回傳 412）
+	if ok, err := bumpVersion(tx, &models.Tag{}, tag.ID, tag.Version); !ok {
+		tx.Rollback()
+		respondBumpFailure(c, "tag", &models.Tag{}, tag.ID, err)
+		return
+	}
+
 	// 處理翻譯
+	tracker, err := newRevisionTracker(tx, &models.TagTranslation{}, "tag_id", tag.ID)
+	if err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrTranslationLoadFailed))
+		return
+	}
+	tracker.sortSourceFirst(req.Translations, func(i int) string { return req.Translations[i].LanguageCode })
+
 	for _, trans := range req.Translations {
-		// 如果沒有提供 slug，則自動生成
-		if trans.Slug == "" {
-			trans.Slug = slug.Make(trans.Name)
+		// 決定 slug：未提供時自動生成並避免重複，提供的 slug 衝突時回傳 409
+		slugValue, conflict, err := resolveSlug(tx, translationTargets["tag"], tag.ID, trans.LanguageCode, trans.Slug, trans.Name)
+		if err != nil || conflict != nil {
+			tx.Rollback()
+			respondSlugError(c, trans.LanguageCode, conflict, err)
+			return
 		}
+		trans.Slug = slugValue
 
 		var translation models.TagTranslation
 		// 檢查該語言的翻譯是否已存在
@@ -182,37 +274,57 @@ func UpdateTag(c *gin.Context) {
 					Name:         trans.Name,
 					Slug:         trans.Slug,
 				}
+				tracker.apply(trans.LanguageCode, true, trans.SourceRevision, &translation.TranslationStatus)
 
 				if err := tx.Create(&translation).Error; err != nil {
 					tx.Rollback()
-					c.JSON(http.StatusInternalServerError, gin.H{"error": "創建標籤翻譯失敗"})
+					c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrTagTranslationCreateFailed))
 					return
 				}
 			} else {
 				tx.Rollback()
-				c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
+				c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, result.Error))
 				return
 			}
 		} else {
 			// 更新已存在的翻譯
+			tracker.apply(trans.LanguageCode, translation.Name != trans.Name, trans.SourceRevision, &translation.TranslationStatus)
+
 			translation.Name = trans.Name
+			// slug 變更時保留舊 slug 作為轉址
+			if err := recordSlugChange(tx, "tag", tag.ID, trans.LanguageCode, translation.Slug, trans.Slug); err != nil {
+				tx.Rollback()
+				c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrTagTranslationUpdateFailed, err))
+				return
+			}
 			translation.Slug = trans.Slug
 
 			if err := tx.Save(&translation).Error; err != nil {
 				tx.Rollback()
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "更新標籤翻譯失敗"})
+				c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrTagTranslationUpdateFailed))
 				return
 			}
 		}
 	}
 
+	// 來源語言內容變更時，標記其他語言翻譯待更新
+	if err := tracker.markStale(tx, &models.TagTranslation{}, "tag_id", tag.ID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrTranslationStatusUpdateFailed))
+		return
+	}
+
 	// 提交事務
 	tx.Commit()
 
+	// 翻譯內容已變更，清除翻譯記憶快取
+	translationMemory.Invalidate()
+
 	// 獲取完整的標籤數據回傳
 	var fullTag models.Tag
 	config.DB.Preload("Translations").First(&fullTag, tag.ID)
 
+	setETag(c, "tag", fullTag.ID, fullTag.Version)
 	c.JSON(http.StatusOK, gin.H{
 		"message": "標籤更新成功",
 		"tag":     fullTag,
@@ -227,45 +339,63 @@ func DeleteTag(c *gin.Context) {
 	// 確認標籤存在
 	if err := config.DB.First(&tag, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "標籤不存在"})
+			c.JSON(http.StatusNotFound, errorBody(c, locales.ErrTagNotFound))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		}
 		return
 	}
 
+	// 確認請求者刪除的是最新版本
+	if !checkIfMatch(c, "tag", tag.ID, tag.Version) {
+		return
+	}
+
 	// 檢查標籤是否被使用
 	var count int64
 	if err := config.DB.Model(&models.ArticleTag{}).Where("tag_id = ?", id).Count(&count).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
 		return
 	}
 
 	if count > 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "該標籤已被使用，不能刪除"})
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrTagInUse))
 		return
 	}
 
 	// 啟動事務
 	tx := config.DB.Begin()
 
+	// 遞增版本號（檢查後若已被其他請求修改則回傳 412）
+	if ok, err := bumpVersion(tx, &models.Tag{}, tag.ID, tag.Version); !ok {
+		tx.Rollback()
+		respondBumpFailure(c, "tag", &models.Tag{}, tag.ID, err)
+		return
+	}
+
 	// 刪除翻譯
 	if err := tx.Where("tag_id = ?", id).Delete(&models.TagTranslation{}).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "刪除標籤翻譯失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrTagTranslationDeleteFailed))
 		return
 	}
 
 	// 刪除標籤本身
 	if err := tx.Delete(&tag).Error; err != nil {
 		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "刪除標籤失敗"})
+		c.JSON(http.StatusInternalServerError, errorBody(c, locales.ErrTagDeleteFailed))
 		return
 	}
 
 	// 提交事務
 	tx.Commit()
 
+	// 翻譯內容已變更，清除翻譯記憶快取
+	translationMemory.Invalidate()
+
+	// 文章的標籤關聯已移除，相關文章需重新計算
+	relatedService.Clear()
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "標籤刪除成功",
 	})
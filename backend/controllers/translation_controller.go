@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"GolangBlog/config"
+	"GolangBlog/locales"
+	"GolangBlog/models"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 翻譯版本追蹤：來源語言內容變更時遞增版本，並將其他語言標記為待更新
+type revisionTracker struct {
+	sourceLang string
+	current    int
+	bumped     bool
+}
+
+// 建立翻譯版本追蹤，載入來源語言翻譯目前的版本
+func newRevisionTracker(tx *gorm.DB, model interface{}, ownerColumn string, ownerID uint) (*revisionTracker, error) {
+	// 來源語言為預設語言
+	tracker := &revisionTracker{sourceLang: languageRegistry.DefaultCode()}
+
+	var revisions []int
+	if err := tx.Model(model).
+		Where(ownerColumn+" = ? AND language_code = ?", ownerID, tracker.sourceLang).
+		Pluck("source_revision", &revisions).Error; err != nil {
+		return nil, err
+	}
+	if len(revisions) > 0 {
+		tracker.current = revisions[0]
+	}
+
+	return tracker, nil
+}
+
+// 判斷是否為來源語言
+func (t *revisionTracker) isSource(langCode string) bool {
+	return langCode == t.sourceLang
+}
+
+// 讓來源語言的翻譯排在最前面，其他語言才能記錄更新後的來源版本
+func (t *revisionTracker) sortSourceFirst(items interface{}, lang func(i int) string) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return t.isSource(lang(i)) && !t.isSource(lang(j))
+	})
+}
+
+// 更新單筆翻譯的同步狀態；changed 表示內容有變更（新建視為變更），
+// requested 為翻譯者指定依據的來源版本，未指定時視為依據目前版本
+func (t *revisionTracker) apply(langCode string, changed bool, requested *int, status *models.TranslationStatus) {
+	if t.isSource(langCode) {
+		if changed {
+			t.current++
+			t.bumped = true
+		}
+		status.SourceRevision = t.current
+		status.NeedsUpdate = false
+		return
+	}
+
+	if changed {
+		status.SourceRevision = t.current
+		if requested != nil && *requested < t.current {
+			status.SourceRevision = *requested
+		}
+		// 人工編輯過的機器翻譯視為已審閱
+		if status.ReviewStatus == "pending" {
+			status.ReviewStatus = "reviewed"
+		}
+	}
+	status.NeedsUpdate = status.SourceRevision < t.current
+}
+
+// 來源語言已更新時，將依據舊版本的其他語言翻譯標記為待更新
+func (t *revisionTracker) markStale(tx *gorm.DB, model interface{}, ownerColumn string, ownerID uint) error {
+	if !t.bumped {
+		return nil
+	}
+	return tx.Model(model).
+		Where(ownerColumn+" = ? AND language_code <> ? AND source_revision < ?", ownerID, t.sourceLang, t.current).
+		Update("needs_update", true).Error
+}
+
+// 翻譯覆蓋率報表與機器翻譯的資料來源
+type translationTarget struct {
+	ownerTable  string
+	table       string
+	ownerColumn string
+	titleColumn string
+	textColumns []string    // 需要翻譯的欄位
+	notFound    string      // 項目不存在時的錯誤代碼
+	model       interface{} // 翻譯模型（版本追蹤使用）
+}
+
+// 支援的翻譯類型
+var translationTargets = map[string]translationTarget{
+	"article": {ownerTable: "articles", table: "article_translations", ownerColumn: "article_id", titleColumn: "title",
+		textColumns: []string{"title", "excerpt", "content", "meta_title", "meta_description", "meta_keywords"}, notFound: locales.ErrArticleNotFound,
+		model: &models.ArticleTranslation{}},
+	"tag": {ownerTable: "tags", table: "tag_translations", ownerColumn: "tag_id", titleColumn: "name",
+		textColumns: []string{"name"}, notFound: locales.ErrTagNotFound, model: &models.TagTranslation{}},
+	"category": {ownerTable: "categories", table: "category_translations", ownerColumn: "category_id", titleColumn: "name",
+		textColumns: []string{"name", "description"}, notFound: locales.ErrCategoryNotFound, model: &models.CategoryTranslation{}},
+}
+
+// 缺少翻譯的項目
+type missingTranslation struct {
+	ID          uint   `json:"id"`
+	SourceTitle string `json:"source_title"`
+}
+
+// 待更新的翻譯
+type staleTranslation struct {
+	ID              uint   `json:"id"`
+	Title           string `json:"title"`
+	SourceTitle     string `json:"source_title"`
+	SourceRevision  int    `json:"source_revision"`
+	CurrentRevision int    `json:"current_revision"`
+}
+
+// 單一語言的翻譯覆蓋率
+type languageCoverage struct {
+	LanguageCode  string               `json:"language_code"`
+	Name          string               `json:"name"`
+	Total         int64                `json:"total"`
+	Translated    int64                `json:"translated"`
+	Missing       int64                `json:"missing"`
+	Stale         int64                `json:"stale"`
+	PendingReview int64                `json:"pending_review"`
+	Coverage      float64              `json:"coverage"`
+	MissingItems  []missingTranslation `json:"missing_items"`
+	StaleItems    []staleTranslation   `json:"stale_items"`
+}
+
+// 獲取翻譯覆蓋率報表（缺少與待更新的翻譯）
+func GetTranslationCoverage(c *gin.Context) {
+	targetType := c.DefaultQuery("type", "article")
+	target, ok := translationTargets[targetType]
+	if !ok {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrTranslationTypeUnsupported, locales.Params{"allowed": locales.List{"article", "tag", "category"}}))
+		return
+	}
+
+	query := config.DB.Where("is_active = ?", true).Order("sort_order asc")
+	if langCode := c.Query("lang"); langCode != "" {
+		query = query.Where("code = ?", langCode)
+	}
+
+	var languages []models.Language
+	if err := query.Find(&languages).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	sourceLang := languageRegistry.DefaultCode()
+
+	var total int64
+	if err := config.DB.Table(target.ownerTable).Where("deleted_at IS NULL").Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	// 只計入未刪除項目的翻譯
+	translations := func(langCode string) *gorm.DB {
+		return config.DB.Table(target.table+" AS t").
+			Joins("JOIN "+target.ownerTable+" AS o ON o.id = t."+target.ownerColumn+" AND o.deleted_at IS NULL").
+			Where("t.language_code = ? AND t.deleted_at IS NULL", langCode)
+	}
+
+	report := make([]languageCoverage, 0, len(languages))
+	for _, language := range languages {
+		coverage := languageCoverage{
+			LanguageCode: language.Code,
+			Name:         language.Name,
+			Total:        total,
+			MissingItems: []missingTranslation{},
+			StaleItems:   []staleTranslation{},
+		}
+
+		if err := translations(language.Code).Count(&coverage.Translated).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+			return
+		}
+		if err := translations(language.Code).Where("t.needs_update = ?", true).Count(&coverage.Stale).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+			return
+		}
+
+		if err := translations(language.Code).Where("t.review_status = ?", "pending").Count(&coverage.PendingReview).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+			return
+		}
+
+		coverage.Missing = total - coverage.Translated
+		if total > 0 {
+			coverage.Coverage = float64(coverage.Translated-coverage.Stale) / float64(total)
+		}
+
+		if err := config.DB.Table(target.ownerTable+" AS o").
+			Select("o.id, COALESCE(s."+target.titleColumn+", '') AS source_title").
+			Joins("LEFT JOIN "+target.table+" AS s ON s."+target.ownerColumn+" = o.id AND s.language_code = ? AND s.deleted_at IS NULL", sourceLang).
+			Where("o.deleted_at IS NULL AND NOT EXISTS (SELECT 1 FROM "+target.table+" AS t WHERE t."+target.ownerColumn+" = o.id AND t.language_code = ? AND t.deleted_at IS NULL)", language.Code).
+			Order("o.id").Limit(100).
+			Scan(&coverage.MissingItems).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+			return
+		}
+
+		if err := translations(language.Code).
+			Select("t."+target.ownerColumn+" AS id, t."+target.titleColumn+" AS title, COALESCE(s."+target.titleColumn+", '') AS source_title, "+
+				"t.source_revision, COALESCE(s.source_revision, 0) AS current_revision").
+			Joins("LEFT JOIN "+target.table+" AS s ON s."+target.ownerColumn+" = t."+target.ownerColumn+" AND s.language_code = ? AND s.deleted_at IS NULL", sourceLang).
+			Where("t.needs_update = ?", true).
+			Order("t." + target.ownerColumn).Limit(100).
+			Scan(&coverage.StaleItems).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+			return
+		}
+
+		report = append(report, coverage)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"type":            targetType,
+		"source_language": sourceLang,
+		"languages":       report,
+	})
+}
@@ -0,0 +1,652 @@
+package controllers
+
+import (
+	"GolangBlog/config"
+	"GolangBlog/locales"
+	"GolangBlog/models"
+	"GolangBlog/services"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 交換檔大小上限
+const maxExchangeFileSize = 20 << 20
+
+// 翻譯匯出請求結構
+type TranslationExportRequest struct {
+	Format         string `json:"format"`          // xliff（預設）或 po
+	SourceLanguage string `json:"source_language"` // 未指定時使用預設語言
+	TargetLanguage string `json:"target_language" binding:"required"`
+	ArticleIDs     []uint `json:"article_ids"`
+	TagIDs         []uint `json:"tag_ids"`
+	CategoryIDs    []uint `json:"category_ids"`
+	SettingIDs     []uint `json:"setting_ids"` // 僅限可翻譯的設定
+}
+
+// 交換檔中單一項目（文章、標籤、分類或設定）的目前內容
+type exchangeEntry struct {
+	source         map[string]string // 來源語言的欄位內容
+	target         map[string]string // 目標語言的欄位內容
+	hasSource      bool
+	targetID       uint // 目標語言翻譯的 ID（0 表示尚無翻譯）
+	targetDeleted  bool // 目標語言翻譯已被刪除
+	targetRevision int  // 目標語言翻譯依據的來源版本
+	sourceSlug     string
+}
+
+// 匯入報告中的單一翻譯單位
+type exchangeItem struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`           // created、updated、unchanged、skipped、conflict 或 invalid
+	Reason string `json:"reason,omitempty"` // 略過、衝突或無效的原因
+}
+
+// 匯入報告
+type exchangeReport struct {
+	Format         string         `json:"format"`
+	SourceLanguage string         `json:"source_language"`
+	TargetLanguage string         `json:"target_language"`
+	DryRun         bool           `json:"dry_run"`
+	Total          int            `json:"total"`
+	Created        int            `json:"created"`
+	Updated        int            `json:"updated"`
+	Unchanged      int            `json:"unchanged"`
+	Skipped        int            `json:"skipped"`
+	Conflicts      int            `json:"conflicts"`
+	Invalid        int            `json:"invalid"`
+	Items          []exchangeItem `json:"items"`
+}
+
+// 已解析的翻譯單位代碼，例如 article:12:title
+type exchangeUnitKey struct {
+	kind  string
+	id    uint
+	field string
+}
+
+// 匯出翻譯交換檔（XLIFF 2.0 或 gettext PO）
+func ExportTranslations(c *gin.Context) {
+	var req TranslationExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrInvalidRequest, err))
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		format = services.XLIFFFormat
+	}
+	if format != services.XLIFFFormat && format != services.POFormat {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrExchangeFormatUnsupported, locales.Params{"allowed": locales.List{"xliff", "po"}}))
+		return
+	}
+
+	sourceLang := req.SourceLanguage
+	if sourceLang == "" {
+		sourceLang = languageRegistry.DefaultCode()
+	}
+	if language, ok := languageRegistry.Get(req.TargetLanguage); !ok || !language.IsActive || req.TargetLanguage == sourceLang {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrLanguageNotActive))
+		return
+	}
+
+	selection := []struct {
+		kind string
+		ids  []uint
+	}{
+		{"article", uniqueIDs(req.ArticleIDs)},
+		{"tag", uniqueIDs(req.TagIDs)},
+		{"category", uniqueIDs(req.CategoryIDs)},
+		{"setting", uniqueIDs(req.SettingIDs)},
+	}
+
+	bundle := &services.TranslationBundle{SourceLanguage: sourceLang, TargetLanguage: req.TargetLanguage}
+	var missing []string
+	for _, selected := range selection {
+		if len(selected.ids) == 0 {
+			continue
+		}
+
+		entries, err := loadExchangeEntries(config.DB, selected.kind, selected.ids, sourceLang, req.TargetLanguage)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+			return
+		}
+
+		for _, id := range selected.ids {
+			entry, ok := entries[id]
+			if !ok || !entry.hasSource {
+				missing = append(missing, fmt.Sprintf("%s:%d", selected.kind, id))
+				continue
+			}
+
+			for _, field := range exchangeFields(selected.kind) {
+				source := entry.source[field]
+				if source == "" {
+					continue
+				}
+				target := entry.target[field]
+				bundle.Units = append(bundle.Units, services.TranslationUnit{
+					ID:         fmt.Sprintf("%s:%d:%s", selected.kind, id, field),
+					Note:       fmt.Sprintf("%s #%d %s", selected.kind, id, field),
+					Source:     source,
+					Target:     target,
+					SourceHash: services.ContentHash(source),
+					TargetHash: services.ContentHash(target),
+				})
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		body := errorBody(c, locales.ErrExchangeItemsMissing, locales.Params{"count": len(missing)})
+		body["items"] = missing
+		c.JSON(http.StatusBadRequest, body)
+		return
+	}
+	if len(bundle.Units) == 0 {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrExchangeSelectionEmpty))
+		return
+	}
+
+	data, err := services.EncodeTranslationBundle(format, bundle)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	contentType := "application/xliff+xml; charset=utf-8"
+	extension := "xlf"
+	if format == services.POFormat {
+		contentType = "text/x-gettext-translation; charset=utf-8"
+		extension = "po"
+	}
+
+	fileName := fmt.Sprintf("translations_%s_%s_%s.%s", sourceLang, req.TargetLanguage, time.Now().Format("20060102-150405"), extension)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// 匯入翻譯交換檔：驗證單位代碼、偵測匯出後的修改，並在同一個事務中寫入目標語言翻譯
+// 查詢參數 dry_run=true 只回傳報告不寫入；force=true 時忽略衝突直接覆寫
+func ImportTranslations(c *gin.Context) {
+	dryRun := c.Query("dry_run") == "true"
+	force := c.Query("force") == "true"
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrExchangeFileMissing))
+		return
+	}
+	if fileHeader.Size > maxExchangeFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, errorBody(c, locales.ErrExchangeFileTooLarge, locales.Params{"limit": maxExchangeFileSize >> 20}))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrExchangeFileInvalid, err))
+		return
+	}
+	defer file.Close()
+
+	// 多讀一個位元組，避免內容超過上限時被截斷後仍當成完整檔案匯入
+	data, err := io.ReadAll(io.LimitReader(file, maxExchangeFileSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrExchangeFileInvalid, err))
+		return
+	}
+	if len(data) > maxExchangeFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, errorBody(c, locales.ErrExchangeFileTooLarge, locales.Params{"limit": maxExchangeFileSize >> 20}))
+		return
+	}
+
+	bundle, format, err := services.DecodeTranslationBundle(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorDetail(c, locales.ErrExchangeFileInvalid, err))
+		return
+	}
+
+	targetLang := bundle.TargetLanguage
+	if language, ok := languageRegistry.Get(targetLang); !ok || !language.IsActive || targetLang == bundle.SourceLanguage || bundle.SourceLanguage == "" {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrLanguageNotActive))
+		return
+	}
+
+	report := exchangeReport{
+		Format:         format,
+		SourceLanguage: bundle.SourceLanguage,
+		TargetLanguage: targetLang,
+		DryRun:         dryRun,
+		Total:          len(bundle.Units),
+		Items:          make([]exchangeItem, len(bundle.Units)),
+	}
+
+	// 解析單位代碼
+	keys := make([]exchangeUnitKey, len(bundle.Units))
+	idsByKind := map[string][]uint{}
+	seen := map[string]bool{}
+	for i, unit := range bundle.Units {
+		report.Items[i] = exchangeItem{ID: unit.ID}
+
+		key, ok := parseExchangeUnitID(unit.ID)
+		switch {
+		case !ok:
+			report.Items[i].Status, report.Items[i].Reason = "invalid", "invalid_id"
+		case seen[unit.ID]:
+			report.Items[i].Status, report.Items[i].Reason = "invalid", "duplicate"
+		default:
+			keys[i] = key
+			idsByKind[key.kind] = append(idsByKind[key.kind], key.id)
+		}
+		seen[unit.ID] = true
+	}
+
+	// 載入目前內容
+	entries := map[string]map[uint]*exchangeEntry{}
+	for kind, ids := range idsByKind {
+		loaded, err := loadExchangeEntries(config.DB, kind, uniqueIDs(ids), bundle.SourceLanguage, targetLang)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+			return
+		}
+		entries[kind] = loaded
+	}
+
+	// 比對每個單位，決定寫入方式
+	changes := map[exchangeUnitKey]map[string]string{} // 以 field 為空的 key 表示項目，值為要寫入的欄位
+	stale := map[exchangeUnitKey]bool{}                // 強制匯入依據舊來源內容的譯文，寫入後仍標記為待更新
+	for i, unit := range bundle.Units {
+		item := &report.Items[i]
+		if item.Status == "invalid" {
+			continue
+		}
+
+		key := keys[i]
+		entry, ok := entries[key.kind][key.id]
+		switch {
+		case !ok:
+			item.Status, item.Reason = "invalid", "not_found"
+			continue
+		case !entry.hasSource:
+			item.Status, item.Reason = "invalid", "source_missing"
+			continue
+		}
+
+		currentTarget := entry.target[key.field]
+		switch {
+		case unit.Target == "":
+			item.Status, item.Reason = "skipped", "empty"
+			continue
+		case unit.Fuzzy:
+			item.Status, item.Reason = "skipped", "fuzzy"
+			continue
+		case unit.Target == currentTarget && entry.targetID != 0 && !entry.targetDeleted:
+			item.Status = "unchanged"
+			continue
+		case unit.SourceHash != "" && unit.SourceHash != services.ContentHash(entry.source[key.field]):
+			item.Status, item.Reason = "conflict", "source_changed"
+			if !force {
+				continue
+			}
+		case unit.TargetHash != "" && unit.TargetHash != services.ContentHash(currentTarget):
+			item.Status, item.Reason = "conflict", "target_changed"
+			if !force {
+				continue
+			}
+		}
+
+		owner := exchangeUnitKey{kind: key.kind, id: key.id}
+		if changes[owner] == nil {
+			changes[owner] = map[string]string{}
+		}
+		changes[owner][key.field] = unit.Target
+		if item.Reason == "source_changed" {
+			stale[owner] = true
+		}
+
+		if item.Status == "" {
+			item.Status = "updated"
+			if entry.targetID == 0 || entry.targetDeleted {
+				item.Status = "created"
+			}
+		}
+	}
+
+	// 新建的翻譯必須包含標題欄位
+	for i := range report.Items {
+		item := &report.Items[i]
+		if item.Status != "created" {
+			continue
+		}
+		key := keys[i]
+		titleField := exchangeFields(key.kind)[0]
+		if _, ok := changes[exchangeUnitKey{kind: key.kind, id: key.id}][titleField]; !ok {
+			item.Status, item.Reason = "invalid", "missing_"+titleField
+		}
+	}
+	for i := range report.Items {
+		key := keys[i]
+		if report.Items[i].Status == "invalid" && key.kind != "" {
+			delete(changes, exchangeUnitKey{kind: key.kind, id: key.id})
+		}
+	}
+
+	for _, item := range report.Items {
+		switch item.Status {
+		case "created":
+			report.Created++
+		case "updated":
+			report.Updated++
+		case "unchanged":
+			report.Unchanged++
+		case "skipped":
+			report.Skipped++
+		case "conflict":
+			report.Conflicts++
+		case "invalid":
+			report.Invalid++
+		}
+	}
+
+	if report.Invalid > 0 {
+		body := errorBody(c, locales.ErrExchangeUnitsInvalid, locales.Params{"count": report.Invalid})
+		body["report"] = report
+		c.JSON(http.StatusUnprocessableEntity, body)
+		return
+	}
+	if report.Conflicts > 0 && !force {
+		body := errorBody(c, locales.ErrExchangeConflicts, locales.Params{"count": report.Conflicts})
+		body["report"] = report
+		c.JSON(http.StatusConflict, body)
+		return
+	}
+	if dryRun {
+		c.JSON(http.StatusOK, gin.H{"report": report})
+		return
+	}
+
+	// 開始事務
+	tx := config.DB.Begin()
+
+	for owner, fields := range changes {
+		entry := entries[owner.kind][owner.id]
+		var err error
+		if owner.kind == "setting" {
+			err = saveSettingTranslation(tx, owner.id, targetLang, fields["value"])
+		} else {
+			err = saveExchangeTranslation(tx, translationTargets[owner.kind], owner.id, targetLang, entry, fields, stale[owner])
+			if err == nil {
+				// 翻譯已變更，遞增文章、標籤或分類的版本號
+				err = touchVersions(tx, translationTargets[owner.kind].ownerTable, owner.id)
+			}
+		}
+		if err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrExchangeImportFailed, err))
+			return
+		}
+	}
+
+	// 提交事務
+	tx.Commit()
+
+	// 翻譯內容已變更，清除翻譯記憶快取
+	translationMemory.Invalidate()
+
+	for owner := range changes {
+		if owner.kind == "article" {
+			relatedService.Invalidate(owner.id)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "翻譯匯入成功",
+		"report":  report,
+	})
+}
+
+// 各類型可交換的欄位（第一個為標題欄位）
+func exchangeFields(kind string) []string {
+	if kind == "setting" {
+		return []string{"value"}
+	}
+	return translationTargets[kind].textColumns
+}
+
+// 解析翻譯單位代碼
+func parseExchangeUnitID(unitID string) (exchangeUnitKey, bool) {
+	parts := strings.Split(unitID, ":")
+	if len(parts) != 3 {
+		return exchangeUnitKey{}, false
+	}
+
+	id, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || id == 0 {
+		return exchangeUnitKey{}, false
+	}
+
+	key := exchangeUnitKey{kind: parts[0], id: uint(id), field: parts[2]}
+	for _, field := range exchangeFields(key.kind) {
+		if field == key.field {
+			return key, true
+		}
+	}
+	return exchangeUnitKey{}, false
+}
+
+// 載入項目在來源與目標語言的目前內容
+func loadExchangeEntries(db *gorm.DB, kind string, ids []uint, sourceLang, targetLang string) (map[uint]*exchangeEntry, error) {
+	if kind == "setting" {
+		return loadSettingEntries(db, ids, sourceLang, targetLang)
+	}
+
+	target := translationTargets[kind]
+	entries := map[uint]*exchangeEntry{}
+
+	var ownerIDs []uint
+	if err := db.Table(target.ownerTable).Where("id IN ? AND deleted_at IS NULL", ids).Pluck("id", &ownerIDs).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ownerIDs {
+		entries[id] = &exchangeEntry{source: map[string]string{}, target: map[string]string{}}
+	}
+
+	columns := append([]string{target.ownerColumn + " AS owner_id", "id", "slug", "source_revision", "deleted_at"}, target.textColumns...)
+
+	var sourceRows []map[string]interface{}
+	if err := db.Table(target.table).Select(columns).
+		Where(target.ownerColumn+" IN ? AND language_code = ? AND deleted_at IS NULL", ownerIDs, sourceLang).
+		Find(&sourceRows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range sourceRows {
+		entry, ok := entries[columnUint(row["owner_id"])]
+		if !ok {
+			continue
+		}
+		entry.hasSource = true
+		entry.sourceSlug = columnString(row["slug"])
+		for _, column := range target.textColumns {
+			entry.source[column] = columnString(row[column])
+		}
+	}
+
+	// 目標語言包含已刪除的翻譯，以免違反唯一索引
+	var targetRows []map[string]interface{}
+	if err := db.Table(target.table).Select(columns).
+		Where(target.ownerColumn+" IN ? AND language_code = ?", ownerIDs, targetLang).
+		Find(&targetRows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range targetRows {
+		entry, ok := entries[columnUint(row["owner_id"])]
+		if !ok {
+			continue
+		}
+		entry.targetID = columnUint(row["id"])
+		entry.targetDeleted = row["deleted_at"] != nil
+		if entry.targetDeleted {
+			continue
+		}
+		entry.targetRevision = int(columnUint(row["source_revision"]))
+		for _, column := range target.textColumns {
+			entry.target[column] = columnString(row[column])
+		}
+	}
+
+	return entries, nil
+}
+
+// 載入可翻譯設定在來源與目標語言的目前內容（來源語言沒有翻譯時使用設定值）
+func loadSettingEntries(db *gorm.DB, ids []uint, sourceLang, targetLang string) (map[uint]*exchangeEntry, error) {
+	entries := map[uint]*exchangeEntry{}
+
+	var settings []models.Setting
+	if err := db.Where("id IN ? AND is_translatable = ?", ids, true).Find(&settings).Error; err != nil {
+		return nil, err
+	}
+	for _, setting := range settings {
+		entries[setting.ID] = &exchangeEntry{
+			source:    map[string]string{"value": setting.Value},
+			target:    map[string]string{},
+			hasSource: true,
+		}
+	}
+
+	var translations []models.SettingTranslation
+	if err := db.Unscoped().Where("setting_id IN ? AND language_code IN ?", ids, []string{sourceLang, targetLang}).
+		Find(&translations).Error; err != nil {
+		return nil, err
+	}
+	for _, translation := range translations {
+		entry, ok := entries[translation.SettingID]
+		if !ok {
+			continue
+		}
+		deleted := translation.DeletedAt.Valid
+		if translation.LanguageCode == sourceLang {
+			if !deleted {
+				entry.source["value"] = translation.Value
+			}
+			continue
+		}
+		entry.targetID = translation.ID
+		entry.targetDeleted = deleted
+		if !deleted {
+			entry.target["value"] = translation.Value
+		}
+	}
+
+	return entries, nil
+}
+
+// 寫入文章、標籤或分類的目標語言翻譯（新建時依標題產生 slug）；同步狀態由版本追蹤決定：
+// 匯入預設語言時遞增來源版本並將其他語言標記為待更新，stale 表示譯文依據的是匯出後已變更的來源內容
+func saveExchangeTranslation(tx *gorm.DB, target translationTarget, ownerID uint, langCode string, entry *exchangeEntry, fields map[string]string, stale bool) error {
+	tracker, err := newRevisionTracker(tx, target.model, target.ownerColumn, ownerID)
+	if err != nil {
+		return err
+	}
+
+	// 依據舊來源內容的譯文保留原本依據的版本
+	var requested *int
+	if stale {
+		requested = &entry.targetRevision
+	}
+	var status models.TranslationStatus
+	tracker.apply(langCode, true, requested, &status)
+	if stale && !tracker.isSource(langCode) {
+		status.NeedsUpdate = true
+	}
+
+	now := time.Now()
+	values := map[string]interface{}{
+		"source_revision":    status.SourceRevision,
+		"needs_update":       status.NeedsUpdate,
+		"machine_translated": false,
+		"translation_engine": "",
+		"review_status":      "",
+		"updated_at":         now,
+	}
+	for field, value := range fields {
+		values[field] = value
+	}
+
+	if entry.targetID != 0 {
+		if entry.targetDeleted {
+			values["deleted_at"] = nil
+		}
+		if err := tx.Table(target.table).Where("id = ?", entry.targetID).Updates(values).Error; err != nil {
+			return err
+		}
+	} else {
+		slugValue, err := uniqueTranslationSlug(tx, target, ownerID, langCode, fields[target.textColumns[0]], entry.sourceSlug)
+		if err != nil {
+			return err
+		}
+		values["slug"] = slugValue
+		values[target.ownerColumn] = ownerID
+		values["language_code"] = langCode
+		values["created_at"] = now
+		if err := tx.Table(target.table).Create(values).Error; err != nil {
+			return err
+		}
+	}
+
+	return tracker.markStale(tx, target.model, target.ownerColumn, ownerID)
+}
+
+// 寫入設定的目標語言翻譯
+func saveSettingTranslation(tx *gorm.DB, settingID uint, langCode, value string) error {
+	var translation models.SettingTranslation
+	result := tx.Unscoped().Where("setting_id = ? AND language_code = ?", settingID, langCode).Limit(1).Find(&translation)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return tx.Create(&models.SettingTranslation{SettingID: settingID, LanguageCode: langCode, Value: value}).Error
+	}
+
+	translation.Value = value
+	translation.DeletedAt = gorm.DeletedAt{}
+	return tx.Unscoped().Save(&translation).Error
+}
+
+// 去除重複的 ID 並保留原順序
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// 將資料庫欄位值轉為無號整數
+func columnUint(value interface{}) uint {
+	switch v := value.(type) {
+	case int64:
+		return uint(v)
+	case int32:
+		return uint(v)
+	case int:
+		return uint(v)
+	case uint64:
+		return uint(v)
+	case uint:
+		return v
+	}
+	parsed, _ := strconv.ParseUint(columnString(value), 10, 64)
+	return uint(parsed)
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"GolangBlog/config"
+	"GolangBlog/locales"
+	"GolangBlog/models"
+	"GolangBlog/services"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 熱門文章排行服務
+var trendingService *services.TrendingService
+
+// 初始化熱門文章排行服務（需在資料庫連接後呼叫）
+func InitTrendingService(db *gorm.DB) {
+	cacheTTL, err := time.ParseDuration(os.Getenv("TRENDING_CACHE_TTL"))
+	if err != nil {
+		cacheTTL = 5 * time.Minute
+	}
+
+	trendingService = services.NewTrendingService(services.TrendingConfig{
+		CacheTTL: cacheTTL,
+	}, db)
+}
+
+// 獲取熱門文章排行
+func GetTrendingArticles(c *gin.Context) {
+	window := c.DefaultQuery("window", "24h")
+	if _, ok := services.TrendingWindows[window]; !ok {
+		c.JSON(http.StatusBadRequest, errorBody(c, locales.ErrTrendingWindowUnsupported, locales.Params{"allowed": locales.List{"24h", "7d", "30d"}}))
+		return
+	}
+
+	_, chain := requestLanguage(c)
+	sortBy := c.DefaultQuery("sort", services.TrendingSortScore)
+
+	limit := 10 // 默認返回10篇熱門文章
+	if parsedLimit, err := strconv.Atoi(c.Query("limit")); err == nil && parsedLimit > 0 {
+		limit = min(parsedLimit, 50)
+	}
+
+	ranking, err := trendingService.Rank(window, chain, sortBy, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	ids := make([]uint, 0, len(ranking))
+	for _, r := range ranking {
+		ids = append(ids, r.ArticleID)
+	}
+
+	var found []models.Article
+	if len(ids) > 0 {
+		query := config.DB.Where("id IN ? AND status = ?", ids, "published")
+		query = query.Preload("Translations", translationScope(c, chain)).
+			Preload("Tags.Translations", translationScope(c, chain)).
+			Preload("User")
+
+		if err := query.Find(&found).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+			return
+		}
+	}
+
+	// 依排行順序排列
+	byID := make(map[uint]models.Article, len(found))
+	for _, a := range found {
+		byID[a.ID] = a
+	}
+	articles := make([]models.Article, 0, len(found))
+	stats := make([]services.TrendingArticle, 0, len(found))
+	for _, r := range ranking {
+		if a, ok := byID[r.ArticleID]; ok {
+			articles = append(articles, a)
+			stats = append(stats, r)
+		}
+	}
+
+	// 依退回順序挑選翻譯並填入反應統計
+	localizeArticles(articles, chain)
+	attachReactionCounts(articles)
+
+	c.JSON(http.StatusOK, gin.H{
+		"window":   window,
+		"articles": articles,
+		"stats":    stats,
+	})
+}
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"GolangBlog/config"
+	"GolangBlog/locales"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 資源的 ETag：由類型、ID 與版本號組成，例如 "article-12-v3"
+func resourceETag(kind string, id, version uint) string {
+	return fmt.Sprintf(`"%s-%d-v%d"`, kind, id, version)
+}
+
+// 在回應中加入資源的 ETag
+func setETag(c *gin.Context, kind string, id, version uint) {
+	c.Header("ETag", resourceETag(kind, id, version))
+}
+
+// 檢查 If-Match 標頭：未提供時回傳 428，與目前版本不符時回傳 412 並附上目前版本；
+// 允許 "*" 與弱比對（部分代理伺服器會將 ETag 轉為弱 ETag）
+func checkIfMatch(c *gin.Context, kind string, id, version uint) bool {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		c.JSON(http.StatusPreconditionRequired, errorBody(c, locales.ErrPreconditionRequired))
+		return false
+	}
+
+	current := resourceETag(kind, id, version)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == current {
+			return true
+		}
+	}
+
+	respondVersionConflict(c, kind, id, version)
+	return false
+}
+
+// 回傳 412：資源已被其他人修改，附上目前的版本號與 ETag
+func respondVersionConflict(c *gin.Context, kind string, id, version uint) {
+	setETag(c, kind, id, version)
+	body := errorBody(c, locales.ErrPreconditionFailed, locales.Params{"version": version})
+	body["current_version"] = version
+	body["etag"] = resourceETag(kind, id, version)
+	c.JSON(http.StatusPreconditionFailed, body)
+}
+
+// 在事務中遞增版本號，只在版本號仍為 version 時更新；
+// 回傳 false 表示檢查 If-Match 之後資源已被其他請求修改
+func bumpVersion(tx *gorm.DB, model interface{}, id, version uint) (bool, error) {
+	result := tx.Model(model).Where("id = ? AND version = ?", id, version).
+		UpdateColumn("version", gorm.Expr("version + 1"))
+	return result.RowsAffected > 0, result.Error
+}
+
+// 遞增版本號失敗時回應：查詢錯誤回傳 500，已被其他請求修改時回傳 412 與最新版本
+func respondBumpFailure(c *gin.Context, kind string, model interface{}, id uint, err error) {
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorDetail(c, locales.ErrInternal, err))
+		return
+	}
+
+	var versions []uint
+	config.DB.Unscoped().Model(model).Where("id = ?", id).Pluck("version", &versions)
+	var version uint
+	if len(versions) > 0 {
+		version = versions[0]
+	}
+	respondVersionConflict(c, kind, id, version)
+}
+
+// 在事務中遞增多個資源的版本號（翻譯或關聯由其他操作修改時使用）
+func touchVersions(tx *gorm.DB, table string, ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return tx.Table(table).Where("id IN ?", ids).UpdateColumn("version", gorm.Expr("version + 1")).Error
+}
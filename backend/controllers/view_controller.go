@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"GolangBlog/services"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 瀏覽計數服務
+var viewCounter *services.ViewCounter
+
+// 初始化瀏覽計數服務並啟動背景寫入（需在資料庫連接後呼叫）
+func InitViewCounter(db *gorm.DB) {
+	flushInterval, err := time.ParseDuration(os.Getenv("VIEW_FLUSH_INTERVAL"))
+	if err != nil {
+		flushInterval = 10 * time.Second
+	}
+
+	dedupeWindow, err := time.ParseDuration(os.Getenv("VIEW_DEDUPE_WINDOW"))
+	if err != nil {
+		dedupeWindow = 30 * time.Minute
+	}
+
+	viewCounter = services.NewViewCounter(services.ViewCounterConfig{
+		FlushInterval: flushInterval,
+		DedupeWindow:  dedupeWindow,
+	}, db)
+	viewCounter.Start()
+}
+
+// 停止瀏覽計數服務，寫入尚未儲存的瀏覽數（伺服器關閉時呼叫）
+func StopViewCounter() {
+	if viewCounter != nil {
+		viewCounter.Stop()
+	}
+}
+
+// 記錄文章瀏覽（非同步，預取請求不計入）
+func recordArticleView(c *gin.Context, articleID uint) {
+	if c.GetHeader("Sec-Purpose") != "" || c.GetHeader("Purpose") == "prefetch" {
+		return
+	}
+
+	userAgent := c.Request.UserAgent()
+	viewCounter.Record(articleID, services.VisitorKey(c.ClientIP(), userAgent), userAgent)
+}
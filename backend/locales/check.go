@@ -0,0 +1,69 @@
+package locales
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// 訊息中的 {name} 佔位符
+var placeholderPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// 檢查所有錯誤代碼在每個內嵌語系中皆有訊息，且佔位符與預設語系一致
+func Check() []error {
+	catalogs := bundles()
+	if loadErr != nil {
+		return []error{loadErr}
+	}
+	if _, ok := catalogs[DefaultLocale]; !ok {
+		return []error{fmt.Errorf("缺少預設語系 %s 的訊息檔", DefaultLocale)}
+	}
+
+	var errs []error
+	for _, code := range Codes() {
+		expected := placeholders(catalogs[DefaultLocale][code])
+
+		for _, locale := range Locales() {
+			msg, ok := catalogs[locale][code]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: 缺少錯誤代碼 %s 的訊息", locale, code))
+				continue
+			}
+			if msg.plural != nil {
+				if _, ok := msg.plural["other"]; !ok {
+					errs = append(errs, fmt.Errorf("%s: 錯誤代碼 %s 的複數訊息缺少 other", locale, code))
+				}
+			} else if msg.text == "" {
+				errs = append(errs, fmt.Errorf("%s: 錯誤代碼 %s 的訊息為空", locale, code))
+			}
+
+			if actual := placeholders(msg); actual != expected {
+				errs = append(errs, fmt.Errorf("%s: 錯誤代碼 %s 的佔位符 [%s] 與預設語系 [%s] 不一致", locale, code, actual, expected))
+			}
+		}
+	}
+
+	return errs
+}
+
+// 取得訊息使用的佔位符（排序後以逗號連接）
+func placeholders(msg message) string {
+	texts := []string{msg.text}
+	for _, text := range msg.plural {
+		texts = append(texts, text)
+	}
+
+	seen := map[string]bool{}
+	var names []string
+	for _, text := range texts {
+		for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				names = append(names, match[1])
+			}
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
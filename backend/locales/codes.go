@@ -0,0 +1,223 @@
+package locales
+
+// 已註冊的錯誤代碼（依註冊順序）
+var codes []string
+
+// 註冊錯誤代碼，供語系檢查確認每個語系皆有對應訊息
+func register(code string) string {
+	codes = append(codes, code)
+	return code
+}
+
+// 取得所有已註冊的錯誤代碼
+func Codes() []string {
+	return append([]string(nil), codes...)
+}
+
+// 錯誤代碼
+var (
+	// 通用
+	ErrInvalidRequest              = register("invalid_request")
+	ErrInternal                    = register("internal_error")
+	ErrRouteNotFound               = register("route_not_found")
+	ErrUnauthenticated             = register("unauthenticated")
+	ErrPatchContentTypeUnsupported = register("patch_content_type_unsupported")
+	ErrPreconditionRequired        = register("precondition_required")
+	ErrPreconditionFailed          = register("precondition_failed")
+
+	// 身份驗證
+	ErrAuthHeaderMissing     = register("auth_header_missing")
+	ErrAuthHeaderInvalid     = register("auth_header_invalid")
+	ErrTokenSignatureInvalid = register("token_signature_invalid")
+	ErrTokenExpired          = register("token_expired")
+	ErrTokenInvalid          = register("token_invalid")
+	ErrAdminRequired         = register("admin_required")
+	ErrEditorRequired        = register("editor_required")
+	ErrUserExists            = register("user_exists")
+	ErrRegistrationSpam      = register("registration_spam")
+	ErrPasswordHashFailed    = register("password_hash_failed")
+	ErrUserCreateFailed      = register("user_create_failed")
+	ErrInvalidCredentials    = register("invalid_credentials")
+	ErrUserDisabled          = register("user_disabled")
+	ErrTokenGenerateFailed   = register("token_generate_failed")
+	ErrUserNotFound          = register("user_not_found")
+
+	// 文章
+	ErrArticleNotFound                = register("article_not_found")
+	ErrArticleNotPublished            = register("article_not_published")
+	ErrArticleCreateFailed            = register("article_create_failed")
+	ErrArticleUpdateFailed            = register("article_update_failed")
+	ErrArticleDeleteFailed            = register("article_delete_failed")
+	ErrArticleTranslationCreateFailed = register("article_translation_create_failed")
+	ErrArticleTranslationUpdateFailed = register("article_translation_update_failed")
+	ErrArticleTranslationDeleteFailed = register("article_translation_delete_failed")
+	ErrArticleTagsLinkFailed          = register("article_tags_link_failed")
+	ErrArticleCategoriesLinkFailed    = register("article_categories_link_failed")
+	ErrArticleTagsUnlinkFailed        = register("article_tags_unlink_failed")
+	ErrArticleCategoriesUnlinkFailed  = register("article_categories_unlink_failed")
+	ErrArticleBulkTargetMissing       = register("article_bulk_target_missing")
+	ErrArticleBulkOperationMissing    = register("article_bulk_operation_missing")
+	ErrArticleBulkDeleteExclusive     = register("article_bulk_delete_exclusive")
+	ErrArticleBulkTooMany             = register("article_bulk_too_many")
+	ErrArticleBulkTagsMissing         = register("article_bulk_tags_missing")
+	ErrArticleBulkCategoriesMissing   = register("article_bulk_categories_missing")
+	ErrArticleBulkFailed              = register("article_bulk_failed")
+	ErrArticleSourceTranslationDelete = register("article_source_translation_delete")
+
+	// 翻譯
+	ErrTranslationTypeUnsupported    = register("translation_type_unsupported")
+	ErrTranslationLoadFailed         = register("translation_load_failed")
+	ErrTranslationStatusUpdateFailed = register("translation_status_update_failed")
+	ErrTranslationSourceMissing      = register("translation_source_missing")
+	ErrTranslationNotPending         = register("translation_not_pending")
+	ErrMachineTranslationDisabled    = register("machine_translation_disabled")
+	ErrMachineTranslationFailed      = register("machine_translation_failed")
+	ErrExchangeFormatUnsupported     = register("exchange_format_unsupported")
+	ErrExchangeSelectionEmpty        = register("exchange_selection_empty")
+	ErrExchangeItemsMissing          = register("exchange_items_missing")
+	ErrExchangeFileMissing           = register("exchange_file_missing")
+	ErrExchangeFileInvalid           = register("exchange_file_invalid")
+	ErrExchangeFileTooLarge          = register("exchange_file_too_large")
+	ErrExchangeUnitsInvalid          = register("exchange_units_invalid")
+	ErrExchangeConflicts             = register("exchange_conflicts")
+	ErrExchangeImportFailed          = register("exchange_import_failed")
+	ErrGlossaryTermNotFound          = register("glossary_term_not_found")
+	ErrGlossaryTermExists            = register("glossary_term_exists")
+	ErrGlossaryLanguagesInvalid      = register("glossary_languages_invalid")
+	ErrTranslationMemoryFailed       = register("translation_memory_failed")
+
+	// Slug
+	ErrSlugTaken              = register("slug_taken")
+	ErrSlugReserved           = register("slug_reserved")
+	ErrSlugInvalid            = register("slug_invalid")
+	ErrRedirectNotFound       = register("redirect_not_found")
+	ErrRedirectExists         = register("redirect_exists")
+	ErrRedirectSlugInUse      = register("redirect_slug_in_use")
+	ErrRedirectTargetNotFound = register("redirect_target_not_found")
+
+	// 文章系列
+	ErrSeriesNotFound                = register("series_not_found")
+	ErrSeriesNotPublished            = register("series_not_published")
+	ErrSeriesCreateFailed            = register("series_create_failed")
+	ErrSeriesUpdateFailed            = register("series_update_failed")
+	ErrSeriesDeleteFailed            = register("series_delete_failed")
+	ErrSeriesTranslationCreateFailed = register("series_translation_create_failed")
+	ErrSeriesTranslationUpdateFailed = register("series_translation_update_failed")
+	ErrSeriesTranslationDeleteFailed = register("series_translation_delete_failed")
+	ErrSeriesArticlesLinkFailed      = register("series_articles_link_failed")
+	ErrSeriesArticlesUnlinkFailed    = register("series_articles_unlink_failed")
+	ErrSeriesDuplicateArticle        = register("series_duplicate_article")
+	ErrSeriesArticlesMissing         = register("series_articles_missing")
+
+	// 標籤
+	ErrTagNotFound                = register("tag_not_found")
+	ErrTagCreateFailed            = register("tag_create_failed")
+	ErrTagDeleteFailed            = register("tag_delete_failed")
+	ErrTagInUse                   = register("tag_in_use")
+	ErrTagTranslationCreateFailed = register("tag_translation_create_failed")
+	ErrTagTranslationUpdateFailed = register("tag_translation_update_failed")
+	ErrTagTranslationDeleteFailed = register("tag_translation_delete_failed")
+
+	// 分類
+	ErrCategoryNotFound                = register("category_not_found")
+	ErrParentCategoryNotFound          = register("parent_category_not_found")
+	ErrCategoryCreateFailed            = register("category_create_failed")
+	ErrCategoryUpdateFailed            = register("category_update_failed")
+	ErrCategoryDeleteFailed            = register("category_delete_failed")
+	ErrCategoryTranslationCreateFailed = register("category_translation_create_failed")
+	ErrCategoryTranslationUpdateFailed = register("category_translation_update_failed")
+	ErrCategoryTranslationDeleteFailed = register("category_translation_delete_failed")
+	ErrCategoryParentSelf              = register("category_parent_self")
+	ErrCategoryParentDescendant        = register("category_parent_descendant")
+	ErrCategoryHasChildren             = register("category_has_children")
+	ErrCategoryInUse                   = register("category_in_use")
+
+	// 語言
+	ErrLanguageNotFound           = register("language_not_found")
+	ErrLanguageNotActive          = register("language_not_active")
+	ErrLanguageCodeExists         = register("language_code_exists")
+	ErrLanguageCodeTaken          = register("language_code_taken")
+	ErrLanguageCreateFailed       = register("language_create_failed")
+	ErrLanguageUpdateFailed       = register("language_update_failed")
+	ErrLanguageDeleteFailed       = register("language_delete_failed")
+	ErrLanguageInUse              = register("language_in_use")
+	ErrLanguageDefaultResetFailed = register("language_default_reset_failed")
+	ErrLanguageDefaultDelete      = register("language_default_delete")
+	ErrLanguageDefaultDisable     = register("language_default_disable")
+	ErrLanguageInactiveDefault    = register("language_inactive_default")
+	ErrLanguagePreviewDefault     = register("language_preview_default")
+	ErrLanguageDefaultPreview     = register("language_default_preview")
+	ErrLanguageSetDefaultFailed   = register("language_set_default_failed")
+	ErrLanguageOrderUpdateFailed  = register("language_order_update_failed")
+	ErrLanguageStatusUpdateFailed = register("language_status_update_failed")
+	ErrLanguageFallbackSelf       = register("language_fallback_self")
+	ErrLanguageFallbackUnknown    = register("language_fallback_unknown")
+
+	// 圖片
+	ErrImageNotFound        = register("image_not_found")
+	ErrImageFileMissing     = register("image_file_missing")
+	ErrImageUploadFailed    = register("image_upload_failed")
+	ErrImageSaveFailed      = register("image_save_failed")
+	ErrImageUpdateFailed    = register("image_update_failed")
+	ErrImageReloadFailed    = register("image_reload_failed")
+	ErrImageDeleteFailed    = register("image_delete_failed")
+	ErrImageUpdateForbidden = register("image_update_forbidden")
+	ErrImageDeleteForbidden = register("image_delete_forbidden")
+
+	// 反應與收藏
+	ErrReactionTypeInvalid     = register("reaction_type_invalid")
+	ErrReactionAddFailed       = register("reaction_add_failed")
+	ErrReactionRemoveFailed    = register("reaction_remove_failed")
+	ErrBookmarkNotFound        = register("bookmark_not_found")
+	ErrBookmarkAddFailed       = register("bookmark_add_failed")
+	ErrBookmarkUpdateFailed    = register("bookmark_update_failed")
+	ErrBookmarkRemoveFailed    = register("bookmark_remove_failed")
+	ErrReadingListNotFound     = register("reading_list_not_found")
+	ErrReadingListCreateFailed = register("reading_list_create_failed")
+	ErrReadingListUpdateFailed = register("reading_list_update_failed")
+	ErrReadingListDeleteFailed = register("reading_list_delete_failed")
+
+	// 直播
+	ErrLiveEntryNotFound     = register("live_entry_not_found")
+	ErrLiveEntryCreateFailed = register("live_entry_create_failed")
+	ErrLiveEntryUpdateFailed = register("live_entry_update_failed")
+	ErrLiveEntryPinFailed    = register("live_entry_pin_failed")
+	ErrLiveEntryDeleteFailed = register("live_entry_delete_failed")
+	ErrLiveEventRecordFailed = register("live_event_record_failed")
+
+	// 編輯鎖
+	ErrArticleLocked      = register("article_locked")
+	ErrArticleLockNotHeld = register("article_lock_not_held")
+	ErrArticleLockFailed  = register("article_lock_failed")
+
+	// 電子報
+	ErrSubscriptionTokenFailed            = register("subscription_token_failed")
+	ErrSubscriptionSaveFailed             = register("subscription_save_failed")
+	ErrSubscriptionCategoriesUpdateFailed = register("subscription_categories_update_failed")
+	ErrConfirmationEmailFailed            = register("confirmation_email_failed")
+	ErrConfirmTokenMissing                = register("confirm_token_missing")
+	ErrConfirmTokenInvalid                = register("confirm_token_invalid")
+	ErrConfirmTokenExpired                = register("confirm_token_expired")
+	ErrSubscriptionConfirmFailed          = register("subscription_confirm_failed")
+	ErrUnsubscribeTokenMissing            = register("unsubscribe_token_missing")
+	ErrUnsubscribeTokenInvalid            = register("unsubscribe_token_invalid")
+	ErrUnsubscribeFailed                  = register("unsubscribe_failed")
+	ErrNewsletterSendFailed               = register("newsletter_send_failed")
+
+	// 搜尋、排行與分析
+	ErrSearchInvalidParams       = register("search_invalid_params")
+	ErrSearchFailed              = register("search_failed")
+	ErrTrendingWindowUnsupported = register("trending_window_unsupported")
+	ErrAnalyticsInvalidURL       = register("analytics_invalid_url")
+	ErrAnalyticsInvalidArticle   = register("analytics_invalid_article")
+	ErrAnalyticsRecordFailed     = register("analytics_record_failed")
+	ErrDateFromInvalid           = register("date_from_invalid")
+	ErrDateToInvalid             = register("date_to_invalid")
+	ErrDateRangeInvalid          = register("date_range_invalid")
+
+	// 垃圾訊息過濾
+	ErrSpamCheckNotFound          = register("spam_check_not_found")
+	ErrSpamClassifierUpdateFailed = register("spam_classifier_update_failed")
+	ErrSpamReviewUpdateFailed     = register("spam_review_update_failed")
+	ErrSpamDecisionConflict       = register("spam_decision_conflict")
+)
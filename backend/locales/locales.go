@@ -0,0 +1,228 @@
+// Package locales 載入內嵌的多語系訊息檔，提供錯誤代碼的在地化訊息
+package locales
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 預設語系：找不到請求語系或訊息時使用
+const DefaultLocale = "zh"
+
+//go:embed */messages.json
+var bundleFS embed.FS
+
+// 訊息佔位符的參數，例如 {"count": 3}
+type Params map[string]interface{}
+
+// 列舉值的參數，代入訊息時以語系的分隔符號連接，例如 List{"xliff", "po"}
+type List []string
+
+// 各語系列舉值的分隔符號，未列出的語系使用英文分隔符號
+var listSeparators = map[string]string{
+	"en": ", ",
+	"zh": "、",
+}
+
+// 單一訊息：一般字串，或依數量區分的複數形式（one、other 等）
+type message struct {
+	text   string
+	plural map[string]string
+}
+
+// 解析訊息檔中的字串或複數物件
+func (m *message) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &m.text); err == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &m.plural)
+}
+
+// 各語系的複數規則，未列出的語系使用英文規則
+var pluralRules = map[string]func(n int64) string{
+	"en": func(n int64) string {
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	},
+	"zh": func(n int64) string {
+		return "other"
+	},
+}
+
+var (
+	loadOnce sync.Once
+	catalogs map[string]map[string]message
+	loadErr  error
+)
+
+// 載入所有內嵌的訊息檔
+func load() {
+	catalogs = make(map[string]map[string]message)
+
+	files, err := bundleFS.ReadDir(".")
+	if err != nil {
+		loadErr = err
+		return
+	}
+
+	for _, dir := range files {
+		if !dir.IsDir() {
+			continue
+		}
+
+		data, err := bundleFS.ReadFile(path.Join(dir.Name(), "messages.json"))
+		if err != nil {
+			loadErr = err
+			return
+		}
+
+		var messages map[string]message
+		if err := json.Unmarshal(data, &messages); err != nil {
+			loadErr = fmt.Errorf("解析 %s 訊息檔失敗: %w", dir.Name(), err)
+			return
+		}
+		catalogs[dir.Name()] = messages
+	}
+}
+
+// 取得已載入的訊息檔（依語系）
+func bundles() map[string]map[string]message {
+	loadOnce.Do(load)
+	return catalogs
+}
+
+// 取得內嵌的語系清單
+func Locales() []string {
+	var names []string
+	for name := range bundles() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// 將語言代碼對應到內嵌語系：先比對完整代碼，再比對主要語言（例如 zh-TW 對應 zh）
+func Resolve(lang string) string {
+	lang = strings.ToLower(strings.ReplaceAll(lang, "_", "-"))
+	catalogs := bundles()
+	if _, ok := catalogs[lang]; ok {
+		return lang
+	}
+	primary, _, _ := strings.Cut(lang, "-")
+	if _, ok := catalogs[primary]; ok {
+		return primary
+	}
+	return DefaultLocale
+}
+
+// 依語言取得訊息並代入參數；訊息不存在時退回預設語系，仍不存在則回傳代碼本身
+func T(lang, code string, params ...Params) string {
+	var merged Params
+	if len(params) > 0 {
+		merged = Params{}
+		for _, p := range params {
+			for key, value := range p {
+				merged[key] = value
+			}
+		}
+	}
+
+	locale := Resolve(lang)
+	msg, ok := bundles()[locale][code]
+	if !ok {
+		locale = DefaultLocale
+		if msg, ok = bundles()[locale][code]; !ok {
+			return code
+		}
+	}
+
+	text := msg.text
+	if msg.plural != nil {
+		text = pluralForm(locale, msg.plural, merged["count"])
+	}
+
+	return format(locale, text, merged)
+}
+
+// 依數量選擇複數形式，找不到對應形式時使用 other
+func pluralForm(locale string, forms map[string]string, count interface{}) string {
+	rule, ok := pluralRules[locale]
+	if !ok {
+		rule = pluralRules["en"]
+	}
+	if text, ok := forms[rule(toInt64(count))]; ok {
+		return text
+	}
+	return forms["other"]
+}
+
+// 以參數取代訊息中的 {name} 佔位符
+func format(locale, text string, params Params) string {
+	if len(params) == 0 || !strings.Contains(text, "{") {
+		return text
+	}
+	pairs := make([]string, 0, len(params)*2)
+	for key, value := range params {
+		if list, ok := value.(List); ok {
+			pairs = append(pairs, "{"+key+"}", joinList(locale, list))
+			continue
+		}
+		pairs = append(pairs, "{"+key+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// 以語系的分隔符號連接列舉值
+func joinList(locale string, list List) string {
+	separator, ok := listSeparators[locale]
+	if !ok {
+		separator = listSeparators["en"]
+	}
+	return strings.Join(list, separator)
+}
+
+// 將數量參數轉為整數
+func toInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case int:
+		return int64(v)
+	case int64:
+		return v
+	case int32:
+		return int64(v)
+	case uint:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+// 帶有錯誤代碼的錯誤，供控制器轉為在地化的錯誤回應
+type Error struct {
+	Code   string
+	Params Params
+}
+
+// 新建帶有錯誤代碼的錯誤
+func NewError(code string, params ...Params) *Error {
+	e := &Error{Code: code}
+	if len(params) > 0 {
+		e.Params = params[0]
+	}
+	return e
+}
+
+// 以預設語系輸出錯誤訊息
+func (e *Error) Error() string {
+	return T(DefaultLocale, e.Code, e.Params)
+}
@@ -0,0 +1,15 @@
+package locales
+
+// 郵件內容（依訂閱者的語言渲染，與錯誤代碼一同檢查各語系皆有訊息）
+var (
+	MailConfirmSubject = register("mail_confirm_subject")
+	MailUpdateSubject  = register("mail_update_subject")
+	MailConfirmIntro   = register("mail_confirm_intro")
+	MailUpdateIntro    = register("mail_update_intro")
+	MailConfirmAction  = register("mail_confirm_action")
+	MailConfirmIgnore  = register("mail_confirm_ignore")
+	MailDigestSubject  = register("mail_digest_subject")
+	MailUnsubscribe    = register("mail_unsubscribe")
+	MailUnsubscribeAsk = register("mail_unsubscribe_prompt")
+	MailUnsubscribed   = register("mail_unsubscribed")
+)
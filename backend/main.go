@@ -2,11 +2,20 @@ package main
 
 import (
 	"GolangBlog/config"
+	"GolangBlog/controllers"
+	"GolangBlog/middlewares"
 	"GolangBlog/models"
 	"GolangBlog/routes"
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -33,6 +42,39 @@ func main() {
 		log.Fatalf("資料庫遷移失敗: %v", err)
 	}
 
+	// 初始化語言設定快取（語言退回順序）
+	controllers.InitLanguageRegistry(db)
+
+	// 初始化 Slug 服務（保留 slug）
+	controllers.InitSlugService()
+
+	// 初始化垃圾訊息過濾服務
+	controllers.InitSpamService(db)
+
+	// 初始化相關文章推薦服務
+	controllers.InitRelatedService(db)
+
+	// 初始化熱門文章排行服務
+	controllers.InitTrendingService(db)
+
+	// 初始化瀏覽計數服務
+	controllers.InitViewCounter(db)
+
+	// 初始化第一方分析服務
+	controllers.InitAnalyticsService(db)
+
+	// 初始化電子報服務與發送排程
+	controllers.InitNewsletterService(db)
+
+	// 初始化機器翻譯服務
+	controllers.InitMachineTranslator()
+
+	// 初始化翻譯記憶服務
+	controllers.InitTranslationMemory(db)
+
+	// 初始化編輯鎖設定
+	controllers.InitEditLocks()
+
 	// 創建 Gin 路由器
 	r := gin.Default()
 
@@ -40,11 +82,20 @@ func main() {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:5173"}, // 允許前端的來源
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "Last-Event-ID", "If-Match"},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: false, // 修改為 false，因為我們使用 JWT 而不是 cookies
 	}))
 
+	// 帶有 token 時辨識使用者身分（編輯者與管理員可看到未公開語言的內容）
+	r.Use(middlewares.OptionalJWT())
+
+	// 設定語言協商中間件（查詢參數、路徑前綴、Cookie、Accept-Language）
+	r.Use(middlewares.Locale(controllers.LanguageRegistry()))
+
+	// 支援語言前綴路徑，例如 /en/api/v1/articles
+	r.NoRoute(middlewares.LocalePrefix(r, controllers.LanguageRegistry()))
+
 	// 健康檢查路由
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -62,10 +113,39 @@ func main() {
 		port = "8080" // 預設埠號
 	}
 
-	// 啟動伺服器
+	// 關閉時取消所有請求的 context，讓串流連線（SSE）結束而不會拖住關閉程序
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
 	serverAddr := fmt.Sprintf(":%s", port)
-	log.Printf("伺服器啟動於 http://localhost%s", serverAddr)
-	if err := r.Run(serverAddr); err != nil {
-		log.Fatalf("啟動伺服器失敗: %v", err)
+	srv := &http.Server{
+		Addr:        serverAddr,
+		Handler:     r,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
 	}
+	srv.RegisterOnShutdown(cancelRequests)
+
+	// 啟動伺服器
+	go func() {
+		log.Printf("伺服器啟動於 http://localhost%s", serverAddr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("啟動伺服器失敗: %v", err)
+		}
+	}()
+
+	// 收到 SIGINT 或 SIGTERM 時停止接收新請求，等待進行中的請求完成
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Println("正在關閉伺服器...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("關閉伺服器逾時: %v", err)
+	}
+
+	// 寫入尚未儲存的瀏覽數
+	controllers.StopViewCounter()
+	log.Println("伺服器已關閉")
 }
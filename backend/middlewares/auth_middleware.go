@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"GolangBlog/locales"
 	"fmt"
 	"net/http"
 	"strings"
@@ -14,7 +15,7 @@ func JWTAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "未提供授權標頭"})
+			c.JSON(http.StatusUnauthorized, ErrorBody(c, locales.ErrAuthHeaderMissing))
 			c.Abort()
 			return
 		}
@@ -22,35 +23,25 @@ func JWTAuth() gin.HandlerFunc {
 		// 檢查標頭格式
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "授權標頭格式無效"})
+			c.JSON(http.StatusUnauthorized, ErrorBody(c, locales.ErrAuthHeaderInvalid))
 			c.Abort()
 			return
 		}
 
-		tokenString := parts[1]
-		claims := &Claims{}
-
-		// 解析 JWT token
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			// 驗證簽名算法
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("無效的簽名方法: %v", token.Header["alg"])
-			}
-			return []byte(getJWTKey()), nil
-		})
+		token, claims, err := parseToken(parts[1])
 
 		if err != nil {
 			if err == jwt.ErrSignatureInvalid {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "無效的 token 簽名"})
+				c.JSON(http.StatusUnauthorized, ErrorBody(c, locales.ErrTokenSignatureInvalid))
 			} else {
-				c.JSON(http.StatusUnauthorized, gin.H{"error": "無效或過期的 token"})
+				c.JSON(http.StatusUnauthorized, ErrorBody(c, locales.ErrTokenExpired))
 			}
 			c.Abort()
 			return
 		}
 
 		if !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "無效的 token"})
+			c.JSON(http.StatusUnauthorized, ErrorBody(c, locales.ErrTokenInvalid))
 			c.Abort()
 			return
 		}
@@ -62,18 +53,39 @@ func JWTAuth() gin.HandlerFunc {
 	}
 }
 
+// 選擇性 JWT 驗證中間件：帶有有效 token 時將使用者資訊加入上下文，
+// 未帶 token 或 token 無效時仍以訪客身分繼續處理
+func OptionalJWT() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if found && tokenString != "" {
+			if token, claims, err := parseToken(tokenString); err == nil && token.Valid {
+				c.Set("userID", claims.UserID)
+				c.Set("role", claims.Role)
+			}
+		}
+		c.Next()
+	}
+}
+
+// 請求者是否為編輯者或管理員
+func IsStaff(c *gin.Context) bool {
+	role := c.GetString("role")
+	return role == "admin" || role == "editor"
+}
+
 // 管理員權限檢查中間件
 func AdminOnly() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		role, exists := c.Get("role")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "未認證的使用者"})
+			c.JSON(http.StatusUnauthorized, ErrorBody(c, locales.ErrUnauthenticated))
 			c.Abort()
 			return
 		}
 
 		if role != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "需要管理員權限"})
+			c.JSON(http.StatusForbidden, ErrorBody(c, locales.ErrAdminRequired))
 			c.Abort()
 			return
 		}
@@ -87,13 +99,13 @@ func EditorOrAdminOnly() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		role, exists := c.Get("role")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "未認證的使用者"})
+			c.JSON(http.StatusUnauthorized, ErrorBody(c, locales.ErrUnauthenticated))
 			c.Abort()
 			return
 		}
 
 		if role != "admin" && role != "editor" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "需要編輯者或管理員權限"})
+			c.JSON(http.StatusForbidden, ErrorBody(c, locales.ErrEditorRequired))
 			c.Abort()
 			return
 		}
@@ -109,6 +121,19 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
+// 解析並驗證 JWT token
+func parseToken(tokenString string) (*jwt.Token, *Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		// 驗證簽名算法
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("無效的簽名方法: %v", token.Header["alg"])
+		}
+		return []byte(getJWTKey()), nil
+	})
+	return token, claims, err
+}
+
 // 從環境變數或配置文件獲取 JWT 金鑰
 func getJWTKey() string {
 	// 在實際應用中應從安全的地方獲取 JWT 密鑰
@@ -0,0 +1,15 @@
+package middlewares
+
+import (
+	"GolangBlog/locales"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 產生錯誤回應：穩定的錯誤代碼與依協商語言翻譯的訊息
+func ErrorBody(c *gin.Context, code string, params ...locales.Params) gin.H {
+	return gin.H{
+		"error": locales.T(c.GetString(LocaleKey), code, params...),
+		"code":  code,
+	}
+}
@@ -0,0 +1,196 @@
+package middlewares
+
+import (
+	"GolangBlog/locales"
+	"GolangBlog/services"
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Gin context 中存放請求語言的鍵
+const (
+	LocaleKey         = "locale"         // 解析後的請求語言代碼
+	LocaleExplicitKey = "localeExplicit" // 語言是否由查詢參數或路徑前綴明確指定
+)
+
+// 記錄使用者偏好語言的 Cookie 名稱
+const LocaleCookieName = "lang"
+
+// 路徑前綴語言在 request context 中的鍵（HandleContext 會清除 Gin context 的值）
+type localePrefixKey struct{}
+
+// 語言協商中間件：依序從查詢參數 lang、路徑前綴、Cookie 與 Accept-Language 標頭
+// 比對可選用的語言（訪客為公開語言，編輯者與管理員另可使用僅預覽的語言），
+// 皆無法比對時使用預設語言；需在 OptionalJWT 之後註冊
+func Locale(registry *services.LanguageRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		active := registry.VisibleCodes(IsStaff(c))
+
+		locale := ""
+		explicit := false
+
+		// 查詢參數
+		if lang := c.Query("lang"); lang != "" {
+			locale = MatchLanguage(lang, active)
+			explicit = locale != ""
+		}
+
+		// 路徑前綴（由 LocalePrefix 去除後轉交）
+		if locale == "" {
+			if prefix, ok := c.Request.Context().Value(localePrefixKey{}).(string); ok {
+				locale = prefix
+				explicit = true
+			}
+		}
+
+		// Cookie
+		if locale == "" {
+			if cookie, err := c.Cookie(LocaleCookieName); err == nil && cookie != "" {
+				locale = MatchLanguage(cookie, active)
+			}
+		}
+
+		// Accept-Language 標頭
+		if locale == "" {
+			for _, tag := range ParseAcceptLanguage(c.GetHeader("Accept-Language")) {
+				if locale = MatchLanguage(tag, active); locale != "" {
+					break
+				}
+			}
+		}
+
+		if locale == "" {
+			locale = registry.DefaultCode()
+		}
+
+		c.Set(LocaleKey, locale)
+		c.Set(LocaleExplicitKey, explicit)
+
+		// 回應內容依語言協商結果而不同
+		c.Header("Content-Language", locale)
+		addVary(c, "Accept-Language")
+		addVary(c, "Cookie")
+
+		c.Next()
+	}
+}
+
+// 處理帶有語言前綴的路徑（例如 /en/api/v1/articles），需註冊為 NoRoute 處理器
+func LocalePrefix(engine *gin.Engine, registry *services.LanguageRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := strings.TrimPrefix(c.Request.URL.Path, "/")
+		prefix, rest, found := strings.Cut(path, "/")
+
+		locale := ""
+		if found && prefix != "" {
+			for _, code := range registry.VisibleCodes(IsStaff(c)) {
+				if strings.EqualFold(code, prefix) {
+					locale = code
+					break
+				}
+			}
+		}
+
+		if locale == "" {
+			c.JSON(http.StatusNotFound, ErrorBody(c, locales.ErrRouteNotFound))
+			return
+		}
+
+		// 去除語言前綴後重新路由
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), localePrefixKey{}, locale))
+		c.Request.URL.Path = "/" + rest
+		c.Request.URL.RawPath = ""
+		engine.HandleContext(c)
+	}
+}
+
+// 在路徑前加上請求使用的語言前綴（請求未使用前綴時原樣回傳）
+func LocalePath(c *gin.Context, path string) string {
+	if prefix, ok := c.Request.Context().Value(localePrefixKey{}).(string); ok {
+		return "/" + prefix + path
+	}
+	return path
+}
+
+// 解析 Accept-Language 標頭，依權重由高到低回傳語言標籤
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var items []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		items = append(items, weighted{tag: tag, q: q})
+	}
+
+	// 權重相同時保留標頭中的順序
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].q > items[j].q
+	})
+
+	tags := make([]string, len(items))
+	for i, item := range items {
+		tags[i] = item.tag
+	}
+	return tags
+}
+
+// 將語言標籤比對到啟用中的語言：先比對完整代碼，再比對主要語言（例如 en-US 對應 en）
+func MatchLanguage(tag string, active []string) string {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if tag == "" {
+		return ""
+	}
+
+	for _, code := range active {
+		if strings.EqualFold(code, tag) {
+			return code
+		}
+	}
+
+	primary, _, _ := strings.Cut(tag, "-")
+	for _, code := range active {
+		codePrimary, _, _ := strings.Cut(code, "-")
+		if strings.EqualFold(codePrimary, primary) {
+			return code
+		}
+	}
+
+	return ""
+}
+
+// 加入 Vary 標頭值（避免重新路由時重複加入）
+func addVary(c *gin.Context, value string) {
+	for _, existing := range c.Writer.Header().Values("Vary") {
+		for _, field := range strings.Split(existing, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), value) {
+				return
+			}
+		}
+	}
+	c.Writer.Header().Add("Vary", value)
+}
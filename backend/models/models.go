@@ -14,6 +14,11 @@ type BaseModel struct {
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// Versioned 版本號：每次修改時遞增，用於 ETag 與 If-Match 的樂觀並行控制
+type Versioned struct {
+	Version uint `gorm:"default:1;not null" json:"version"`
+}
+
 // User 使用者模型
 type User struct {
 	BaseModel
@@ -24,24 +29,29 @@ type User struct {
 	LastName  string `gorm:"size:50" json:"last_name"`
 	Avatar    string `gorm:"size:255" json:"avatar"`
 	Role      string `gorm:"size:20;default:user" json:"role"`     // admin, editor, user
-	Status    string `gorm:"size:20;default:active" json:"status"` // active, disabled
+	Status    string `gorm:"size:20;default:active" json:"status"` // active, pending, disabled
 }
 
 // Language 語言模型
 type Language struct {
 	BaseModel
-	Code       string `gorm:"size:10;uniqueIndex" json:"code"`
-	Name       string `gorm:"size:50;not null" json:"name"`
-	NativeName string `gorm:"size:50;not null" json:"native_name"`
-	IsActive   bool   `gorm:"default:true" json:"is_active"`
-	IsDefault  bool   `gorm:"default:false" json:"is_default"`
-	Direction  string `gorm:"size:3;default:ltr" json:"direction"` // ltr, rtl
-	SortOrder  int    `gorm:"default:0" json:"sort_order"`
+	Versioned
+	Code         string `gorm:"size:10;uniqueIndex" json:"code"`
+	Name         string `gorm:"size:50;not null" json:"name"`
+	NativeName   string `gorm:"size:50;not null" json:"native_name"`
+	IsActive     bool   `gorm:"default:true" json:"is_active"`
+	IsPreview    bool   `gorm:"default:false" json:"is_preview"` // 僅預覽：啟用但只有編輯者與管理員可見，用於上線前準備
+	IsDefault    bool   `gorm:"default:false" json:"is_default"`
+	Direction    string `gorm:"size:3;default:ltr" json:"direction"` // ltr, rtl
+	SortOrder    int    `gorm:"default:0" json:"sort_order"`
+	Fallbacks    string `gorm:"size:255" json:"fallbacks"`                          // 以逗號分隔的退回語言代碼，依序使用，如 "zh-TW,en"
+	SlugStrategy string `gorm:"size:20;default:transliterate" json:"slug_strategy"` // transliterate（轉寫為拉丁字母）, native（保留原文字）
 }
 
 // Article 文章模型（語言無關）
 type Article struct {
 	BaseModel
+	Versioned
 	UserID        uint                  `json:"user_id"`
 	User          User                  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:UserID" json:"user"`
 	Status        string                `gorm:"size:20;default:draft" json:"status"` // draft, published, archived
@@ -51,11 +61,25 @@ type Article struct {
 	IsFeatured    bool                  `gorm:"default:false" json:"is_featured"`
 	Translations  []ArticleTranslation  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:ArticleID" json:"translations"`
 	Tags          []Tag                 `gorm:"many2many:article_tags;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"tags"`
+
+	ReactionCounts map[string]int64 `gorm:"-" json:"reaction_counts"`           // 各類反應的數量，查詢後填入
+	ServedLanguage string           `gorm:"-" json:"served_language,omitempty"` // 依退回順序實際提供的語言
+}
+
+// TranslationStatus 翻譯與來源語言版本的同步狀態
+type TranslationStatus struct {
+	SourceRevision int  `gorm:"default:0" json:"source_revision"`        // 來源語言為目前版本；其他語言為翻譯時依據的來源版本
+	NeedsUpdate    bool `gorm:"default:false;index" json:"needs_update"` // 來源語言內容已更新，翻譯待更新
+
+	MachineTranslated bool   `gorm:"default:false" json:"machine_translated"` // 由機器翻譯產生
+	TranslationEngine string `gorm:"size:50" json:"translation_engine"`       // 產生翻譯的服務
+	ReviewStatus      string `gorm:"size:20;index" json:"review_status"`      // 機器翻譯的審閱狀態：pending（待審閱）或 reviewed（已審閱）
 }
 
 // ArticleTranslation 文章翻譯模型（語言相關）
 type ArticleTranslation struct {
 	BaseModel
+	TranslationStatus
 	ArticleID    uint   `gorm:"index:idx_article_lang,unique;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"article_id"`
 	LanguageCode string `gorm:"size:10;index:idx_article_lang,unique;uniqueIndex:idx_article_slug_lang" json:"language_code"`
 	Title        string `gorm:"size:200;not null" json:"title"`
@@ -65,41 +89,55 @@ type ArticleTranslation struct {
 	MetaTitle       string `gorm:"size:200" json:"meta_title"`
 	MetaDescription string `gorm:"size:500" json:"meta_description"`
 	MetaKeywords    string `gorm:"size:255" json:"meta_keywords"`
+
+	Dir string `gorm:"-" json:"dir"` // 文字方向（ltr 或 rtl），查詢後依語言填入
 }
 
 // Tag 標籤模型
 type Tag struct {
 	BaseModel
+	Versioned
 	Translations []TagTranslation `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:TagID" json:"translations"`
 	Articles     []Article        `gorm:"many2many:article_tags" json:"-"`
+
+	ServedLanguage string `gorm:"-" json:"served_language,omitempty"` // 依退回順序實際提供的語言
 }
 
 // TagTranslation 標籤翻譯模型
 type TagTranslation struct {
 	BaseModel
+	TranslationStatus
 	TagID        uint   `gorm:"index:idx_tag_lang,unique;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"tag_id"`
 	LanguageCode string `gorm:"size:10;index:idx_tag_lang,unique;uniqueIndex:idx_tag_slug_lang" json:"language_code"`
 	Name         string `gorm:"size:50;not null" json:"name"`
 	Slug         string `gorm:"size:100;index;uniqueIndex:idx_tag_slug_lang" json:"slug"`
+
+	Dir string `gorm:"-" json:"dir"` // 文字方向（ltr 或 rtl），查詢後依語言填入
 }
 
 // Category 分類模型
 type Category struct {
 	BaseModel
+	Versioned
 	ParentID     *uint                  `json:"parent_id"`
 	Parent       *Category              `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;foreignKey:ParentID" json:"parent,omitempty"`
 	Translations []CategoryTranslation  `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:CategoryID" json:"translations"`
 	Articles     []Article              `gorm:"many2many:article_categories" json:"-"`
+
+	ServedLanguage string `gorm:"-" json:"served_language,omitempty"` // 依退回順序實際提供的語言
 }
 
 // CategoryTranslation 分類翻譯模型
 type CategoryTranslation struct {
 	BaseModel
+	TranslationStatus
 	CategoryID   uint   `gorm:"index:idx_category_lang,unique;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"category_id"`
 	LanguageCode string `gorm:"size:10;index:idx_category_lang,unique;uniqueIndex:idx_category_slug_lang" json:"language_code"`
 	Name         string `gorm:"size:100;not null" json:"name"`
 	Slug         string `gorm:"size:150;index;uniqueIndex:idx_category_slug_lang" json:"slug"`
 	Description  string `gorm:"size:500" json:"description"`
+
+	Dir string `gorm:"-" json:"dir"` // 文字方向（ltr 或 rtl），查詢後依語言填入
 }
 
 // Image 圖片模型
@@ -146,6 +184,255 @@ type SettingTranslation struct {
 	SettingID    uint   `gorm:"index:idx_setting_lang,unique;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"setting_id"`
 	LanguageCode string `gorm:"size:10;index:idx_setting_lang,unique" json:"language_code"`
 	Value        string `gorm:"type:text" json:"value"`
+
+	Dir string `gorm:"-" json:"dir"` // 文字方向（ltr 或 rtl），查詢後依語言填入
+}
+
+// GlossaryTerm 詞彙表：來源語言詞彙在目標語言必須使用的譯法
+type GlossaryTerm struct {
+	BaseModel
+	SourceLanguage string `gorm:"size:10;uniqueIndex:idx_glossary_term" json:"source_language"`
+	SourceTerm     string `gorm:"size:100;not null;uniqueIndex:idx_glossary_term" json:"source_term"`
+	TargetLanguage string `gorm:"size:10;uniqueIndex:idx_glossary_term;index" json:"target_language"`
+	TargetTerm     string `gorm:"size:100;not null" json:"target_term"`
+	CaseSensitive  bool   `gorm:"default:false" json:"case_sensitive"` // 比對時是否區分大小寫
+	Note           string `gorm:"size:255" json:"note"`
+}
+
+// SlugRedirect 舊 slug 轉址：文章、標籤或分類變更 slug 後保留舊 slug，以 301 轉址到目前的 slug
+type SlugRedirect struct {
+	BaseModel
+	Type         string     `gorm:"size:20;uniqueIndex:idx_redirect_slug" json:"type"` // article, tag, category
+	LanguageCode string     `gorm:"size:10;uniqueIndex:idx_redirect_slug" json:"language_code"`
+	OldSlug      string     `gorm:"size:255;uniqueIndex:idx_redirect_slug" json:"old_slug"`
+	TargetID     uint       `gorm:"index" json:"target_id"`
+	IsCustom     bool       `gorm:"default:false" json:"is_custom"` // 管理員手動新增
+	HitCount     int64      `gorm:"default:0" json:"hit_count"`
+	LastHitAt    *time.Time `json:"last_hit_at"`
+
+	CanonicalSlug string `gorm:"-" json:"canonical_slug,omitempty"` // 目前的 slug，查詢後填入
+}
+
+// SpamLog 垃圾訊息檢查紀錄（供審核與訓練分類器使用）
+type SpamLog struct {
+	BaseModel
+	Kind        string     `gorm:"size:20;index" json:"kind"` // comment, register
+	ReferenceID *uint      `gorm:"index" json:"reference_id"` // 對應的資料 ID，例如註冊的使用者 ID
+	IP          string     `gorm:"size:45;index" json:"ip"`
+	UserAgent   string     `gorm:"size:255" json:"user_agent"`
+	AuthorName  string     `gorm:"size:100" json:"author_name"`
+	AuthorEmail string     `gorm:"size:100" json:"author_email"`
+	Content     string     `gorm:"type:text" json:"content"`
+	Score       float64    `json:"score"`
+	Verdict     string     `gorm:"size:20;index" json:"verdict"`                  // ham, suspect, spam
+	Signals     string     `gorm:"type:text" json:"signals"`                      // JSON 格式的各規則評分
+	Decision    string     `gorm:"size:20;default:pending;index" json:"decision"` // pending, spam, ham
+	DecidedBy   *uint      `json:"decided_by"`
+	DecidedAt   *time.Time `json:"decided_at"`
+}
+
+// SpamToken 貝氏分類器的詞彙統計
+type SpamToken struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	Token     string `gorm:"size:100;uniqueIndex" json:"token"`
+	SpamCount int    `gorm:"default:0" json:"spam_count"`
+	HamCount  int    `gorm:"default:0" json:"ham_count"`
+}
+
+// ArticleReaction 讀者對文章的反應（每位使用者每種反應一筆）
+type ArticleReaction struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	ArticleID uint      `gorm:"uniqueIndex:idx_reaction_article_user_type;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"article_id"`
+	UserID    uint      `gorm:"uniqueIndex:idx_reaction_article_user_type;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"user_id"`
+	Type      string    `gorm:"size:20;uniqueIndex:idx_reaction_article_user_type" json:"type"` // like, love, haha, wow, sad, angry
+}
+
+// ReadingList 使用者的閱讀清單
+type ReadingList struct {
+	BaseModel
+	UserID      uint   `gorm:"index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"user_id"`
+	Name        string `gorm:"size:100;not null" json:"name"`
+	Description string `gorm:"size:500" json:"description"`
+}
+
+// Bookmark 使用者收藏的文章
+type Bookmark struct {
+	ID            uint         `gorm:"primaryKey" json:"id"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+	UserID        uint         `gorm:"uniqueIndex:idx_bookmark_user_article;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"user_id"`
+	ArticleID     uint         `gorm:"uniqueIndex:idx_bookmark_user_article;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"article_id"`
+	Article       Article      `gorm:"foreignKey:ArticleID" json:"article"`
+	ReadingListID *uint        `gorm:"index" json:"reading_list_id"` // 為空表示未分類
+	ReadingList   *ReadingList `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL;foreignKey:ReadingListID" json:"reading_list,omitempty"`
+	Note          string       `gorm:"size:500" json:"note"`
+}
+
+// NewsletterSubscriber 電子報訂閱者（每個電子郵件每種語言一筆）
+type NewsletterSubscriber struct {
+	BaseModel
+	Email             string     `gorm:"size:100;not null;uniqueIndex:idx_subscriber_email_lang" json:"email"`
+	LanguageCode      string     `gorm:"size:10;not null;uniqueIndex:idx_subscriber_email_lang" json:"language_code"`
+	Status            string     `gorm:"size:20;default:pending;index" json:"status"` // pending, confirmed, unsubscribed
+	ConfirmToken      string     `gorm:"size:64;index" json:"-"`
+	ConfirmSentAt     *time.Time `json:"-"`                 // 確認連結產生的時間，超過有效期限後不可使用
+	PendingCategories string     `gorm:"size:500" json:"-"` // 待確認的分類 ID（以逗號分隔，空白表示全部分類），點擊確認連結後才生效
+	UnsubscribeToken  string     `gorm:"size:64;uniqueIndex" json:"-"`
+	ConfirmedAt       *time.Time `json:"confirmed_at"`
+	UnsubscribedAt    *time.Time `json:"unsubscribed_at"`
+	Categories        []Category `gorm:"many2many:newsletter_subscriber_categories;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"categories"` // 為空表示訂閱全部分類
+}
+
+// NewsletterSendLog 電子報發送紀錄
+type NewsletterSendLog struct {
+	BaseModel
+	SubscriberID uint   `gorm:"index;uniqueIndex:idx_newsletter_digest_once,where:kind = 'digest'" json:"subscriber_id"`
+	Email        string `gorm:"size:100" json:"email"`
+	LanguageCode string `gorm:"size:10;index" json:"language_code"`
+	Kind         string `gorm:"size:20;index" json:"kind"` // confirmation, digest
+	// 電子報期別，例如 2025-W18；同一訂閱者每期只會發送一次
+	DigestKey    string     `gorm:"size:20;index;uniqueIndex:idx_newsletter_digest_once,where:kind = 'digest'" json:"digest_key"`
+	Subject      string     `gorm:"size:255" json:"subject"`
+	ArticleCount int        `json:"article_count"`
+	Status       string     `gorm:"size:20;index" json:"status"` // sending, sent, failed
+	Error        string     `gorm:"type:text" json:"error"`
+	SentAt       *time.Time `json:"sent_at"`
+}
+
+// LiveBlogEntry 直播文章的即時更新
+type LiveBlogEntry struct {
+	BaseModel
+	ArticleID    uint   `gorm:"index:idx_live_entry_article_lang;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"article_id"`
+	LanguageCode string `gorm:"size:10;index:idx_live_entry_article_lang" json:"language_code"`
+	UserID       uint   `json:"user_id"`
+	User         User   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:UserID" json:"user"`
+	Title        string `gorm:"size:200" json:"title"`
+	Content      string `gorm:"type:text;not null" json:"content"`
+	IsPinned     bool   `gorm:"default:false" json:"is_pinned"`
+}
+
+// LiveBlogEvent 直播更新事件（遞增的 ID 作為 SSE 的事件 ID，供斷線續傳）
+type LiveBlogEvent struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	ArticleID    uint      `gorm:"index:idx_live_event_article_lang" json:"article_id"`
+	LanguageCode string    `gorm:"size:10;index:idx_live_event_article_lang" json:"language_code"`
+	EntryID      uint      `json:"entry_id"`
+	Type         string    `gorm:"size:20" json:"type"` // created, updated, pinned, unpinned, deleted
+}
+
+// ArticleEditLock 文章的建議性編輯鎖（每篇文章最多一個；持有者需定期續約，過期後其他人可取得）
+type ArticleEditLock struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ArticleID   uint      `gorm:"uniqueIndex;not null;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"article_id"`
+	UserID      uint      `gorm:"not null" json:"user_id"`
+	User        User      `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:UserID" json:"user"`
+	AcquiredAt  time.Time `json:"acquired_at"`
+	HeartbeatAt time.Time `json:"heartbeat_at"`
+	ExpiresAt   time.Time `gorm:"index" json:"expires_at"`
+}
+
+// Series 文章系列／合集
+type Series struct {
+	BaseModel
+	Status       string              `gorm:"size:20;default:draft" json:"status"` // draft, published
+	CoverImage   string              `gorm:"size:255" json:"cover_image"`
+	Translations []SeriesTranslation `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:SeriesID" json:"translations"`
+	Items        []SeriesArticle     `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:SeriesID" json:"items,omitempty"`
+}
+
+// SeriesTranslation 文章系列翻譯模型
+type SeriesTranslation struct {
+	BaseModel
+	SeriesID     uint   `gorm:"index:idx_series_lang,unique;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"series_id"`
+	LanguageCode string `gorm:"size:10;index:idx_series_lang,unique;uniqueIndex:idx_series_slug_lang" json:"language_code"`
+	Title        string `gorm:"size:200;not null" json:"title"`
+	Slug         string `gorm:"size:255;index;uniqueIndex:idx_series_slug_lang" json:"slug"`
+	Description  string `gorm:"size:1000" json:"description"`
+
+	Dir string `gorm:"-" json:"dir"` // 文字方向（ltr 或 rtl），查詢後依語言填入
+}
+
+// SeriesArticle 文章系列與文章的有序關聯
+type SeriesArticle struct {
+	SeriesID  uint    `gorm:"primaryKey" json:"series_id"`
+	ArticleID uint    `gorm:"primaryKey;index" json:"article_id"`
+	Position  int     `gorm:"not null;default:0" json:"position"`
+	Article   Article `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;foreignKey:ArticleID" json:"article"`
+}
+
+// ArticleViewStat 文章每小時瀏覽數統計
+type ArticleViewStat struct {
+	ArticleID   uint      `gorm:"primaryKey;autoIncrement:false" json:"article_id"`
+	BucketStart time.Time `gorm:"primaryKey;index" json:"bucket_start"` // 統計區間的起始整點
+	Views       int64     `gorm:"not null;default:0" json:"views"`
+}
+
+// AnalyticsEvent 第一方分析事件（不保存 IP，訪客以每日輪換的雜湊識別）
+type AnalyticsEvent struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	CreatedAt    time.Time `gorm:"index" json:"created_at"`
+	Type         string    `gorm:"size:20;index" json:"type"` // page_view, scroll, read_complete
+	ArticleID    *uint     `gorm:"index" json:"article_id"`
+	LanguageCode string    `gorm:"size:10;index" json:"language_code"`
+	Path         string    `gorm:"size:500" json:"path"`
+	VisitorHash  string    `gorm:"size:64;index" json:"-"`
+	ReferrerHost string    `gorm:"size:255" json:"referrer_host"`
+	ReferrerType string    `gorm:"size:20" json:"referrer_type"` // direct, internal, search, social, other
+	UTMSource    string    `gorm:"size:100" json:"utm_source"`
+	UTMMedium    string    `gorm:"size:100" json:"utm_medium"`
+	UTMCampaign  string    `gorm:"size:100" json:"utm_campaign"`
+	Country      string    `gorm:"size:2" json:"country"`
+	DeviceType   string    `gorm:"size:10" json:"device_type"` // desktop, mobile, tablet
+	ScrollDepth  int       `json:"scroll_depth"`               // 0-100
+	ReadSeconds  int       `json:"read_seconds"`
+}
+
+// AnalyticsSalt 每日輪換的訪客雜湊鹽值，過期即刪除以確保無法回推訪客
+type AnalyticsSalt struct {
+	Day       string    `gorm:"primaryKey;size:10" json:"day"` // YYYY-MM-DD (UTC)
+	Salt      string    `gorm:"size:64;not null" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DirectionResolver 依語言代碼取得文字方向，由語言設定快取初始化時指定
+var DirectionResolver func(langCode string) string
+
+// 取得語言的文字方向，未設定解析函式時為 ltr
+func TextDirection(langCode string) string {
+	if DirectionResolver != nil {
+		if dir := DirectionResolver(langCode); dir != "" {
+			return dir
+		}
+	}
+	return "ltr"
+}
+
+// 查詢後填入文字方向
+func (t *ArticleTranslation) AfterFind(tx *gorm.DB) error {
+	t.Dir = TextDirection(t.LanguageCode)
+	return nil
+}
+
+func (t *TagTranslation) AfterFind(tx *gorm.DB) error {
+	t.Dir = TextDirection(t.LanguageCode)
+	return nil
+}
+
+func (t *CategoryTranslation) AfterFind(tx *gorm.DB) error {
+	t.Dir = TextDirection(t.LanguageCode)
+	return nil
+}
+
+func (t *SettingTranslation) AfterFind(tx *gorm.DB) error {
+	t.Dir = TextDirection(t.LanguageCode)
+	return nil
+}
+
+func (t *SeriesTranslation) AfterFind(tx *gorm.DB) error {
+	t.Dir = TextDirection(t.LanguageCode)
+	return nil
 }
 
 // AutoMigrate 將所有模型依照正確順序遷移到資料庫
@@ -170,5 +457,31 @@ func AutoMigrate(db *gorm.DB) error {
 
 		&Setting{},
 		&SettingTranslation{},
+
+		&GlossaryTerm{},
+		&SlugRedirect{},
+
+		&SpamLog{},
+		&SpamToken{},
+
+		&ArticleReaction{},
+		&ReadingList{},
+		&Bookmark{},
+
+		&NewsletterSubscriber{},
+		&NewsletterSendLog{},
+
+		&LiveBlogEntry{},
+		&LiveBlogEvent{},
+		&ArticleEditLock{},
+
+		&Series{},
+		&SeriesTranslation{},
+		&SeriesArticle{},
+
+		&ArticleViewStat{},
+
+		&AnalyticsEvent{},
+		&AnalyticsSalt{},
 	)
 }
@@ -31,6 +31,11 @@ func SetupRoutes(router *gin.Engine) {
 			articles.GET("/featured", controllers.GetFeaturedArticles)             // 新增獲取精選文章的路由
 			articles.GET("/latest", controllers.GetLatestArticles)                 // 新增獲取最新文章的路由
 			articles.GET("/category/:category", controllers.GetArticlesByCategory) // 新增根據分類獲取文章的路由
+			articles.GET("/trending", controllers.GetTrendingArticles)
+			articles.GET("/:id/related", controllers.GetRelatedArticles)
+			articles.GET("/:id/reactions", controllers.GetArticleReactions)
+			articles.GET("/:id/live", controllers.GetLiveBlogEntries)
+			articles.GET("/:id/live/stream", controllers.StreamLiveBlog)
 		}
 
 		// 公開標籤API
@@ -38,6 +43,7 @@ func SetupRoutes(router *gin.Engine) {
 		{
 			tags.GET("", controllers.GetTags)
 			tags.GET("/:id", controllers.GetTag)
+			tags.GET("/slug/:slug", controllers.GetTagBySlug)
 		}
 
 		// 公開分類API
@@ -45,6 +51,15 @@ func SetupRoutes(router *gin.Engine) {
 		{
 			categories.GET("", controllers.GetCategories)
 			categories.GET("/:id", controllers.GetCategory)
+			categories.GET("/slug/:slug", controllers.GetCategoryBySlug)
+		}
+
+		// 公開文章系列API
+		series := public.Group("/series")
+		{
+			series.GET("", controllers.GetSeriesList)
+			series.GET("/:id", controllers.GetSeries)
+			series.GET("/slug/:slug", controllers.GetSeriesBySlug)
 		}
 
 		// 公開語言API
@@ -62,6 +77,18 @@ func SetupRoutes(router *gin.Engine) {
 			images.GET("/:id", controllers.GetImage)
 		}
 
+		// 電子報訂閱
+		newsletter := public.Group("/newsletter")
+		{
+			newsletter.POST("/subscribe", controllers.Subscribe)
+			newsletter.GET("/confirm", controllers.ConfirmSubscription)
+			newsletter.GET("/unsubscribe", controllers.UnsubscribePage)
+			newsletter.POST("/unsubscribe", controllers.Unsubscribe)
+		}
+
+		// 第一方分析事件
+		public.POST("/analytics/events", controllers.TrackAnalyticsEvent)
+
 		// 設置靜態檔案服務 (如果使用本地儲存)
 		router.Static("/uploads", "./uploads")
 	}
@@ -73,6 +100,25 @@ func SetupRoutes(router *gin.Engine) {
 		// 獲取當前使用者資訊
 		authenticated.GET("/user", controllers.GetCurrentUser)
 
+		// 文章反應
+		reactions := authenticated.Group("/articles/:id/reactions")
+		{
+			reactions.POST("", controllers.AddArticleReaction)
+			reactions.DELETE("/:type", controllers.RemoveArticleReaction)
+		}
+
+		// 收藏與閱讀清單
+		user := authenticated.Group("/user")
+		{
+			user.GET("/bookmarks", controllers.GetBookmarks)
+			user.POST("/bookmarks", controllers.SaveBookmark)
+			user.DELETE("/bookmarks/:article_id", controllers.DeleteBookmark)
+			user.GET("/reading-lists", controllers.GetReadingLists)
+			user.POST("/reading-lists", controllers.CreateReadingList)
+			user.PUT("/reading-lists/:id", controllers.UpdateReadingList)
+			user.DELETE("/reading-lists/:id", controllers.DeleteReadingList)
+		}
+
 		// 通用工具
 		utils := authenticated.Group("/utils")
 		{
@@ -96,8 +142,33 @@ func SetupRoutes(router *gin.Engine) {
 		editorArticles := editor.Group("/admin/articles")
 		{
 			editorArticles.POST("", controllers.CreateArticle)
+			editorArticles.POST("/bulk", controllers.BulkUpdateArticles)
 			editorArticles.PUT("/:id", controllers.UpdateArticle)
+			editorArticles.PATCH("/:id", controllers.PatchArticle)
 			editorArticles.DELETE("/:id", controllers.DeleteArticle)
+
+			// 直播更新
+			editorArticles.POST("/:id/live", controllers.CreateLiveBlogEntry)
+			editorArticles.PUT("/:id/live/:entry_id", controllers.UpdateLiveBlogEntry)
+			editorArticles.PUT("/:id/live/:entry_id/pin", controllers.PinLiveBlogEntry)
+			editorArticles.DELETE("/:id/live/:entry_id", controllers.DeleteLiveBlogEntry)
+
+			// 編輯鎖與在線狀態
+			editorArticles.POST("/:id/lock", controllers.AcquireArticleLock)
+			editorArticles.PUT("/:id/lock", controllers.RenewArticleLock)
+			editorArticles.DELETE("/:id/lock", controllers.ReleaseArticleLock)
+			editorArticles.GET("/:id/presence", controllers.GetArticlePresence)
+			editorArticles.GET("/:id/presence/stream", controllers.StreamArticlePresence)
+		}
+
+		// 文章系列管理
+		editorSeries := editor.Group("/admin/series")
+		{
+			editorSeries.GET("/:id", controllers.GetSeriesAdmin)
+			editorSeries.POST("", controllers.CreateSeries)
+			editorSeries.PUT("/:id", controllers.UpdateSeries)
+			editorSeries.DELETE("/:id", controllers.DeleteSeries)
+			editorSeries.PUT("/:id/articles", controllers.SetSeriesArticles)
 		}
 
 		// 標籤管理
@@ -108,6 +179,24 @@ func SetupRoutes(router *gin.Engine) {
 			editorTags.DELETE("/:id", controllers.DeleteTag)
 		}
 
+		// 翻譯覆蓋率、機器翻譯與翻譯交換檔
+		editor.GET("/admin/translations/coverage", controllers.GetTranslationCoverage)
+		editor.POST("/admin/translations/machine", controllers.MachineTranslate)
+		editor.PUT("/admin/translations/review", controllers.ReviewTranslation)
+		editor.POST("/admin/translations/export", controllers.ExportTranslations)
+		editor.POST("/admin/translations/import", controllers.ImportTranslations)
+
+		// 翻譯記憶與詞彙表
+		editor.POST("/admin/translations/memory", controllers.SuggestTranslations)
+		editorGlossary := editor.Group("/admin/glossary")
+		{
+			editorGlossary.GET("", controllers.GetGlossaryTerms)
+			editorGlossary.POST("", controllers.CreateGlossaryTerm)
+			editorGlossary.PUT("/:id", controllers.UpdateGlossaryTerm)
+			editorGlossary.DELETE("/:id", controllers.DeleteGlossaryTerm)
+			editorGlossary.POST("/check", controllers.CheckGlossary)
+		}
+
 		// 分類管理
 		editorCategories := editor.Group("/admin/categories")
 		{
@@ -129,9 +218,42 @@ func SetupRoutes(router *gin.Engine) {
 			adminLanguages.DELETE("/:id", controllers.DeleteLanguage)
 			adminLanguages.PUT("/:id/default", controllers.SetDefaultLanguage)
 			adminLanguages.PUT("/:id/toggle", controllers.ToggleLanguageStatus)
+			adminLanguages.PUT("/:id/preview", controllers.ToggleLanguagePreview)
 			adminLanguages.PUT("/order", controllers.UpdateLanguageOrder)
 		}
 
+		// 垃圾訊息審核
+		adminSpam := admin.Group("/spam")
+		{
+			adminSpam.GET("/logs", controllers.GetSpamLogs)
+			adminSpam.PUT("/logs/:id/decision", controllers.UpdateSpamDecision)
+		}
+
+		// 電子報管理
+		adminNewsletter := admin.Group("/newsletter")
+		{
+			adminNewsletter.GET("/subscribers", controllers.GetSubscribers)
+			adminNewsletter.GET("/logs", controllers.GetNewsletterLogs)
+			adminNewsletter.GET("/preview", controllers.PreviewDigest)
+			adminNewsletter.POST("/send", controllers.SendDigest)
+		}
+
+		// 接手文章的編輯鎖
+		admin.POST("/articles/:id/lock/takeover", controllers.TakeOverArticleLock)
+
+		// Slug 轉址管理
+		adminRedirects := admin.Group("/redirects")
+		{
+			adminRedirects.GET("", controllers.GetSlugRedirects)
+			adminRedirects.POST("", controllers.CreateSlugRedirect)
+			adminRedirects.PUT("/:id", controllers.UpdateSlugRedirect)
+			adminRedirects.DELETE("/:id", controllers.DeleteSlugRedirect)
+		}
+
+		// 後台總覽與分析統計
+		admin.GET("/stats", controllers.GetAdminStats)
+		admin.GET("/analytics/stats", controllers.GetAnalyticsStats)
+
 		// 將來可以添加其他管理員專屬功能，如用戶管理、系統設置等
 	}
 }
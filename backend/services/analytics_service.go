@@ -0,0 +1,343 @@
+package services
+
+import (
+	"GolangBlog/models"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 分析事件類型
+const (
+	AnalyticsPageView     = "page_view"
+	AnalyticsScroll       = "scroll"
+	AnalyticsReadComplete = "read_complete"
+)
+
+// 來源類型
+const (
+	ReferrerDirect   = "direct"
+	ReferrerInternal = "internal"
+	ReferrerSearch   = "search"
+	ReferrerSocial   = "social"
+	ReferrerOther    = "other"
+)
+
+// 常見搜尋引擎網域關鍵字
+var searchEngines = []string{"google.", "bing.com", "duckduckgo.com", "yahoo.", "baidu.com", "yandex.", "naver.com", "ecosia.org", "search.brave.com"}
+
+// 常見社群網站網域
+var socialNetworks = []string{"facebook.com", "fb.me", "t.co", "twitter.com", "x.com", "linkedin.com", "lnkd.in", "reddit.com", "instagram.com", "threads.net", "line.me", "ptt.cc", "dcard.tw", "news.ycombinator.com", "mastodon.social", "bsky.app", "youtube.com", "weibo.com"}
+
+// 分析服務配置
+type AnalyticsConfig struct {
+	SiteHosts []string // 視為站內來源的網域
+}
+
+// 已解析的來源資訊
+type ReferrerInfo struct {
+	Host string
+	Type string
+}
+
+// 活動追蹤參數
+type UTMParams struct {
+	Source   string
+	Medium   string
+	Campaign string
+}
+
+// 分析統計的篩選條件
+type AnalyticsFilter struct {
+	From         time.Time
+	To           time.Time
+	ArticleID    *uint
+	LanguageCode string
+}
+
+// 第一方分析服務
+type AnalyticsService struct {
+	config AnalyticsConfig
+	db     *gorm.DB
+	mu     sync.Mutex
+	day    string
+	salt   string
+}
+
+// 新建第一方分析服務
+func NewAnalyticsService(config AnalyticsConfig, db *gorm.DB) *AnalyticsService {
+	for i, host := range config.SiteHosts {
+		config.SiteHosts[i] = strings.ToLower(strings.TrimSpace(host))
+	}
+	return &AnalyticsService{config: config, db: db}
+}
+
+// 解析來源網址並分類
+func (s *AnalyticsService) ParseReferrer(referrer string) ReferrerInfo {
+	if referrer == "" {
+		return ReferrerInfo{Type: ReferrerDirect}
+	}
+
+	u, err := url.Parse(referrer)
+	if err != nil || u.Hostname() == "" {
+		return ReferrerInfo{Type: ReferrerDirect}
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	info := ReferrerInfo{Host: host, Type: ReferrerOther}
+
+	for _, siteHost := range s.config.SiteHosts {
+		if host == strings.TrimPrefix(siteHost, "www.") {
+			info.Type = ReferrerInternal
+			return info
+		}
+	}
+	for _, engine := range searchEngines {
+		if strings.Contains(host, engine) {
+			info.Type = ReferrerSearch
+			return info
+		}
+	}
+	for _, network := range socialNetworks {
+		if host == network || strings.HasSuffix(host, "."+network) {
+			info.Type = ReferrerSocial
+			return info
+		}
+	}
+
+	return info
+}
+
+// 解析頁面網址中的 UTM 參數
+func ParseUTM(pageURL string) UTMParams {
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return UTMParams{}
+	}
+	query := u.Query()
+	return UTMParams{
+		Source:   Truncate(strings.ToLower(query.Get("utm_source")), 100),
+		Medium:   Truncate(strings.ToLower(query.Get("utm_medium")), 100),
+		Campaign: Truncate(query.Get("utm_campaign"), 100),
+	}
+}
+
+// 將 IP 截斷為網段（IPv4 保留 /24，IPv6 保留 /48）
+func TruncateIP(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return parsed.Mask(net.CIDRMask(48, 128)).String()
+}
+
+// 依 User-Agent 判斷裝置類型
+func DeviceType(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case strings.Contains(ua, "ipad") || strings.Contains(ua, "tablet"):
+		return "tablet"
+	case strings.Contains(ua, "mobi") || strings.Contains(ua, "iphone") || strings.Contains(ua, "android"):
+		return "mobile"
+	default:
+		return "desktop"
+	}
+}
+
+// 計算訪客雜湊：截斷後的 IP 與 User-Agent 加上當日鹽值，跨日即無法關聯
+func (s *AnalyticsService) VisitorHash(ip, userAgent string, now time.Time) (string, error) {
+	salt, err := s.dailySalt(now)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(salt + "|" + TruncateIP(ip) + "|" + userAgent))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// 取得當日鹽值，多個實例共用資料庫中的同一組鹽值，並刪除過期鹽值
+func (s *AnalyticsService) dailySalt(now time.Time) (string, error) {
+	day := now.UTC().Format("2006-01-02")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.day == day {
+		return s.salt, nil
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	salt := models.AnalyticsSalt{Day: day, Salt: hex.EncodeToString(buf)}
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&salt).Error; err != nil {
+		return "", err
+	}
+	if err := s.db.Where("day = ?", day).First(&salt).Error; err != nil {
+		return "", err
+	}
+
+	s.db.Where("day < ?", day).Delete(&models.AnalyticsSalt{})
+
+	s.day = day
+	s.salt = salt.Salt
+	return s.salt, nil
+}
+
+// 儲存分析事件
+func (s *AnalyticsService) Track(event *models.AnalyticsEvent) error {
+	return s.db.Create(event).Error
+}
+
+// 套用統計篩選條件
+func (s *AnalyticsService) filtered(filter AnalyticsFilter) *gorm.DB {
+	query := s.db.Model(&models.AnalyticsEvent{}).
+		Where("created_at >= ? AND created_at < ?", filter.From, filter.To)
+	if filter.ArticleID != nil {
+		query = query.Where("article_id = ?", *filter.ArticleID)
+	}
+	if filter.LanguageCode != "" {
+		query = query.Where("language_code = ?", filter.LanguageCode)
+	}
+	return query
+}
+
+// 統計總覽
+type AnalyticsTotals struct {
+	PageViews      int64   `json:"page_views"`
+	Visitors       int64   `json:"visitors"` // 每日不重複訪客加總（雜湊每日輪換）
+	ReadCompletes  int64   `json:"read_completes"`
+	CompletionRate float64 `json:"completion_rate"`
+	AvgScrollDepth float64 `json:"avg_scroll_depth"`
+	AvgReadSeconds float64 `json:"avg_read_seconds"`
+}
+
+// 每日統計
+type AnalyticsDay struct {
+	Day       string `json:"day"`
+	PageViews int64  `json:"page_views"`
+	Visitors  int64  `json:"visitors"`
+}
+
+// 分組統計
+type AnalyticsBreakdown struct {
+	Key       string `json:"key"`
+	PageViews int64  `json:"page_views"`
+}
+
+// 文章統計
+type AnalyticsArticle struct {
+	ArticleID     uint   `json:"article_id"`
+	LanguageCode  string `json:"language_code"`
+	PageViews     int64  `json:"page_views"`
+	ReadCompletes int64  `json:"read_completes"`
+}
+
+// 分析統計報表
+type AnalyticsReport struct {
+	Totals        AnalyticsTotals      `json:"totals"`
+	Days          []AnalyticsDay       `json:"days"`
+	ReferrerTypes []AnalyticsBreakdown `json:"referrer_types"`
+	Referrers     []AnalyticsBreakdown `json:"referrers"`
+	UTMSources    []AnalyticsBreakdown `json:"utm_sources"`
+	UTMCampaigns  []AnalyticsBreakdown `json:"utm_campaigns"`
+	Countries     []AnalyticsBreakdown `json:"countries"`
+	Devices       []AnalyticsBreakdown `json:"devices"`
+	Languages     []AnalyticsBreakdown `json:"languages"`
+	Articles      []AnalyticsArticle   `json:"articles"`
+}
+
+// 產生分析統計報表
+func (s *AnalyticsService) Stats(filter AnalyticsFilter) (*AnalyticsReport, error) {
+	report := &AnalyticsReport{}
+
+	// 每日瀏覽與訪客
+	if err := s.filtered(filter).
+		Select("TO_CHAR(created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, COUNT(*) AS page_views, COUNT(DISTINCT visitor_hash) AS visitors").
+		Where("type = ?", AnalyticsPageView).
+		Group("day").Order("day").
+		Scan(&report.Days).Error; err != nil {
+		return nil, err
+	}
+	for _, day := range report.Days {
+		report.Totals.PageViews += day.PageViews
+		report.Totals.Visitors += day.Visitors
+	}
+
+	if err := s.filtered(filter).Where("type = ?", AnalyticsReadComplete).
+		Count(&report.Totals.ReadCompletes).Error; err != nil {
+		return nil, err
+	}
+	if report.Totals.PageViews > 0 {
+		report.Totals.CompletionRate = float64(report.Totals.ReadCompletes) / float64(report.Totals.PageViews)
+	}
+
+	// 每位訪客在每篇文章的最大捲動深度平均
+	maxDepth := s.filtered(filter).Select("MAX(scroll_depth) AS depth").
+		Where("type IN ?", []string{AnalyticsScroll, AnalyticsReadComplete}).
+		Group("visitor_hash, article_id")
+	if err := s.db.Table("(?) AS depths", maxDepth).
+		Select("COALESCE(AVG(depth), 0)").Scan(&report.Totals.AvgScrollDepth).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.filtered(filter).Where("type = ? AND read_seconds > 0", AnalyticsReadComplete).
+		Select("COALESCE(AVG(read_seconds), 0)").Scan(&report.Totals.AvgReadSeconds).Error; err != nil {
+		return nil, err
+	}
+
+	breakdowns := []struct {
+		column string
+		target *[]AnalyticsBreakdown
+	}{
+		{"referrer_type", &report.ReferrerTypes},
+		{"referrer_host", &report.Referrers},
+		{"utm_source", &report.UTMSources},
+		{"utm_campaign", &report.UTMCampaigns},
+		{"country", &report.Countries},
+		{"device_type", &report.Devices},
+		{"language_code", &report.Languages},
+	}
+	for _, b := range breakdowns {
+		if err := s.filtered(filter).
+			Select(b.column+" AS key, COUNT(*) AS page_views").
+			Where("type = ? AND "+b.column+" <> ''", AnalyticsPageView).
+			Group(b.column).Order("page_views DESC").Limit(20).
+			Scan(b.target).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.filtered(filter).
+		Select("article_id, language_code, "+
+			"SUM(CASE WHEN type = ? THEN 1 ELSE 0 END) AS page_views, "+
+			"SUM(CASE WHEN type = ? THEN 1 ELSE 0 END) AS read_completes", AnalyticsPageView, AnalyticsReadComplete).
+		Where("article_id IS NOT NULL").
+		Group("article_id, language_code").Order("page_views DESC").Limit(50).
+		Scan(&report.Articles).Error; err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// 依字元數截斷字串（不會切斷多位元組字元）
+func Truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit])
+}
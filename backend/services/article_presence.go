@@ -0,0 +1,113 @@
+package services
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// 正在查看文章的使用者
+type PresenceUser struct {
+	UserID   uint      `json:"user_id"`
+	Username string    `json:"username"`
+	Status   string    `json:"status"` // viewing, editing
+	Since    time.Time `json:"since"`
+}
+
+// 單一連線的查看者
+type presenceViewer struct {
+	userID   uint
+	username string
+	since    time.Time
+	notify   chan struct{}
+}
+
+// 文章的在線狀態追蹤（單一程序內）：記錄透過串流連線查看文章的使用者，
+// 並在有人加入、離開或編輯鎖變更時通知同一篇文章的所有連線
+type ArticlePresence struct {
+	mu      sync.Mutex
+	viewers map[uint]map[*presenceViewer]struct{}
+}
+
+// 新建文章在線狀態追蹤
+func NewArticlePresence() *ArticlePresence {
+	return &ArticlePresence{
+		viewers: make(map[uint]map[*presenceViewer]struct{}),
+	}
+}
+
+// 加入文章的查看者，回傳變更通知通道與離開函數
+func (p *ArticlePresence) Join(articleID, userID uint, username string) (<-chan struct{}, func()) {
+	viewer := &presenceViewer{
+		userID:   userID,
+		username: username,
+		since:    time.Now(),
+		notify:   make(chan struct{}, 1),
+	}
+
+	p.mu.Lock()
+	if p.viewers[articleID] == nil {
+		p.viewers[articleID] = make(map[*presenceViewer]struct{})
+	}
+	p.viewers[articleID][viewer] = struct{}{}
+	p.mu.Unlock()
+	p.Notify(articleID)
+
+	leave := func() {
+		p.mu.Lock()
+		delete(p.viewers[articleID], viewer)
+		if len(p.viewers[articleID]) == 0 {
+			delete(p.viewers, articleID)
+		}
+		p.mu.Unlock()
+		p.Notify(articleID)
+	}
+
+	return viewer.notify, leave
+}
+
+// 通知文章的所有連線在線狀態已變更（通知會合併，不會阻塞）
+func (p *ArticlePresence) Notify(articleID uint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for viewer := range p.viewers[articleID] {
+		select {
+		case viewer.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// 目前查看文章的使用者（同一使用者多個連線只列一次，依加入時間排序）
+func (p *ArticlePresence) Viewers(articleID uint) []PresenceUser {
+	p.mu.Lock()
+	byUser := make(map[uint]*PresenceUser)
+	for viewer := range p.viewers[articleID] {
+		if user, ok := byUser[viewer.userID]; ok {
+			if viewer.since.Before(user.Since) {
+				user.Since = viewer.since
+			}
+			continue
+		}
+		byUser[viewer.userID] = &PresenceUser{
+			UserID:   viewer.userID,
+			Username: viewer.username,
+			Status:   "viewing",
+			Since:    viewer.since,
+		}
+	}
+	p.mu.Unlock()
+
+	users := make([]PresenceUser, 0, len(byUser))
+	for _, user := range byUser {
+		users = append(users, *user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].Since.Equal(users[j].Since) {
+			return users[i].Since.Before(users[j].Since)
+		}
+		return users[i].UserID < users[j].UserID
+	})
+	return users
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"GolangBlog/models"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// 未設定預設語言時使用的語言
+const FallbackLanguageCode = "zh-TW"
+
+// 語言設定快取：提供預設語言與各語言的退回順序
+type LanguageRegistry struct {
+	db          *gorm.DB
+	mu          sync.RWMutex
+	loaded      bool
+	gen         uint64 // 每次清除快取時遞增，避免載入期間的變更被舊資料覆蓋
+	defaultCode string
+	languages   map[string]models.Language
+	active      []string // 啟用中的語言代碼（依排序，包含僅預覽的語言）
+	public      []string // 公開的語言代碼：啟用且非僅預覽（依排序）
+}
+
+// 新建語言設定快取
+func NewLanguageRegistry(db *gorm.DB) *LanguageRegistry {
+	return &LanguageRegistry{db: db}
+}
+
+// 解析以逗號分隔的語言代碼
+func ParseLanguageList(value string) []string {
+	var codes []string
+	for _, code := range strings.Split(value, ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// 語言設定變更後清除快取，下次使用時重新載入
+func (r *LanguageRegistry) Invalidate() {
+	r.mu.Lock()
+	r.loaded = false
+	r.gen++
+	r.mu.Unlock()
+}
+
+// 確保已載入語言設定
+func (r *LanguageRegistry) ensureLoaded() {
+	r.mu.RLock()
+	loaded := r.loaded
+	gen := r.gen
+	r.mu.RUnlock()
+	if loaded {
+		return
+	}
+
+	var languages []models.Language
+	if err := r.db.Order("sort_order asc").Find(&languages).Error; err != nil {
+		return
+	}
+
+	byCode := make(map[string]models.Language, len(languages))
+	active := make([]string, 0, len(languages))
+	public := make([]string, 0, len(languages))
+	defaultCode := FallbackLanguageCode
+	for _, language := range languages {
+		byCode[language.Code] = language
+		if language.IsActive {
+			active = append(active, language.Code)
+			if !language.IsPreview {
+				public = append(public, language.Code)
+			}
+		}
+		if language.IsDefault {
+			defaultCode = language.Code
+		}
+	}
+
+	r.mu.Lock()
+	r.languages = byCode
+	r.active = active
+	r.public = public
+	r.defaultCode = defaultCode
+	r.loaded = r.gen == gen
+	r.mu.Unlock()
+}
+
+// 取得預設語言代碼
+func (r *LanguageRegistry) DefaultCode() string {
+	r.ensureLoaded()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.defaultCode == "" {
+		return FallbackLanguageCode
+	}
+	return r.defaultCode
+}
+
+// 取得語言設定
+func (r *LanguageRegistry) Get(code string) (models.Language, bool) {
+	r.ensureLoaded()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	language, ok := r.languages[code]
+	return language, ok
+}
+
+// 取得啟用中的語言代碼（依排序）
+func (r *LanguageRegistry) ActiveCodes() []string {
+	r.ensureLoaded()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.active...)
+}
+
+// 取得公開的語言代碼（依排序），訪客只能看到這些語言的內容
+func (r *LanguageRegistry) PublicCodes() []string {
+	r.ensureLoaded()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.public...)
+}
+
+// 取得請求者可選用的語言代碼：編輯者與管理員可使用僅預覽的語言
+func (r *LanguageRegistry) VisibleCodes(staff bool) []string {
+	if staff {
+		return r.ActiveCodes()
+	}
+	return r.PublicCodes()
+}
+
+// 語言是否公開（啟用且非僅預覽）
+func (r *LanguageRegistry) IsPublic(code string) bool {
+	language, ok := r.Get(code)
+	return ok && language.IsActive && !language.IsPreview
+}
+
+// 取得語言的退回順序：請求語言、其設定的退回語言（逐層展開），最後為預設語言
+func (r *LanguageRegistry) Chain(code string) []string {
+	r.ensureLoaded()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := map[string]bool{code: true}
+	chain := []string{code}
+	for i := 0; i < len(chain); i++ {
+		language, ok := r.languages[chain[i]]
+		if !ok {
+			continue
+		}
+		for _, fallback := range ParseLanguageList(language.Fallbacks) {
+			if !seen[fallback] {
+				seen[fallback] = true
+				chain = append(chain, fallback)
+			}
+		}
+	}
+
+	defaultCode := r.defaultCode
+	if defaultCode == "" {
+		defaultCode = FallbackLanguageCode
+	}
+	if !seen[defaultCode] {
+		chain = append(chain, defaultCode)
+	}
+
+	return chain
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"GolangBlog/models"
+	"sync"
+)
+
+// 直播更新訊息
+type LiveBlogMessage struct {
+	Event models.LiveBlogEvent
+	Entry *models.LiveBlogEntry
+}
+
+// 直播更新的訂閱者
+type liveBlogSubscriber struct {
+	articleID uint
+	lang      string
+	ch        chan LiveBlogMessage
+}
+
+// 直播更新的訊息分派器（單一程序內）
+type LiveBlogBroker struct {
+	mu          sync.RWMutex
+	subscribers map[uint]map[*liveBlogSubscriber]struct{}
+}
+
+// 新建直播更新分派器
+func NewLiveBlogBroker() *LiveBlogBroker {
+	return &LiveBlogBroker{
+		subscribers: make(map[uint]map[*liveBlogSubscriber]struct{}),
+	}
+}
+
+// 訂閱指定文章與語言的更新，回傳訊息通道與取消訂閱函數
+func (b *LiveBlogBroker) Subscribe(articleID uint, lang string) (<-chan LiveBlogMessage, func()) {
+	sub := &liveBlogSubscriber{
+		articleID: articleID,
+		lang:      lang,
+		ch:        make(chan LiveBlogMessage, 16),
+	}
+
+	b.mu.Lock()
+	if b.subscribers[articleID] == nil {
+		b.subscribers[articleID] = make(map[*liveBlogSubscriber]struct{})
+	}
+	b.subscribers[articleID][sub] = struct{}{}
+	b.mu.Unlock()
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers[articleID], sub)
+		if len(b.subscribers[articleID]) == 0 {
+			delete(b.subscribers, articleID)
+		}
+	}
+
+	return sub.ch, cancel
+}
+
+// 將更新推送給對應文章與語言的所有訂閱者
+func (b *LiveBlogBroker) Publish(msg LiveBlogMessage) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscribers[msg.Event.ArticleID] {
+		if sub.lang != msg.Event.LanguageCode {
+			continue
+		}
+		// 緩衝已滿的慢速訂閱者略過，之後會透過資料庫補送
+		select {
+		case sub.ch <- msg:
+		default:
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// 郵件發送策略
+const (
+	FileMailer = "file" // 寫入本地檔案（本機測試用）
+	SMTPMailer = "smtp" // 透過 SMTP 伺服器發送
+)
+
+// 郵件內容
+type MailMessage struct {
+	To       string
+	Subject  string
+	HTMLBody string
+	TextBody string
+	Headers  map[string]string // 額外的郵件標頭，例如 List-Unsubscribe
+}
+
+// 郵件發送介面
+type Mailer interface {
+	Send(msg MailMessage) error
+}
+
+// 郵件配置
+type MailConfig struct {
+	Strategy     string // 發送策略: "file" 或 "smtp"
+	From         string // 寄件者，例如 "GolangBlog <news@example.com>"
+	FileDir      string // 檔案策略的輸出目錄
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// 依配置建立郵件發送器
+func NewMailer(config MailConfig) (Mailer, error) {
+	switch config.Strategy {
+	case FileMailer, "":
+		if config.FileDir == "" {
+			config.FileDir = "mail_outbox"
+		}
+		if err := os.MkdirAll(config.FileDir, 0755); err != nil {
+			return nil, fmt.Errorf("創建郵件輸出目錄失敗: %w", err)
+		}
+		return &fileMailer{dir: config.FileDir, from: config.From}, nil
+	case SMTPMailer:
+		if config.SMTPHost == "" {
+			return nil, errors.New("未設定 SMTP 主機")
+		}
+		if config.SMTPPort == 0 {
+			config.SMTPPort = 587
+		}
+		return &smtpMailer{config: config}, nil
+	default:
+		return nil, errors.New("不支援的郵件發送策略")
+	}
+}
+
+// 將郵件寫成 .eml 檔案的發送器
+type fileMailer struct {
+	dir  string
+	from string
+}
+
+func (m *fileMailer) Send(msg MailMessage) error {
+	data, err := buildMIMEMessage(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102-150405.000000"), sanitizeFileName(msg.To))
+	if err := os.WriteFile(filepath.Join(m.dir, name), data, 0644); err != nil {
+		return fmt.Errorf("寫入郵件檔案失敗: %w", err)
+	}
+	return nil
+}
+
+// 透過 SMTP 發送郵件
+type smtpMailer struct {
+	config MailConfig
+}
+
+func (m *smtpMailer) Send(msg MailMessage) error {
+	data, err := buildMIMEMessage(m.config.From, msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.config.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", m.config.SMTPUsername, m.config.SMTPPassword, m.config.SMTPHost)
+	}
+
+	addr := fmt.Sprintf("%s:%d", m.config.SMTPHost, m.config.SMTPPort)
+	if err := smtp.SendMail(addr, auth, extractAddress(m.config.From), []string{msg.To}, data); err != nil {
+		return fmt.Errorf("SMTP 發送失敗: %w", err)
+	}
+	return nil
+}
+
+// 組合 multipart/alternative 格式的郵件
+func buildMIMEMessage(from string, msg MailMessage) ([]byte, error) {
+	boundaryBytes := make([]byte, 12)
+	if _, err := rand.Read(boundaryBytes); err != nil {
+		return nil, err
+	}
+	boundary := "b_" + hex.EncodeToString(boundaryBytes)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	for key, value := range msg.Headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain", msg.TextBody},
+		{"text/html", msg.HTMLBody},
+	}
+	for _, part := range parts {
+		if part.body == "" {
+			continue
+		}
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		fmt.Fprintf(&buf, "Content-Type: %s; charset=utf-8\r\n", part.contentType)
+		fmt.Fprintf(&buf, "Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		qp := quotedprintable.NewWriter(&buf)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		qp.Close()
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+
+	return buf.Bytes(), nil
+}
+
+// 從 "名稱 <地址>" 格式中取出電子郵件地址
+func extractAddress(from string) string {
+	if start := strings.LastIndex(from, "<"); start >= 0 {
+		if end := strings.LastIndex(from, ">"); end > start {
+			return from[start+1 : end]
+		}
+	}
+	return strings.TrimSpace(from)
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// 將字串轉為安全的檔案名稱
+func sanitizeFileName(s string) string {
+	return unsafeFileChars.ReplaceAllString(s, "_")
+}
@@ -0,0 +1,489 @@
+package services
+
+import (
+	"GolangBlog/locales"
+	"GolangBlog/models"
+	"GolangBlog/utils"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 電子報訂閱狀態
+const (
+	SubscriberPending      = "pending"
+	SubscriberConfirmed    = "confirmed"
+	SubscriberUnsubscribed = "unsubscribed"
+)
+
+// 電子報發送紀錄類型
+const (
+	NewsletterKindConfirmation = "confirmation"
+	NewsletterKindDigest       = "digest"
+)
+
+// 電子報中文章摘要的字數上限
+const digestExcerptLength = 200
+
+// 電子報配置
+type NewsletterConfig struct {
+	SiteName      string       // 網站名稱，用於郵件標題
+	SiteURL       string       // 前台網址，用於文章連結
+	APIURL        string       // API 網址，用於確認與退訂連結
+	DigestWeekday time.Weekday // 每週發送電子報的星期
+	DigestHour    int          // 每週發送電子報的時間（小時）
+
+	ConfirmInterval time.Duration // 同一訂閱重新寄送確認信的最短間隔
+	ConfirmTTL      time.Duration // 確認連結的有效期限
+}
+
+// 電子報服務
+type NewsletterService struct {
+	db     *gorm.DB
+	mailer Mailer
+	config NewsletterConfig
+}
+
+// 電子報中的單篇文章
+type DigestArticle struct {
+	Title       string
+	Excerpt     string
+	URL         string
+	PublishedAt time.Time
+}
+
+// 一期電子報的內容
+type Digest struct {
+	Key          string
+	LanguageCode string
+	Dir          string // 文字方向（ltr 或 rtl）
+	Since        time.Time
+	Until        time.Time
+	Articles     []DigestArticle
+}
+
+// 電子報發送結果統計
+type DigestReport struct {
+	DigestKey string `json:"digest_key"`
+	Sent      int    `json:"sent"`
+	Skipped   int    `json:"skipped"`
+	Empty     int    `json:"empty"`
+	Failed    int    `json:"failed"`
+}
+
+// 新建電子報服務
+func NewNewsletterService(config NewsletterConfig, db *gorm.DB, mailer Mailer) *NewsletterService {
+	if config.SiteName == "" {
+		config.SiteName = "GolangBlog"
+	}
+	if config.ConfirmInterval <= 0 {
+		config.ConfirmInterval = 10 * time.Minute
+	}
+	if config.ConfirmTTL <= 0 {
+		config.ConfirmTTL = 48 * time.Hour
+	}
+	return &NewsletterService{db: db, mailer: mailer, config: config}
+}
+
+// 產生隨機令牌
+func GenerateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// 取得日期所屬的電子報期別（ISO 週）
+func DigestKeyFor(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// 發送訂閱確認信（已確認的訂閱者為分類變更的確認信）
+func (s *NewsletterService) SendConfirmation(sub *models.NewsletterSubscriber) error {
+	lang := sub.LanguageCode
+	subject, intro := locales.MailConfirmSubject, locales.MailConfirmIntro
+	if sub.Status == SubscriberConfirmed {
+		subject, intro = locales.MailUpdateSubject, locales.MailUpdateIntro
+	}
+
+	data := map[string]interface{}{
+		"SiteName":     s.config.SiteName,
+		"LanguageCode": lang,
+		"Dir":          models.TextDirection(lang),
+		"Intro":        locales.T(lang, intro),
+		"Action":       locales.T(lang, locales.MailConfirmAction),
+		"Ignore":       locales.T(lang, locales.MailConfirmIgnore),
+		"ConfirmURL":   fmt.Sprintf("%s/api/v1/newsletter/confirm?token=%s", strings.TrimRight(s.config.APIURL, "/"), sub.ConfirmToken),
+	}
+
+	htmlBody, textBody, err := renderMail(confirmHTMLTemplate, confirmTextTemplate, data)
+	if err != nil {
+		return err
+	}
+
+	msg := MailMessage{
+		To:       sub.Email,
+		Subject:  locales.T(lang, subject, locales.Params{"site": s.config.SiteName}),
+		HTMLBody: htmlBody,
+		TextBody: textBody,
+	}
+
+	sendLog := models.NewsletterSendLog{
+		SubscriberID: sub.ID,
+		Email:        sub.Email,
+		LanguageCode: sub.LanguageCode,
+		Kind:         NewsletterKindConfirmation,
+		Subject:      msg.Subject,
+	}
+	sendErr := s.mailer.Send(msg)
+	s.finishLog(&sendLog, sendErr)
+	s.db.Create(&sendLog)
+
+	return sendErr
+}
+
+// 是否在最短間隔內已寄過確認信（避免重複送出訂閱請求時不斷寄信）
+func (s *NewsletterService) ConfirmationThrottled(sub *models.NewsletterSubscriber) bool {
+	if sub.ID == 0 {
+		return false
+	}
+
+	var count int64
+	s.db.Model(&models.NewsletterSendLog{}).
+		Where("subscriber_id = ? AND kind = ? AND status <> ? AND created_at > ?",
+			sub.ID, NewsletterKindConfirmation, "failed", time.Now().Add(-s.config.ConfirmInterval)).
+		Count(&count)
+	return count > 0
+}
+
+// 確認連結是否已超過有效期限（沒有產生時間的舊連結視為過期）
+func (s *NewsletterService) ConfirmationExpired(sub *models.NewsletterSubscriber) bool {
+	return sub.ConfirmSentAt == nil || time.Since(*sub.ConfirmSentAt) > s.config.ConfirmTTL
+}
+
+// 渲染退訂頁面：尚未退訂時顯示確認按鈕（以 POST 退訂，避免郵件掃描工具開啟連結就退訂），退訂後顯示結果
+func (s *NewsletterService) RenderUnsubscribePage(sub *models.NewsletterSubscriber) (string, error) {
+	lang := sub.LanguageCode
+	params := locales.Params{"site": s.config.SiteName}
+
+	data := map[string]interface{}{
+		"SiteName":       s.config.SiteName,
+		"LanguageCode":   lang,
+		"Dir":            models.TextDirection(lang),
+		"Done":           sub.Status == SubscriberUnsubscribed,
+		"Prompt":         locales.T(lang, locales.MailUnsubscribeAsk, params),
+		"Result":         locales.T(lang, locales.MailUnsubscribed, params),
+		"Action":         locales.T(lang, locales.MailUnsubscribe),
+		"UnsubscribeURL": s.unsubscribeURL(sub.UnsubscribeToken),
+	}
+
+	var buf bytes.Buffer
+	if err := unsubscribeHTMLTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("渲染退訂頁面失敗: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// 組合指定語言與分類在時間區間內發布的文章
+func (s *NewsletterService) BuildDigest(langCode string, categoryIDs []uint, since, until time.Time) (*Digest, error) {
+	var translations []models.ArticleTranslation
+
+	query := s.db.Model(&models.ArticleTranslation{}).
+		Select("article_translations.*").
+		Joins("JOIN articles ON articles.id = article_translations.article_id AND articles.deleted_at IS NULL").
+		Where("article_translations.language_code = ?", langCode).
+		Where(ReviewedTranslationFilter("article_translations")).
+		Where("articles.status = ? AND articles.published_at >= ? AND articles.published_at < ?", "published", since, until)
+
+	if len(categoryIDs) > 0 {
+		query = query.Where("articles.id IN (?)",
+			s.db.Table("article_categories").Select("article_id").Where("category_id IN ?", categoryIDs))
+	}
+
+	if err := query.Order("articles.published_at DESC").Limit(30).Find(&translations).Error; err != nil {
+		return nil, err
+	}
+
+	// 取得發布時間
+	publishedAt := make(map[uint]time.Time)
+	if len(translations) > 0 {
+		ids := make([]uint, 0, len(translations))
+		for _, t := range translations {
+			ids = append(ids, t.ArticleID)
+		}
+		var articles []models.Article
+		s.db.Select("id, published_at").Where("id IN ?", ids).Find(&articles)
+		for _, a := range articles {
+			if a.PublishedAt != nil {
+				publishedAt[a.ID] = *a.PublishedAt
+			}
+		}
+	}
+
+	digest := &Digest{
+		Key:          DigestKeyFor(until.Add(-time.Second)),
+		LanguageCode: langCode,
+		Dir:          models.TextDirection(langCode),
+		Since:        since,
+		Until:        until,
+	}
+	for _, t := range translations {
+		// 未填寫摘要時從內文產生
+		excerpt := utils.TruncateText(t.Excerpt, digestExcerptLength)
+		if strings.TrimSpace(excerpt) == "" {
+			excerpt = utils.Excerpt(t.Content, digestExcerptLength)
+		}
+
+		digest.Articles = append(digest.Articles, DigestArticle{
+			Title:       t.Title,
+			Excerpt:     excerpt,
+			URL:         fmt.Sprintf("%s/%s/articles/%s", strings.TrimRight(s.config.SiteURL, "/"), langCode, t.Slug),
+			PublishedAt: publishedAt[t.ArticleID],
+		})
+	}
+
+	return digest, nil
+}
+
+// 將電子報內容渲染為 HTML 與純文字
+func (s *NewsletterService) RenderDigest(digest *Digest, unsubscribeToken string) (string, string, error) {
+	data := map[string]interface{}{
+		"SiteName":       s.config.SiteName,
+		"Digest":         digest,
+		"Unsubscribe":    locales.T(digest.LanguageCode, locales.MailUnsubscribe),
+		"UnsubscribeURL": s.unsubscribeURL(unsubscribeToken),
+	}
+	return renderMail(digestHTMLTemplate, digestTextTemplate, data)
+}
+
+// 發送截至指定時間前一週的電子報給所有已確認的訂閱者
+func (s *NewsletterService) SendWeeklyDigest(now time.Time) (DigestReport, error) {
+	until := now.Truncate(time.Hour)
+	since := until.AddDate(0, 0, -7)
+	report := DigestReport{DigestKey: DigestKeyFor(until.Add(-time.Second))}
+
+	// 只發送給公開語言的訂閱者，停用或僅預覽語言的訂閱者待語言公開後再發送
+	publicLanguages := s.db.Model(&models.Language{}).Select("code").Where("is_active = ? AND is_preview = ?", true, false)
+
+	var subscribers []models.NewsletterSubscriber
+	if err := s.db.Preload("Categories").Where("status = ? AND language_code IN (?)", SubscriberConfirmed, publicLanguages).
+		Find(&subscribers).Error; err != nil {
+		return report, err
+	}
+
+	// 相同語言與分類組合的訂閱者共用同一份內容
+	digests := make(map[string]*Digest)
+
+	for i := range subscribers {
+		sub := &subscribers[i]
+
+		categoryIDs := make([]uint, 0, len(sub.Categories))
+		for _, cat := range sub.Categories {
+			categoryIDs = append(categoryIDs, cat.ID)
+		}
+		sort.Slice(categoryIDs, func(a, b int) bool { return categoryIDs[a] < categoryIDs[b] })
+		cacheKey := fmt.Sprintf("%s|%v", sub.LanguageCode, categoryIDs)
+
+		digest, ok := digests[cacheKey]
+		if !ok {
+			var err error
+			if digest, err = s.BuildDigest(sub.LanguageCode, categoryIDs, since, until); err != nil {
+				return report, err
+			}
+			digests[cacheKey] = digest
+		}
+
+		if len(digest.Articles) == 0 {
+			report.Empty++
+			continue
+		}
+
+		sendLog, claimed := s.claimDigest(sub, digest)
+		if !claimed {
+			report.Skipped++
+			continue
+		}
+
+		err := s.sendDigest(sub, digest, sendLog)
+		s.finishLog(sendLog, err)
+		s.db.Save(sendLog)
+
+		if err != nil {
+			log.Printf("發送電子報給 %s 失敗: %v", sub.Email, err)
+			report.Failed++
+		} else {
+			report.Sent++
+		}
+	}
+
+	return report, nil
+}
+
+// 每小時檢查一次，於設定的星期與時間發送電子報
+func (s *NewsletterService) StartScheduler() {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			if now.Weekday() != s.config.DigestWeekday || now.Hour() != s.config.DigestHour {
+				continue
+			}
+			report, err := s.SendWeeklyDigest(now)
+			if err != nil {
+				log.Printf("電子報排程執行失敗: %v", err)
+				continue
+			}
+			log.Printf("電子報 %s 發送完成: 成功 %d，略過 %d，失敗 %d", report.DigestKey, report.Sent, report.Skipped, report.Failed)
+		}
+	}()
+}
+
+// 發送單一訂閱者的電子報
+func (s *NewsletterService) sendDigest(sub *models.NewsletterSubscriber, digest *Digest, sendLog *models.NewsletterSendLog) error {
+	htmlBody, textBody, err := s.RenderDigest(digest, sub.UnsubscribeToken)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(MailMessage{
+		To:       sub.Email,
+		Subject:  sendLog.Subject,
+		HTMLBody: htmlBody,
+		TextBody: textBody,
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + s.unsubscribeURL(sub.UnsubscribeToken) + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	})
+}
+
+// 取得該期電子報的發送權，避免重複發送；失敗過的紀錄可重新發送
+func (s *NewsletterService) claimDigest(sub *models.NewsletterSubscriber, digest *Digest) (*models.NewsletterSendLog, bool) {
+	var existing models.NewsletterSendLog
+	err := s.db.Where("subscriber_id = ? AND kind = ? AND digest_key = ?", sub.ID, NewsletterKindDigest, digest.Key).
+		First(&existing).Error
+	if err == nil {
+		if existing.Status != "failed" {
+			return nil, false
+		}
+		result := s.db.Model(&existing).Where("status = ?", "failed").Update("status", "sending")
+		return &existing, result.RowsAffected == 1
+	}
+
+	sendLog := &models.NewsletterSendLog{
+		SubscriberID: sub.ID,
+		Email:        sub.Email,
+		LanguageCode: sub.LanguageCode,
+		Kind:         NewsletterKindDigest,
+		DigestKey:    digest.Key,
+		Subject:      locales.T(sub.LanguageCode, locales.MailDigestSubject, locales.Params{"site": s.config.SiteName, "digest": digest.Key}),
+		ArticleCount: len(digest.Articles),
+		Status:       "sending",
+	}
+	// 唯一索引衝突表示其他程序已開始發送
+	if err := s.db.Create(sendLog).Error; err != nil {
+		return nil, false
+	}
+	return sendLog, true
+}
+
+// 依發送結果更新紀錄
+func (s *NewsletterService) finishLog(sendLog *models.NewsletterSendLog, err error) {
+	if err != nil {
+		sendLog.Status = "failed"
+		sendLog.Error = err.Error()
+		return
+	}
+	now := time.Now()
+	sendLog.Status = "sent"
+	sendLog.Error = ""
+	sendLog.SentAt = &now
+}
+
+// 退訂連結
+func (s *NewsletterService) unsubscribeURL(token string) string {
+	return fmt.Sprintf("%s/api/v1/newsletter/unsubscribe?token=%s", strings.TrimRight(s.config.APIURL, "/"), token)
+}
+
+// 渲染 HTML 與純文字郵件
+func renderMail(htmlTmpl *htmltemplate.Template, textTmpl *texttemplate.Template, data interface{}) (string, string, error) {
+	var htmlBuf, textBuf bytes.Buffer
+	if err := htmlTmpl.Execute(&htmlBuf, data); err != nil {
+		return "", "", fmt.Errorf("渲染 HTML 郵件失敗: %w", err)
+	}
+	if err := textTmpl.Execute(&textBuf, data); err != nil {
+		return "", "", fmt.Errorf("渲染純文字郵件失敗: %w", err)
+	}
+	return htmlBuf.String(), textBuf.String(), nil
+}
+
+var confirmHTMLTemplate = htmltemplate.Must(htmltemplate.New("confirm").Parse(`<!DOCTYPE html>
+<html lang="{{.LanguageCode}}" dir="{{.Dir}}">
+<body style="font-family: sans-serif; color: #111;">
+  <h2>{{.SiteName}}</h2>
+  <p>{{.Intro}}</p>
+  <p><a href="{{.ConfirmURL}}">{{.Action}}</a></p>
+  <p style="color: #666; font-size: 12px;">{{.Ignore}}</p>
+</body>
+</html>`))
+
+var confirmTextTemplate = texttemplate.Must(texttemplate.New("confirm").Parse(`{{.SiteName}}
+
+{{.Intro}}
+{{.ConfirmURL}}
+
+{{.Ignore}}
+`))
+
+var digestHTMLTemplate = htmltemplate.Must(htmltemplate.New("digest").Parse(`<!DOCTYPE html>
+<html lang="{{.Digest.LanguageCode}}" dir="{{.Digest.Dir}}">
+<body style="font-family: sans-serif; color: #111; max-width: 640px; margin: 0 auto;">
+  <h1 style="font-size: 22px;">{{.SiteName}}</h1>
+  <p style="color: #666;">{{.Digest.Since.Format "2006-01-02"}} – {{.Digest.Until.Format "2006-01-02"}}</p>
+  {{range .Digest.Articles}}
+  <div style="border-top: 1px solid #000; padding: 12px 0;">
+    <h2 style="font-size: 18px; margin: 0 0 6px;"><a href="{{.URL}}" style="color: #111;">{{.Title}}</a></h2>
+    {{if .Excerpt}}<p style="margin: 0;">{{.Excerpt}}</p>{{end}}
+  </div>
+  {{end}}
+  <p style="color: #666; font-size: 12px;"><a href="{{.UnsubscribeURL}}">{{.Unsubscribe}}</a></p>
+</body>
+</html>`))
+
+var digestTextTemplate = texttemplate.Must(texttemplate.New("digest").Parse(`{{.SiteName}}
+{{.Digest.Since.Format "2006-01-02"}} – {{.Digest.Until.Format "2006-01-02"}}
+{{range .Digest.Articles}}
+* {{.Title}}
+  {{.URL}}
+{{if .Excerpt}}  {{.Excerpt}}
+{{end}}{{end}}
+{{.Unsubscribe}}: {{.UnsubscribeURL}}
+`))
+
+var unsubscribeHTMLTemplate = htmltemplate.Must(htmltemplate.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="{{.LanguageCode}}" dir="{{.Dir}}">
+<head><meta charset="utf-8"><meta name="robots" content="noindex"><title>{{.SiteName}}</title></head>
+<body style="font-family: sans-serif; color: #111; max-width: 480px; margin: 40px auto;">
+  <h2>{{.SiteName}}</h2>
+  {{if .Done}}
+  <p>{{.Result}}</p>
+  {{else}}
+  <p>{{.Prompt}}</p>
+  <form method="post" action="{{.UnsubscribeURL}}">
+    <button type="submit">{{.Action}}</button>
+  </form>
+  {{end}}
+</body>
+</html>`))
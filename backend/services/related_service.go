@@ -0,0 +1,364 @@
+package services
+
+import (
+	"GolangBlog/models"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 相關文章評分配置
+type RelatedConfig struct {
+	TagWeight       float64       // 共同標籤權重
+	CategoryWeight  float64       // 共同分類權重
+	TextWeight      float64       // 內文相似度權重
+	RecencyWeight   float64       // 新近程度權重
+	RecencyHalfLife time.Duration // 新近程度的半衰期
+	CandidateLimit  int           // 每種來源最多取出的候選文章數
+	CacheTTL        time.Duration // 快取有效時間
+}
+
+// 相關文章評分結果
+type RelatedArticle struct {
+	ArticleID uint    `json:"article_id"`
+	Score     float64 `json:"score"`
+	Tag       float64 `json:"tag"`
+	Category  float64 `json:"category"`
+	Text      float64 `json:"text"`
+	Recency   float64 `json:"recency"`
+}
+
+// 快取的推薦結果
+type relatedCacheEntry struct {
+	sourceID  uint
+	results   []RelatedArticle
+	expiresAt time.Time
+}
+
+// 用於評分的文章特徵
+type relatedFeatures struct {
+	tags        map[uint]bool
+	categories  map[uint]bool
+	terms       map[string]float64
+	publishedAt *time.Time
+}
+
+// 相關文章推薦服務
+type RelatedService struct {
+	config RelatedConfig
+	db     *gorm.DB
+	mu     sync.RWMutex
+	cache  map[string]*relatedCacheEntry
+	gen    uint64    // 每次失效時遞增，避免計算期間失效的結果被寫回快取
+	swept  time.Time // 上次清除過期快取的時間
+}
+
+// 新建相關文章推薦服務
+func NewRelatedService(config RelatedConfig, db *gorm.DB) *RelatedService {
+	if config.TagWeight == 0 && config.CategoryWeight == 0 && config.TextWeight == 0 && config.RecencyWeight == 0 {
+		config.TagWeight = 0.4
+		config.CategoryWeight = 0.2
+		config.TextWeight = 0.3
+		config.RecencyWeight = 0.1
+	}
+	if config.RecencyHalfLife <= 0 {
+		config.RecencyHalfLife = 30 * 24 * time.Hour
+	}
+	if config.CandidateLimit <= 0 {
+		config.CandidateLimit = 200
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = 15 * time.Minute
+	}
+
+	return &RelatedService{
+		config: config,
+		db:     db,
+		cache:  make(map[string]*relatedCacheEntry),
+	}
+}
+
+// 取得文章的相關文章（依分數由高至低）
+func (s *RelatedService) Related(articleID uint, langCode string, limit int) ([]RelatedArticle, error) {
+	key := fmt.Sprintf("%d:%s:%d", articleID, langCode, limit)
+
+	s.mu.RLock()
+	entry, ok := s.cache[key]
+	gen := s.gen
+	s.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.results, nil
+	}
+
+	results, err := s.compute(articleID, langCode, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	if s.gen == gen {
+		s.cache[key] = &relatedCacheEntry{
+			sourceID:  articleID,
+			results:   results,
+			expiresAt: now.Add(s.config.CacheTTL),
+		}
+	}
+	// 快取以文章、語言與數量為鍵，每個有效時間週期清除一次過期的項目，避免無限增長
+	if now.Sub(s.swept) >= s.config.CacheTTL {
+		s.swept = now
+		for key, entry := range s.cache {
+			if !now.Before(entry.expiresAt) {
+				delete(s.cache, key)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	return results, nil
+}
+
+// 使與指定文章有關的快取失效（作為來源或出現在推薦結果中）
+func (s *RelatedService) Invalidate(articleID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gen++
+	for key, entry := range s.cache {
+		if entry.sourceID == articleID {
+			delete(s.cache, key)
+			continue
+		}
+		for _, result := range entry.results {
+			if result.ArticleID == articleID {
+				delete(s.cache, key)
+				break
+			}
+		}
+	}
+}
+
+// 清除所有快取
+func (s *RelatedService) Clear() {
+	s.mu.Lock()
+	s.gen++
+	s.cache = make(map[string]*relatedCacheEntry)
+	s.mu.Unlock()
+}
+
+// 計算相關文章分數
+func (s *RelatedService) compute(articleID uint, langCode string, limit int) ([]RelatedArticle, error) {
+	source, err := s.loadFeatures([]uint{articleID}, langCode)
+	if err != nil {
+		return nil, err
+	}
+	sourceFeatures, ok := source[articleID]
+	if !ok {
+		return []RelatedArticle{}, nil
+	}
+
+	candidateIDs, err := s.candidates(articleID, sourceFeatures, langCode)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidateIDs) == 0 {
+		return []RelatedArticle{}, nil
+	}
+
+	candidates, err := s.loadFeatures(candidateIDs, langCode)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	results := make([]RelatedArticle, 0, len(candidates))
+	for id, features := range candidates {
+		result := RelatedArticle{
+			ArticleID: id,
+			Tag:       jaccard(sourceFeatures.tags, features.tags),
+			Category:  jaccard(sourceFeatures.categories, features.categories),
+			Text:      cosine(sourceFeatures.terms, features.terms),
+		}
+		if features.publishedAt != nil {
+			age := now.Sub(*features.publishedAt)
+			result.Recency = math.Exp(-math.Ln2 * math.Max(age.Hours(), 0) / s.config.RecencyHalfLife.Hours())
+		}
+
+		result.Score = s.config.TagWeight*result.Tag +
+			s.config.CategoryWeight*result.Category +
+			s.config.TextWeight*result.Text +
+			s.config.RecencyWeight*result.Recency
+
+		// 與來源文章完全無關聯的候選不列入
+		if result.Tag == 0 && result.Category == 0 && result.Text == 0 {
+			continue
+		}
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ArticleID > results[j].ArticleID
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+// 取出候選文章：共享標籤或分類的文章，以及最新發布的文章
+func (s *RelatedService) candidates(articleID uint, source *relatedFeatures, langCode string) ([]uint, error) {
+	published := func() *gorm.DB {
+		return s.db.Model(&models.Article{}).
+			Joins("JOIN article_translations ON article_translations.article_id = articles.id AND article_translations.language_code = ? AND article_translations.deleted_at IS NULL AND "+ReviewedTranslationFilter("article_translations"), langCode).
+			Where("articles.status = ? AND articles.id <> ?", "published", articleID)
+	}
+
+	seen := make(map[uint]bool)
+	var ids []uint
+	collect := func(query *gorm.DB) error {
+		var found []uint
+		if err := query.Order("articles.published_at DESC").Limit(s.config.CandidateLimit).Pluck("articles.id", &found).Error; err != nil {
+			return err
+		}
+		for _, id := range found {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		return nil
+	}
+
+	if len(source.tags) > 0 {
+		query := published().Where("articles.id IN (?)",
+			s.db.Model(&models.ArticleTag{}).Select("article_id").Where("tag_id IN ?", setKeys(source.tags)))
+		if err := collect(query); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(source.categories) > 0 {
+		query := published().Where("articles.id IN (?)",
+			s.db.Model(&models.ArticleCategory{}).Select("article_id").Where("category_id IN ?", setKeys(source.categories)))
+		if err := collect(query); err != nil {
+			return nil, err
+		}
+	}
+
+	// 沒有共同分類的文章仍可能內容相近
+	if err := collect(published()); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// 載入文章的標籤、分類與指定語言的內文詞頻
+func (s *RelatedService) loadFeatures(ids []uint, langCode string) (map[uint]*relatedFeatures, error) {
+	var articles []models.Article
+	if err := s.db.Select("id", "published_at").Where("id IN ?", ids).Find(&articles).Error; err != nil {
+		return nil, err
+	}
+
+	var translations []models.ArticleTranslation
+	if err := s.db.Select("article_id", "title", "excerpt", "content").
+		Where("article_id IN ? AND language_code = ?", ids, langCode).
+		Where(ReviewedTranslationFilter("")).
+		Find(&translations).Error; err != nil {
+		return nil, err
+	}
+
+	features := make(map[uint]*relatedFeatures)
+	for _, article := range articles {
+		features[article.ID] = &relatedFeatures{
+			tags:        make(map[uint]bool),
+			categories:  make(map[uint]bool),
+			terms:       make(map[string]float64),
+			publishedAt: article.PublishedAt,
+		}
+	}
+
+	for _, trans := range translations {
+		f, ok := features[trans.ArticleID]
+		if !ok {
+			continue
+		}
+		// 標題與摘要的詞彙加重計算
+		for _, token := range tokenize(trans.Title + " " + trans.Excerpt) {
+			f.terms[token] += 2
+		}
+		for _, token := range tokenize(trans.Content) {
+			f.terms[token]++
+		}
+	}
+
+	var articleTags []models.ArticleTag
+	if err := s.db.Where("article_id IN ?", ids).Find(&articleTags).Error; err != nil {
+		return nil, err
+	}
+	for _, at := range articleTags {
+		if f, ok := features[at.ArticleID]; ok {
+			f.tags[at.TagID] = true
+		}
+	}
+
+	var articleCategories []models.ArticleCategory
+	if err := s.db.Where("article_id IN ?", ids).Find(&articleCategories).Error; err != nil {
+		return nil, err
+	}
+	for _, ac := range articleCategories {
+		if f, ok := features[ac.ArticleID]; ok {
+			f.categories[ac.CategoryID] = true
+		}
+	}
+
+	return features, nil
+}
+
+// 集合的 Jaccard 相似度
+func jaccard(a, b map[uint]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for id := range a {
+		if b[id] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// 詞頻向量的餘弦相似度
+func cosine(a, b map[string]float64) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for term, weight := range a {
+		normA += weight * weight
+		dot += weight * b[term]
+	}
+	for _, weight := range b {
+		normB += weight * weight
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// 取出集合中的所有鍵
+func setKeys(set map[uint]bool) []uint {
+	result := make([]uint, 0, len(set))
+	for id := range set {
+		result = append(result, id)
+	}
+	return result
+}
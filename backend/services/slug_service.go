@@ -0,0 +1,120 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// 預設的保留 slug：與前台路由或常用頁面相同，避免文章、標籤或分類佔用
+var DefaultReservedSlugs = []string{
+	"admin", "api", "new", "edit", "search", "feed", "rss", "atom", "sitemap",
+	"login", "logout", "register", "articles", "tags", "categories", "series",
+	"trending", "featured", "latest", "preview",
+}
+
+// slug 無法使用的原因
+const (
+	SlugTaken    = "taken"    // 同語言已有其他項目使用
+	SlugReserved = "reserved" // 保留 slug
+	SlugInvalid  = "invalid"  // 空白或包含路徑分隔字元
+)
+
+// Slug 服務配置
+type SlugConfig struct {
+	Reserved []string // 保留 slug（不分大小寫）
+}
+
+// slug 衝突資訊
+type SlugConflict struct {
+	Slug       string `json:"slug"`
+	Reason     string `json:"reason"`                // taken、reserved 或 invalid
+	ConflictID uint   `json:"conflict_id,omitempty"` // 已使用此 slug 的項目 ID
+	Suggestion string `json:"suggestion,omitempty"`  // 建議使用的 slug
+}
+
+// Slug 服務：檢查同語言內的 slug 是否重複或為保留字，並產生不重複的 slug
+type SlugService struct {
+	reserved map[string]bool
+}
+
+// 新建 Slug 服務
+func NewSlugService(config SlugConfig) *SlugService {
+	if config.Reserved == nil {
+		config.Reserved = DefaultReservedSlugs
+	}
+
+	reserved := make(map[string]bool, len(config.Reserved))
+	for _, value := range config.Reserved {
+		if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+			reserved[value] = true
+		}
+	}
+	return &SlugService{reserved: reserved}
+}
+
+// 是否為保留 slug
+func (s *SlugService) IsReserved(slug string) bool {
+	return s.reserved[strings.ToLower(slug)]
+}
+
+// 檢查 slug 是否可供項目使用（同一項目原本的 slug 不算衝突）；
+// 已刪除的翻譯仍受唯一索引限制，因此一併檢查。可使用時回傳 nil
+func (s *SlugService) Check(db *gorm.DB, table, ownerColumn string, ownerID uint, langCode, slug string) (*SlugConflict, error) {
+	if strings.TrimSpace(slug) == "" || strings.ContainsAny(slug, "/?#") {
+		return &SlugConflict{Slug: slug, Reason: SlugInvalid}, nil
+	}
+
+	conflict := &SlugConflict{Slug: slug}
+	if s.IsReserved(slug) {
+		conflict.Reason = SlugReserved
+	} else {
+		var owners []uint
+		if err := db.Table(table).
+			Where("slug = ? AND language_code = ? AND "+ownerColumn+" <> ?", slug, langCode, ownerID).
+			Limit(1).Pluck(ownerColumn, &owners).Error; err != nil {
+			return nil, err
+		}
+		if len(owners) == 0 {
+			return nil, nil
+		}
+		conflict.Reason = SlugTaken
+		conflict.ConflictID = owners[0]
+	}
+
+	suggestion, err := s.Unique(db, table, ownerColumn, ownerID, langCode, slug)
+	if err != nil {
+		return nil, err
+	}
+	conflict.Suggestion = suggestion
+	return conflict, nil
+}
+
+// 產生同語言內不重複且非保留字的 slug：base 已被使用時依序加上 -2、-3…
+func (s *SlugService) Unique(db *gorm.DB, table, ownerColumn string, ownerID uint, langCode, base string) (string, error) {
+	base = strings.Trim(strings.NewReplacer("/", "-", "?", "-", "#", "-").Replace(strings.TrimSpace(base)), "-")
+	if base == "" {
+		base = strconv.FormatUint(uint64(ownerID), 10)
+	}
+
+	// 一次取出所有可能衝突的 slug
+	pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(base) + "-%"
+	var existing []string
+	if err := db.Table(table).
+		Where("(slug = ? OR slug LIKE ?) AND language_code = ? AND "+ownerColumn+" <> ?", base, pattern, langCode, ownerID).
+		Pluck("slug", &existing).Error; err != nil {
+		return "", err
+	}
+	taken := make(map[string]bool, len(existing))
+	for _, value := range existing {
+		taken[value] = true
+	}
+
+	candidate := base
+	for i := 2; taken[candidate] || s.IsReserved(candidate); i++ {
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+	return candidate, nil
+}
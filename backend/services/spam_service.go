@@ -0,0 +1,489 @@
+package services
+
+import (
+	"GolangBlog/models"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 垃圾訊息判定結果
+const (
+	SpamVerdictHam     = "ham"     // 正常內容
+	SpamVerdictSuspect = "suspect" // 可疑內容，需人工審核
+	SpamVerdictSpam    = "spam"    // 垃圾訊息
+)
+
+// 垃圾訊息檢查對象類型
+const (
+	SpamKindComment  = "comment"  // 留言
+	SpamKindRegister = "register" // 註冊
+)
+
+// 待檢查的使用者提交內容
+type SpamSubmission struct {
+	Kind        string
+	Content     string
+	AuthorName  string
+	AuthorEmail string
+	IP          string
+	UserAgent   string
+	Honeypot    string    // 蜜罐欄位，正常使用者看不到也不會填寫
+	RenderedAt  time.Time // 表單顯示時間，用於判斷提交速度
+	SubmittedAt time.Time
+}
+
+// 單一規則的評分結果
+type SpamSignal struct {
+	Rule   string  `json:"rule"`
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+// 垃圾訊息檢查結果
+type SpamResult struct {
+	Score   float64      `json:"score"`
+	Verdict string       `json:"verdict"`
+	Signals []SpamSignal `json:"signals"`
+}
+
+// 垃圾訊息評分規則，回傳分數與原因（分數為 0 表示未命中）
+type SpamRule interface {
+	Name() string
+	Check(sub *SpamSubmission) (float64, string)
+}
+
+// 垃圾訊息過濾配置
+type SpamConfig struct {
+	SuspectThreshold  float64       // 達到此分數視為可疑
+	SpamThreshold     float64       // 達到此分數視為垃圾訊息
+	MaxLinks          int           // 允許的最大連結數
+	BlockedWords      []string      // 封鎖字詞
+	BlockedDomains    []string      // 封鎖網域
+	MinSubmitDuration time.Duration // 表單顯示到提交的最短時間
+	VelocityLimit     int           // 單一 IP 在時間窗內允許的提交次數
+	VelocityWindow    time.Duration // IP 提交次數的統計時間窗
+	BayesMinDocs      int           // 貝氏分類器啟用前每類至少需要的訓練樣本數
+}
+
+// 垃圾訊息過濾服務
+type SpamService struct {
+	config SpamConfig
+	rules  []SpamRule
+	bayes  *BayesClassifier
+	mu     sync.RWMutex
+}
+
+// 新建垃圾訊息過濾服務，並註冊預設規則
+func NewSpamService(config SpamConfig, db *gorm.DB) *SpamService {
+	if config.SuspectThreshold <= 0 {
+		config.SuspectThreshold = 0.5
+	}
+	if config.SpamThreshold <= 0 {
+		config.SpamThreshold = 1.0
+	}
+	if config.BayesMinDocs <= 0 {
+		config.BayesMinDocs = 10
+	}
+
+	service := &SpamService{
+		config: config,
+		bayes:  &BayesClassifier{db: db, minDocs: config.BayesMinDocs},
+	}
+
+	service.AddRule(&HoneypotRule{})
+	service.AddRule(&SubmitSpeedRule{MinDuration: config.MinSubmitDuration})
+	service.AddRule(&LinkCountRule{MaxLinks: config.MaxLinks})
+	service.AddRule(NewBlocklistRule(config.BlockedWords, config.BlockedDomains))
+	service.AddRule(NewIPVelocityRule(config.VelocityLimit, config.VelocityWindow))
+	service.AddRule(service.bayes)
+
+	return service
+}
+
+// 註冊額外的評分規則
+func (s *SpamService) AddRule(rule SpamRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = append(s.rules, rule)
+}
+
+// 依序執行所有規則並計算總分與判定結果
+func (s *SpamService) Check(sub *SpamSubmission) SpamResult {
+	if sub.SubmittedAt.IsZero() {
+		sub.SubmittedAt = time.Now()
+	}
+
+	s.mu.RLock()
+	rules := s.rules
+	s.mu.RUnlock()
+
+	result := SpamResult{Verdict: SpamVerdictHam, Signals: []SpamSignal{}}
+	for _, rule := range rules {
+		score, reason := rule.Check(sub)
+		if score <= 0 {
+			continue
+		}
+		result.Score += score
+		result.Signals = append(result.Signals, SpamSignal{Rule: rule.Name(), Score: score, Reason: reason})
+	}
+
+	if result.Score >= s.config.SpamThreshold {
+		result.Verdict = SpamVerdictSpam
+	} else if result.Score >= s.config.SuspectThreshold {
+		result.Verdict = SpamVerdictSuspect
+	}
+
+	return result
+}
+
+// 在指定事務中以審核結果訓練貝氏分類器，delta 為 -1 時撤銷先前的訓練
+func (s *SpamService) Train(tx *gorm.DB, text string, isSpam bool, delta int) error {
+	return s.bayes.Train(tx, text, isSpam, delta)
+}
+
+// 蜜罐欄位規則：隱藏欄位被填寫即判定為機器人
+type HoneypotRule struct{}
+
+func (r *HoneypotRule) Name() string { return "honeypot" }
+
+func (r *HoneypotRule) Check(sub *SpamSubmission) (float64, string) {
+	if strings.TrimSpace(sub.Honeypot) != "" {
+		return 1.0, "蜜罐欄位被填寫"
+	}
+	return 0, ""
+}
+
+// 提交速度規則：表單顯示後過快提交
+type SubmitSpeedRule struct {
+	MinDuration time.Duration
+}
+
+func (r *SubmitSpeedRule) Name() string { return "submit_speed" }
+
+func (r *SubmitSpeedRule) Check(sub *SpamSubmission) (float64, string) {
+	if r.MinDuration <= 0 || sub.RenderedAt.IsZero() {
+		return 0, ""
+	}
+	elapsed := sub.SubmittedAt.Sub(sub.RenderedAt)
+	if elapsed < 0 {
+		return 0.6, "表單時間戳無效"
+	}
+	if elapsed < r.MinDuration {
+		return 0.6, fmt.Sprintf("提交過快（%.1f 秒）", elapsed.Seconds())
+	}
+	return 0, ""
+}
+
+var linkPattern = regexp.MustCompile(`(?i)(?:https?://|www\.)[^\s<>"']+`)
+
+// 連結數量規則：超出上限的每個連結都會加分
+type LinkCountRule struct {
+	MaxLinks int
+}
+
+func (r *LinkCountRule) Name() string { return "link_count" }
+
+func (r *LinkCountRule) Check(sub *SpamSubmission) (float64, string) {
+	count := len(linkPattern.FindAllString(sub.Content, -1))
+	if count <= r.MaxLinks {
+		return 0, ""
+	}
+	score := math.Min(0.3*float64(count-r.MaxLinks), 1.0)
+	return score, fmt.Sprintf("包含 %d 個連結", count)
+}
+
+// 封鎖清單規則：比對封鎖字詞與連結、電子郵件的網域
+type BlocklistRule struct {
+	words   []string
+	domains []string
+}
+
+// 新建封鎖清單規則
+func NewBlocklistRule(words, domains []string) *BlocklistRule {
+	rule := &BlocklistRule{}
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			rule.words = append(rule.words, w)
+		}
+	}
+	for _, d := range domains {
+		if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+			rule.domains = append(rule.domains, strings.TrimPrefix(d, "."))
+		}
+	}
+	return rule
+}
+
+func (r *BlocklistRule) Name() string { return "blocklist" }
+
+func (r *BlocklistRule) Check(sub *SpamSubmission) (float64, string) {
+	text := strings.ToLower(sub.Content + " " + sub.AuthorName)
+
+	var hits []string
+	for _, w := range r.words {
+		if strings.Contains(text, w) {
+			hits = append(hits, w)
+		}
+	}
+	score := math.Min(0.5*float64(len(hits)), 1.0)
+
+	// 收集內容中的連結網域與電子郵件網域
+	var hosts []string
+	for _, link := range linkPattern.FindAllString(sub.Content, -1) {
+		if !strings.Contains(link, "://") {
+			link = "http://" + link
+		}
+		if u, err := url.Parse(link); err == nil && u.Hostname() != "" {
+			hosts = append(hosts, strings.ToLower(u.Hostname()))
+		}
+	}
+	if at := strings.LastIndex(sub.AuthorEmail, "@"); at >= 0 {
+		hosts = append(hosts, strings.ToLower(sub.AuthorEmail[at+1:]))
+	}
+
+	for _, host := range hosts {
+		for _, d := range r.domains {
+			if host == d || strings.HasSuffix(host, "."+d) {
+				hits = append(hits, d)
+				score = math.Max(score, 1.0)
+			}
+		}
+	}
+
+	if len(hits) == 0 {
+		return 0, ""
+	}
+	return score, "命中封鎖清單: " + strings.Join(hits, ", ")
+}
+
+// IP 提交頻率規則：在時間窗內同一 IP 提交過多次
+type IPVelocityRule struct {
+	limit  int
+	window time.Duration
+	hits   map[string][]time.Time
+	mu     sync.Mutex
+}
+
+// 新建 IP 提交頻率規則
+func NewIPVelocityRule(limit int, window time.Duration) *IPVelocityRule {
+	return &IPVelocityRule{
+		limit:  limit,
+		window: window,
+		hits:   make(map[string][]time.Time),
+	}
+}
+
+func (r *IPVelocityRule) Name() string { return "ip_velocity" }
+
+func (r *IPVelocityRule) Check(sub *SpamSubmission) (float64, string) {
+	if r.limit <= 0 || r.window <= 0 || sub.IP == "" {
+		return 0, ""
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 清除時間窗外的紀錄
+	cutoff := sub.SubmittedAt.Add(-r.window)
+	recent := r.hits[sub.IP][:0]
+	for _, t := range r.hits[sub.IP] {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	recent = append(recent, sub.SubmittedAt)
+	r.hits[sub.IP] = recent
+
+	// 避免長時間運行後記憶體無限增長
+	if len(r.hits) > 10000 {
+		for ip, times := range r.hits {
+			if len(times) == 0 || !times[len(times)-1].After(cutoff) {
+				delete(r.hits, ip)
+			}
+		}
+	}
+
+	if len(recent) > r.limit {
+		return 0.7, fmt.Sprintf("同一 IP 於 %s 內提交 %d 次", r.window, len(recent))
+	}
+	return 0, ""
+}
+
+// 貝氏分類器，以審核人員的判定結果訓練
+type BayesClassifier struct {
+	db      *gorm.DB
+	minDocs int
+}
+
+func (b *BayesClassifier) Name() string { return "bayes" }
+
+// 計算內容為垃圾訊息的機率並轉換為分數（機率高於 0.5 才計分）
+func (b *BayesClassifier) Check(sub *SpamSubmission) (float64, string) {
+	prob, ok := b.Probability(sub.Content + " " + sub.AuthorName)
+	if !ok || prob <= 0.5 {
+		return 0, ""
+	}
+	return (prob - 0.5) * 2, fmt.Sprintf("貝氏機率 %.2f", prob)
+}
+
+// 計算垃圾訊息機率，訓練樣本不足時回傳 false
+func (b *BayesClassifier) Probability(text string) (float64, bool) {
+	if b.db == nil {
+		return 0.5, false
+	}
+
+	var spamDocs, hamDocs int64
+	b.db.Model(&models.SpamLog{}).Where("decision = ?", SpamVerdictSpam).Count(&spamDocs)
+	b.db.Model(&models.SpamLog{}).Where("decision = ?", SpamVerdictHam).Count(&hamDocs)
+	if spamDocs < int64(b.minDocs) || hamDocs < int64(b.minDocs) {
+		return 0.5, false
+	}
+
+	tokens := uniqueTokens(text)
+	if len(tokens) == 0 {
+		return 0.5, false
+	}
+
+	var stats []models.SpamToken
+	if err := b.db.Where("token IN ?", tokens).Find(&stats).Error; err != nil || len(stats) == 0 {
+		return 0.5, false
+	}
+
+	// Robinson 平滑：出現次數少的詞彙機率趨近 0.5
+	const strength, assumed = 1.0, 0.5
+	probs := make([]float64, 0, len(stats))
+	for _, st := range stats {
+		spamFreq := float64(st.SpamCount) / float64(spamDocs)
+		hamFreq := float64(st.HamCount) / float64(hamDocs)
+		if spamFreq+hamFreq == 0 {
+			continue
+		}
+		n := float64(st.SpamCount + st.HamCount)
+		p := spamFreq / (spamFreq + hamFreq)
+		p = (strength*assumed + n*p) / (strength + n)
+		probs = append(probs, math.Min(math.Max(p, 0.01), 0.99))
+	}
+	if len(probs) == 0 {
+		return 0.5, false
+	}
+
+	// 只取最具鑑別度的詞彙
+	sort.Slice(probs, func(i, j int) bool {
+		return math.Abs(probs[i]-0.5) > math.Abs(probs[j]-0.5)
+	})
+	if len(probs) > 15 {
+		probs = probs[:15]
+	}
+
+	eta := 0.0
+	for _, p := range probs {
+		eta += math.Log(1-p) - math.Log(p)
+	}
+	return 1 / (1 + math.Exp(eta)), true
+}
+
+// 在指定事務中將內容的詞彙加入（或以 delta = -1 移出）指定類別的統計
+func (b *BayesClassifier) Train(tx *gorm.DB, text string, isSpam bool, delta int) error {
+	if b.db == nil {
+		return nil
+	}
+
+	column := "ham_count"
+	if isSpam {
+		column = "spam_count"
+	}
+
+	for _, token := range uniqueTokens(text) {
+		row := models.SpamToken{Token: token}
+		if isSpam {
+			row.SpamCount = max(delta, 0)
+		} else {
+			row.HamCount = max(delta, 0)
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "token"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				column: gorm.Expr(fmt.Sprintf("GREATEST(spam_tokens.%s + ?, 0)", column), delta),
+			}),
+		}).Create(&row).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 將文字切分為不重複的詞彙：拉丁文字以單字切分，中日韓文字以二元組切分
+func uniqueTokens(text string) []string {
+	seen := make(map[string]bool)
+	var tokens []string
+	for _, token := range tokenize(text) {
+		if !seen[token] {
+			seen[token] = true
+			tokens = append(tokens, token)
+		}
+		if len(tokens) >= 300 {
+			break
+		}
+	}
+	return tokens
+}
+
+// 切分文字為詞彙序列
+func tokenize(text string) []string {
+	var tokens []string
+	var word []rune
+	var cjk []rune
+
+	flushWord := func() {
+		if len(word) >= 2 && len(word) <= 40 {
+			tokens = append(tokens, string(word))
+		}
+		word = word[:0]
+	}
+	flushCJK := func() {
+		if len(cjk) == 1 {
+			tokens = append(tokens, string(cjk))
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			tokens = append(tokens, string(cjk[i:i+2]))
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+
+	return tokens
+}
+
+// 判斷是否為中日韓文字
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
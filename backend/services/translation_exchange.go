@@ -0,0 +1,350 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 交換檔格式
+const (
+	XLIFFFormat = "xliff" // XLIFF 2.0
+	POFormat    = "po"    // gettext PO
+)
+
+// 交換檔註記中記錄內容雜湊的類別，匯入時用來偵測匯出後的修改
+const (
+	sourceHashNote = "golangblog:source-hash"
+	targetHashNote = "golangblog:target-hash"
+)
+
+// 單一翻譯單位（一個欄位）
+type TranslationUnit struct {
+	ID         string // 單位代碼，例如 article:12:title
+	Note       string // 給譯者的說明
+	Source     string
+	Target     string
+	SourceHash string // 匯出時來源內容的雜湊
+	TargetHash string // 匯出時目標語言內容的雜湊（尚無翻譯時為空內容的雜湊）
+	Fuzzy      bool   // 譯者標記為待確認（PO 的 fuzzy 旗標），匯入時略過
+}
+
+// 翻譯交換檔內容
+type TranslationBundle struct {
+	SourceLanguage string
+	TargetLanguage string
+	Units          []TranslationUnit
+}
+
+// 計算內容雜湊（取前 16 碼）
+func ContentHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// 依格式輸出交換檔
+func EncodeTranslationBundle(format string, bundle *TranslationBundle) ([]byte, error) {
+	switch format {
+	case XLIFFFormat:
+		return EncodeXLIFF(bundle)
+	case POFormat:
+		return EncodePO(bundle), nil
+	}
+	return nil, errors.New("不支援的交換檔格式")
+}
+
+// 解析交換檔，依內容判斷格式
+func DecodeTranslationBundle(data []byte) (*TranslationBundle, string, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		bundle, err := DecodeXLIFF(trimmed)
+		return bundle, XLIFFFormat, err
+	}
+	bundle, err := DecodePO(trimmed)
+	return bundle, POFormat, err
+}
+
+// XLIFF 2.0 結構
+type xliffDocument struct {
+	XMLName xml.Name    `xml:"urn:oasis:names:tc:xliff:document:2.0 xliff"`
+	Version string      `xml:"version,attr"`
+	SrcLang string      `xml:"srcLang,attr"`
+	TrgLang string      `xml:"trgLang,attr,omitempty"`
+	Files   []xliffFile `xml:"file"`
+}
+
+type xliffFile struct {
+	ID    string      `xml:"id,attr"`
+	Units []xliffUnit `xml:"unit"`
+}
+
+type xliffUnit struct {
+	ID       string         `xml:"id,attr"`
+	Notes    *xliffNotes    `xml:"notes"`
+	Segments []xliffSegment `xml:"segment"`
+}
+
+type xliffNotes struct {
+	Notes []xliffNote `xml:"note"`
+}
+
+type xliffNote struct {
+	Category string `xml:"category,attr,omitempty"`
+	Text     string `xml:",chardata"`
+}
+
+type xliffSegment struct {
+	Source string  `xml:"source"`
+	Target *string `xml:"target"`
+}
+
+// 輸出 XLIFF 2.0；同一項目的欄位放在同一個 file 中
+func EncodeXLIFF(bundle *TranslationBundle) ([]byte, error) {
+	doc := xliffDocument{Version: "2.0", SrcLang: bundle.SourceLanguage, TrgLang: bundle.TargetLanguage}
+
+	fileIndex := map[string]int{}
+	for _, unit := range bundle.Units {
+		fileID := unit.ID
+		if i := strings.LastIndex(fileID, ":"); i > 0 {
+			fileID = fileID[:i]
+		}
+		fileID = strings.ReplaceAll(fileID, ":", "-")
+
+		idx, ok := fileIndex[fileID]
+		if !ok {
+			idx = len(doc.Files)
+			fileIndex[fileID] = idx
+			doc.Files = append(doc.Files, xliffFile{ID: fileID})
+		}
+
+		notes := &xliffNotes{Notes: []xliffNote{
+			{Category: sourceHashNote, Text: unit.SourceHash},
+			{Category: targetHashNote, Text: unit.TargetHash},
+		}}
+		if unit.Note != "" {
+			notes.Notes = append([]xliffNote{{Text: unit.Note}}, notes.Notes...)
+		}
+
+		target := unit.Target
+		doc.Files[idx].Units = append(doc.Files[idx].Units, xliffUnit{
+			ID:       unit.ID,
+			Notes:    notes,
+			Segments: []xliffSegment{{Source: unit.Source, Target: &target}},
+		})
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// 解析 XLIFF 2.0；單位內的多個段落會依序合併
+func DecodeXLIFF(data []byte) (*TranslationBundle, error) {
+	var doc xliffDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析 XLIFF 失敗: %w", err)
+	}
+	if !strings.HasPrefix(doc.Version, "2.") {
+		return nil, fmt.Errorf("不支援的 XLIFF 版本: %s", doc.Version)
+	}
+
+	bundle := &TranslationBundle{SourceLanguage: doc.SrcLang, TargetLanguage: doc.TrgLang}
+	for _, file := range doc.Files {
+		for _, unit := range file.Units {
+			item := TranslationUnit{ID: unit.ID}
+			for _, segment := range unit.Segments {
+				item.Source += segment.Source
+				if segment.Target != nil {
+					item.Target += *segment.Target
+				}
+			}
+			if unit.Notes != nil {
+				for _, note := range unit.Notes.Notes {
+					switch note.Category {
+					case sourceHashNote:
+						item.SourceHash = strings.TrimSpace(note.Text)
+					case targetHashNote:
+						item.TargetHash = strings.TrimSpace(note.Text)
+					default:
+						item.Note = note.Text
+					}
+				}
+			}
+			bundle.Units = append(bundle.Units, item)
+		}
+	}
+	return bundle, nil
+}
+
+// 輸出 gettext PO；以 msgctxt 記錄單位代碼，雜湊記錄於註解
+func EncodePO(bundle *TranslationBundle) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("msgid \"\"\nmsgstr \"\"\n")
+	buf.WriteString("\"Content-Type: text/plain; charset=UTF-8\\n\"\n")
+	buf.WriteString("\"Content-Transfer-Encoding: 8bit\\n\"\n")
+	fmt.Fprintf(&buf, "\"Language: %s\\n\"\n", poEscape(bundle.TargetLanguage))
+	fmt.Fprintf(&buf, "\"X-Source-Language: %s\\n\"\n", poEscape(bundle.SourceLanguage))
+
+	for _, unit := range bundle.Units {
+		buf.WriteString("\n")
+		if unit.Note != "" {
+			for _, line := range strings.Split(unit.Note, "\n") {
+				fmt.Fprintf(&buf, "#. %s\n", line)
+			}
+		}
+		fmt.Fprintf(&buf, "#. %s: %s\n", sourceHashNote, unit.SourceHash)
+		fmt.Fprintf(&buf, "#. %s: %s\n", targetHashNote, unit.TargetHash)
+		writePOString(&buf, "msgctxt", unit.ID)
+		writePOString(&buf, "msgid", unit.Source)
+		writePOString(&buf, "msgstr", unit.Target)
+	}
+	return buf.Bytes()
+}
+
+// 解析 gettext PO
+func DecodePO(data []byte) (*TranslationBundle, error) {
+	bundle := &TranslationBundle{}
+
+	var entry TranslationUnit
+	var comments []string
+	var field *string
+	seenMsgstr := false
+
+	// 完成一筆項目；沒有 msgctxt 且 msgid 為空的項目是檔頭
+	flush := func() {
+		if entry.ID == "" && entry.Source == "" {
+			for _, line := range strings.Split(entry.Target, "\n") {
+				key, value, _ := strings.Cut(line, ":")
+				switch strings.TrimSpace(key) {
+				case "Language":
+					bundle.TargetLanguage = strings.TrimSpace(value)
+				case "X-Source-Language":
+					bundle.SourceLanguage = strings.TrimSpace(value)
+				}
+			}
+		} else {
+			var notes []string
+			for _, comment := range comments {
+				if value, ok := strings.CutPrefix(comment, sourceHashNote+":"); ok {
+					entry.SourceHash = strings.TrimSpace(value)
+				} else if value, ok := strings.CutPrefix(comment, targetHashNote+":"); ok {
+					entry.TargetHash = strings.TrimSpace(value)
+				} else {
+					notes = append(notes, comment)
+				}
+			}
+			entry.Note = strings.Join(notes, "\n")
+			bundle.Units = append(bundle.Units, entry)
+		}
+		entry = TranslationUnit{}
+		comments = nil
+		field = nil
+		seenMsgstr = false
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#"):
+			// 註解出現在 msgstr 之後代表下一筆項目開始
+			if seenMsgstr {
+				flush()
+			}
+			if strings.HasPrefix(line, "#.") {
+				comments = append(comments, strings.TrimSpace(line[2:]))
+			}
+			// 旗標註解，例如 "#, fuzzy, c-format"
+			if strings.HasPrefix(line, "#,") {
+				for _, flag := range strings.Split(line[2:], ",") {
+					if strings.TrimSpace(flag) == "fuzzy" {
+						entry.Fuzzy = true
+					}
+				}
+			}
+		case strings.HasPrefix(line, `"`):
+			if field == nil {
+				return nil, fmt.Errorf("PO 第 %d 行格式錯誤", lineNo)
+			}
+			value, err := poUnquote(line)
+			if err != nil {
+				return nil, fmt.Errorf("PO 第 %d 行格式錯誤: %w", lineNo, err)
+			}
+			*field += value
+		default:
+			keyword, rest, _ := strings.Cut(line, " ")
+			value, err := poUnquote(strings.TrimSpace(rest))
+			if err != nil {
+				return nil, fmt.Errorf("PO 第 %d 行格式錯誤: %w", lineNo, err)
+			}
+
+			if keyword != "msgstr" && seenMsgstr {
+				flush()
+			}
+
+			switch keyword {
+			case "msgctxt":
+				field = &entry.ID
+			case "msgid":
+				field = &entry.Source
+			case "msgstr":
+				field = &entry.Target
+				seenMsgstr = true
+			default:
+				return nil, fmt.Errorf("PO 第 %d 行包含不支援的關鍵字 %s", lineNo, keyword)
+			}
+			*field = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if seenMsgstr {
+		flush()
+	}
+
+	return bundle, nil
+}
+
+// 以 PO 格式輸出字串；多行內容拆成多個字串
+func writePOString(buf *bytes.Buffer, keyword, value string) {
+	if !strings.Contains(value, "\n") || value == "\n" {
+		fmt.Fprintf(buf, "%s \"%s\"\n", keyword, poEscape(value))
+		return
+	}
+
+	fmt.Fprintf(buf, "%s \"\"\n", keyword)
+	lines := strings.SplitAfter(value, "\n")
+	for _, line := range lines {
+		if line != "" {
+			fmt.Fprintf(buf, "\"%s\"\n", poEscape(line))
+		}
+	}
+}
+
+// 跳脫 PO 字串
+func poEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`).Replace(value)
+}
+
+// 解析 PO 的引號字串
+func poUnquote(value string) (string, error) {
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return "", errors.New("缺少引號")
+	}
+	return strconv.Unquote(value)
+}
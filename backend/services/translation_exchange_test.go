@@ -0,0 +1,189 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 往返測試用的翻譯單位：涵蓋多行內容、跳脫字元、空白與非 ASCII 文字
+// （XLIFF 會將同一項目的單位放在同一個 file 中，因此同一項目的單位需相鄰）
+var exchangeRoundTripCases = []struct {
+	name string
+	unit TranslationUnit
+}{
+	{"單行", TranslationUnit{ID: "article:1:title", Source: "Hello", Target: "你好"}},
+	{"空白譯文", TranslationUnit{ID: "article:1:excerpt", Source: "Summary", Target: ""}},
+	{"多行", TranslationUnit{ID: "article:2:content", Source: "first\nsecond\n\nthird", Target: "第一\n第二\n\n第三"}},
+	{"結尾換行", TranslationUnit{ID: "article:2:content", Source: "line\n", Target: "行\n"}},
+	{"Windows 換行", TranslationUnit{ID: "article:2:excerpt", Source: "a\r\nb", Target: "甲\r\n乙"}},
+	{"只有換行", TranslationUnit{ID: "tag:3:name", Source: "\n", Target: "\n"}},
+	{"引號與反斜線", TranslationUnit{ID: "category:4:name", Source: `say "hi" \ path\to`, Target: `說「hi」 "x" \n`}},
+	{"定位字元", TranslationUnit{ID: "category:4:description", Source: "a\tb", Target: "甲\t乙"}},
+	{"前後空白", TranslationUnit{ID: "setting:5:value", Source: "  padded  ", Target: " 留白 "}},
+	{"XML 特殊字元", TranslationUnit{ID: "article:6:content", Source: "<b>bold</b> & <i>it</i>", Target: "<b>粗體</b> & <i>斜體</i>"}},
+	{"從右至左", TranslationUnit{ID: "article:7:title", Source: "Welcome", Target: "مرحبا بكم"}},
+	{"說明", TranslationUnit{ID: "article:8:meta_title", Note: "Max 60 characters", Source: "SEO", Target: "搜尋"}},
+	{"多行說明", TranslationUnit{ID: "article:8:meta_description", Note: "line one\nline two", Source: "Desc", Target: "描述"}},
+	{"雜湊", TranslationUnit{ID: "article:9:title", Source: "Hash", Target: "雜湊", SourceHash: ContentHash("Hash"), TargetHash: ContentHash("")}},
+}
+
+func TestTranslationBundleRoundTrip(t *testing.T) {
+	for _, format := range []string{XLIFFFormat, POFormat} {
+		for _, tc := range exchangeRoundTripCases {
+			t.Run(format+"/"+tc.name, func(t *testing.T) {
+				bundle := &TranslationBundle{SourceLanguage: "en", TargetLanguage: "zh", Units: []TranslationUnit{tc.unit}}
+
+				data, err := EncodeTranslationBundle(format, bundle)
+				if err != nil {
+					t.Fatalf("輸出失敗: %v", err)
+				}
+				decoded, detected, err := DecodeTranslationBundle(data)
+				if err != nil {
+					t.Fatalf("解析失敗: %v\n%s", err, data)
+				}
+
+				if detected != format {
+					t.Errorf("格式為 %q，預期 %q", detected, format)
+				}
+				if decoded.SourceLanguage != "en" || decoded.TargetLanguage != "zh" {
+					t.Errorf("語言為 %q → %q，預期 en → zh", decoded.SourceLanguage, decoded.TargetLanguage)
+				}
+				if len(decoded.Units) != 1 {
+					t.Fatalf("解析出 %d 個單位，預期 1 個\n%s", len(decoded.Units), data)
+				}
+				if !reflect.DeepEqual(decoded.Units[0], tc.unit) {
+					t.Errorf("單位不一致\n得到 %#v\n預期 %#v\n%s", decoded.Units[0], tc.unit, data)
+				}
+			})
+		}
+	}
+}
+
+func TestTranslationBundleRoundTripMultipleUnits(t *testing.T) {
+	units := make([]TranslationUnit, 0, len(exchangeRoundTripCases))
+	for _, tc := range exchangeRoundTripCases {
+		units = append(units, tc.unit)
+	}
+
+	for _, format := range []string{XLIFFFormat, POFormat} {
+		t.Run(format, func(t *testing.T) {
+			data, err := EncodeTranslationBundle(format, &TranslationBundle{SourceLanguage: "en", TargetLanguage: "ja", Units: units})
+			if err != nil {
+				t.Fatalf("輸出失敗: %v", err)
+			}
+			decoded, _, err := DecodeTranslationBundle(data)
+			if err != nil {
+				t.Fatalf("解析失敗: %v", err)
+			}
+			if !reflect.DeepEqual(decoded.Units, units) {
+				t.Errorf("單位不一致\n得到 %#v\n預期 %#v", decoded.Units, units)
+			}
+		})
+	}
+}
+
+func TestDecodePOFlags(t *testing.T) {
+	header := "msgid \"\"\nmsgstr \"\"\n\"Language: zh\\n\"\n\"X-Source-Language: en\\n\"\n\n"
+
+	tests := []struct {
+		name  string
+		entry string
+		fuzzy bool
+	}{
+		{"無旗標", "msgctxt \"article:1:title\"\nmsgid \"a\"\nmsgstr \"b\"\n", false},
+		{"fuzzy", "#, fuzzy\nmsgctxt \"article:1:title\"\nmsgid \"a\"\nmsgstr \"b\"\n", true},
+		{"多個旗標", "#, c-format, fuzzy\nmsgctxt \"article:1:title\"\nmsgid \"a\"\nmsgstr \"b\"\n", true},
+		{"其他旗標", "#, c-format\nmsgctxt \"article:1:title\"\nmsgid \"a\"\nmsgstr \"b\"\n", false},
+		{"相似名稱", "#, no-fuzzy-check\nmsgctxt \"article:1:title\"\nmsgid \"a\"\nmsgstr \"b\"\n", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			bundle, err := DecodePO([]byte(header + tc.entry))
+			if err != nil {
+				t.Fatalf("解析失敗: %v", err)
+			}
+			if len(bundle.Units) != 1 {
+				t.Fatalf("解析出 %d 個單位，預期 1 個", len(bundle.Units))
+			}
+			if bundle.Units[0].Fuzzy != tc.fuzzy {
+				t.Errorf("Fuzzy 為 %v，預期 %v", bundle.Units[0].Fuzzy, tc.fuzzy)
+			}
+		})
+	}
+}
+
+// fuzzy 旗標只屬於緊接在後的項目，不會延續到下一個項目
+func TestDecodePOFuzzyDoesNotLeak(t *testing.T) {
+	data := "msgid \"\"\nmsgstr \"Language: zh\\n\"\n\n" +
+		"#, fuzzy\nmsgctxt \"article:1:title\"\nmsgid \"a\"\nmsgstr \"b\"\n" +
+		"msgctxt \"article:1:excerpt\"\nmsgid \"c\"\nmsgstr \"d\"\n"
+
+	bundle, err := DecodePO([]byte(data))
+	if err != nil {
+		t.Fatalf("解析失敗: %v", err)
+	}
+	if len(bundle.Units) != 2 {
+		t.Fatalf("解析出 %d 個單位，預期 2 個", len(bundle.Units))
+	}
+	if !bundle.Units[0].Fuzzy || bundle.Units[1].Fuzzy {
+		t.Errorf("Fuzzy 為 %v、%v，預期 true、false", bundle.Units[0].Fuzzy, bundle.Units[1].Fuzzy)
+	}
+}
+
+func TestDecodeTranslationBundleErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"XLIFF 版本", `<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="1.2" srcLang="en"></xliff>`},
+		{"XLIFF 格式", `<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0"><file>`},
+		{"PO 缺少引號", "msgctxt article:1:title\nmsgid \"a\"\nmsgstr \"b\"\n"},
+		{"PO 孤立字串", "\"dangling\"\n"},
+		{"PO 不支援的關鍵字", "msgid_plural \"a\"\n"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := DecodeTranslationBundle([]byte(tc.data)); err == nil {
+				t.Errorf("預期解析失敗")
+			}
+		})
+	}
+}
+
+// 含 BOM 與前置空白的檔案仍能判斷格式
+func TestDecodeTranslationBundleDetectsFormat(t *testing.T) {
+	xliff, err := EncodeXLIFF(&TranslationBundle{SourceLanguage: "en", TargetLanguage: "zh"})
+	if err != nil {
+		t.Fatalf("輸出失敗: %v", err)
+	}
+	po := EncodePO(&TranslationBundle{SourceLanguage: "en", TargetLanguage: "zh"})
+
+	tests := []struct {
+		name   string
+		data   string
+		format string
+	}{
+		{"XLIFF", string(xliff), XLIFFFormat},
+		{"XLIFF 含 BOM", "\xef\xbb\xbf" + string(xliff), XLIFFFormat},
+		{"PO", string(po), POFormat},
+		{"PO 含前置空白", "\n\n" + string(po), POFormat},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			bundle, format, err := DecodeTranslationBundle([]byte(tc.data))
+			if err != nil {
+				t.Fatalf("解析失敗: %v", err)
+			}
+			if format != tc.format {
+				t.Errorf("格式為 %q，預期 %q", format, tc.format)
+			}
+			if bundle.TargetLanguage != "zh" || !strings.EqualFold(bundle.SourceLanguage, "en") {
+				t.Errorf("語言為 %q → %q，預期 en → zh", bundle.SourceLanguage, bundle.TargetLanguage)
+			}
+		})
+	}
+}
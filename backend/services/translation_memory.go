@@ -0,0 +1,483 @@
+package services
+
+import (
+	"GolangBlog/models"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// 段落分隔（一個以上的空行）
+var paragraphBreak = regexp.MustCompile(`\n\s*\n`)
+
+// 查詢段落的長度上限（字元數），超過時不提供建議；編輯距離的計算量與兩段長度的乘積成正比
+const maxSegmentLength = 2000
+
+// 納入記憶的譯文條件：待更新的翻譯已與來源內容不一致，待審閱的機器翻譯尚未確認
+var memoryTargetFilter = "t.needs_update = false AND " + ReviewedTranslationFilter("t")
+
+// 已審閱的翻譯條件：待審閱的機器翻譯尚未確認，不提供給訪客（alias 為資料表名稱或別名，可為空）
+func ReviewedTranslationFilter(alias string) string {
+	column := "review_status"
+	if alias != "" {
+		column = alias + "." + column
+	}
+	return "(" + column + " IS NULL OR " + column + " <> 'pending')"
+}
+
+// 翻譯記憶配置
+type TranslationMemoryConfig struct {
+	MinScore float64       // 建議的最低相似度（0-1）
+	CacheTTL time.Duration // 語言組合的記憶快取有效時間
+}
+
+// 翻譯記憶中的一組對照
+type MemoryPair struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`  // article、tag 或 category
+	ID     uint   `json:"id"`    // 項目 ID
+	Field  string `json:"field"` // 來源欄位，例如 title、name、content
+}
+
+// 翻譯記憶建議
+type MemorySuggestion struct {
+	MemoryPair
+	Score float64 `json:"score"` // 與查詢段落的相似度（0-1）
+}
+
+// 詞彙表檢查結果：來源內容出現詞彙，但譯文未使用指定譯法
+type GlossaryViolation struct {
+	TermID     uint   `json:"term_id"`
+	SourceTerm string `json:"source_term"`
+	TargetTerm string `json:"target_term"`
+	Field      string `json:"field,omitempty"`
+}
+
+// 快取的語言組合記憶
+type memoryCacheEntry struct {
+	pairs     []memoryEntry
+	index     map[bigram][]bigramPosting // 雙字元索引，用來在計算編輯距離前篩選候選對照
+	expiresAt time.Time
+}
+
+// 預先正規化的對照，避免每次比對重新處理
+type memoryEntry struct {
+	pair       MemoryPair
+	normalized []rune
+}
+
+// 連續的兩個字元
+type bigram [2]rune
+
+// 雙字元在某個對照中出現的次數
+type bigramPosting struct {
+	entry int
+	count int
+}
+
+// 翻譯記憶服務：以既有的文章、標籤與分類翻譯建立對照，提供模糊比對建議
+type TranslationMemoryService struct {
+	config TranslationMemoryConfig
+	db     *gorm.DB
+	mu     sync.RWMutex
+	cache  map[string]*memoryCacheEntry
+	gen    uint64 // 每次失效時遞增，避免載入期間失效的結果被寫回快取
+}
+
+// 新建翻譯記憶服務
+func NewTranslationMemoryService(config TranslationMemoryConfig, db *gorm.DB) *TranslationMemoryService {
+	if config.MinScore <= 0 || config.MinScore > 1 {
+		config.MinScore = 0.7
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = 10 * time.Minute
+	}
+
+	return &TranslationMemoryService{
+		config: config,
+		db:     db,
+		cache:  make(map[string]*memoryCacheEntry),
+	}
+}
+
+// 清除所有快取的記憶（翻譯內容變更後呼叫）
+func (s *TranslationMemoryService) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache = make(map[string]*memoryCacheEntry)
+	s.gen++
+}
+
+// 查詢與段落相似的既有翻譯，依相似度由高到低排序；minScore 為 0 時使用預設值
+func (s *TranslationMemoryService) Suggest(text, sourceLang, targetLang string, limit int, minScore float64) ([]MemorySuggestion, error) {
+	if minScore <= 0 || minScore > 1 {
+		minScore = s.config.MinScore
+	}
+	if limit <= 0 {
+		limit = 5
+	}
+
+	cached, err := s.entries(sourceLang, targetLang)
+	if err != nil {
+		return nil, err
+	}
+
+	query := normalizeSegment(text)
+	if len(query) == 0 || len(query) > maxSegmentLength {
+		return []MemorySuggestion{}, nil
+	}
+
+	suggestions := []MemorySuggestion{}
+	seen := map[string]int{} // 相同對照只保留一筆
+	for _, i := range cached.candidates(query, minScore) {
+		entry := cached.pairs[i]
+		// 長度差距過大時不可能達到門檻
+		if lengthBound(len(query), len(entry.normalized)) < minScore {
+			continue
+		}
+
+		score := similarity(query, entry.normalized)
+		if score < minScore {
+			continue
+		}
+
+		key := entry.pair.Source + "\x00" + entry.pair.Target
+		if i, ok := seen[key]; ok {
+			if score > suggestions[i].Score {
+				suggestions[i].Score = score
+			}
+			continue
+		}
+		seen[key] = len(suggestions)
+		suggestions = append(suggestions, MemorySuggestion{MemoryPair: entry.pair, Score: score})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Score > suggestions[j].Score
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// 取得語言組合的記憶與索引（快取過期時重新載入）
+func (s *TranslationMemoryService) entries(sourceLang, targetLang string) (*memoryCacheEntry, error) {
+	key := sourceLang + ">" + targetLang
+
+	s.mu.RLock()
+	cached, ok := s.cache[key]
+	gen := s.gen
+	s.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached, nil
+	}
+
+	pairs, err := s.load(sourceLang, targetLang)
+	if err != nil {
+		return nil, err
+	}
+
+	cached = &memoryCacheEntry{
+		pairs:     make([]memoryEntry, 0, len(pairs)),
+		index:     make(map[bigram][]bigramPosting),
+		expiresAt: time.Now().Add(s.config.CacheTTL),
+	}
+	for i, pair := range pairs {
+		normalized := normalizeSegment(pair.Source)
+		cached.pairs = append(cached.pairs, memoryEntry{pair: pair, normalized: normalized})
+		for gram, count := range countBigrams(normalized) {
+			cached.index[gram] = append(cached.index[gram], bigramPosting{entry: i, count: count})
+		}
+	}
+
+	s.mu.Lock()
+	if gen == s.gen {
+		s.cache[key] = cached
+	}
+	s.mu.Unlock()
+
+	return cached, nil
+}
+
+// 以雙字元索引篩選可能達到門檻的對照：編輯距離為 d 的兩段文字至少共有
+// max(長度) - 1 - 2d 個雙字元，共有數量不足的對照不需要計算編輯距離
+func (m *memoryCacheEntry) candidates(query []rune, minScore float64) []int {
+	// 門檻過低或查詢過短時，沒有共同雙字元的對照也可能達到門檻，改為逐一比對
+	if minScore <= 0.5 || float64(len(query))*(2*minScore-1) <= 1 {
+		all := make([]int, len(m.pairs))
+		for i := range all {
+			all[i] = i
+		}
+		return all
+	}
+
+	common := map[int]int{}
+	for gram, count := range countBigrams(query) {
+		for _, posting := range m.index[gram] {
+			common[posting.entry] += min(count, posting.count)
+		}
+	}
+
+	candidates := make([]int, 0, len(common))
+	for i, shared := range common {
+		longer := float64(max(len(query), len(m.pairs[i].normalized)))
+		if float64(shared) >= longer-1-2*(1-minScore)*longer-1e-9 {
+			candidates = append(candidates, i)
+		}
+	}
+	// 依原本順序比對，讓相同分數的建議維持穩定的排序
+	sort.Ints(candidates)
+	return candidates
+}
+
+// 計算每個雙字元出現的次數
+func countBigrams(runes []rune) map[bigram]int {
+	counts := make(map[bigram]int, len(runes))
+	for i := 1; i < len(runes); i++ {
+		counts[bigram{runes[i-1], runes[i]}]++
+	}
+	return counts
+}
+
+// 從資料庫載入兩種語言皆有翻譯的對照；文章內文依段落對齊，段落數不同時不使用
+func (s *TranslationMemoryService) load(sourceLang, targetLang string) ([]MemoryPair, error) {
+	var pairs []MemoryPair
+
+	var tags []struct {
+		ID     uint
+		Source string
+		Target string
+	}
+	if err := s.db.Table("tag_translations AS s").
+		Select("s.tag_id AS id, s.name AS source, t.name AS target").
+		Joins("JOIN tag_translations AS t ON t.tag_id = s.tag_id AND t.language_code = ? AND t.deleted_at IS NULL AND "+memoryTargetFilter, targetLang).
+		Joins("JOIN tags ON tags.id = s.tag_id AND tags.deleted_at IS NULL").
+		Where("s.language_code = ? AND s.deleted_at IS NULL", sourceLang).
+		Scan(&tags).Error; err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		pairs = appendPair(pairs, MemoryPair{Source: tag.Source, Target: tag.Target, Type: "tag", ID: tag.ID, Field: "name"})
+	}
+
+	var categories []struct {
+		ID                uint
+		Source            string
+		Target            string
+		SourceDescription string
+		TargetDescription string
+	}
+	if err := s.db.Table("category_translations AS s").
+		Select("s.category_id AS id, s.name AS source, t.name AS target, s.description AS source_description, t.description AS target_description").
+		Joins("JOIN category_translations AS t ON t.category_id = s.category_id AND t.language_code = ? AND t.deleted_at IS NULL AND "+memoryTargetFilter, targetLang).
+		Joins("JOIN categories ON categories.id = s.category_id AND categories.deleted_at IS NULL").
+		Where("s.language_code = ? AND s.deleted_at IS NULL", sourceLang).
+		Scan(&categories).Error; err != nil {
+		return nil, err
+	}
+	for _, category := range categories {
+		pairs = appendPair(pairs, MemoryPair{Source: category.Source, Target: category.Target, Type: "category", ID: category.ID, Field: "name"})
+		pairs = appendPair(pairs, MemoryPair{Source: category.SourceDescription, Target: category.TargetDescription, Type: "category", ID: category.ID, Field: "description"})
+	}
+
+	var sources []models.ArticleTranslation
+	if err := s.db.Joins("JOIN articles ON articles.id = article_translations.article_id AND articles.deleted_at IS NULL").
+		Where("article_translations.language_code = ?", sourceLang).
+		Find(&sources).Error; err != nil {
+		return nil, err
+	}
+	var targets []models.ArticleTranslation
+	if err := s.db.Unscoped().Table("article_translations AS t").
+		Where("t.language_code = ? AND t.deleted_at IS NULL AND "+memoryTargetFilter, targetLang).
+		Find(&targets).Error; err != nil {
+		return nil, err
+	}
+	targetByArticle := make(map[uint]models.ArticleTranslation, len(targets))
+	for _, target := range targets {
+		targetByArticle[target.ArticleID] = target
+	}
+
+	for _, source := range sources {
+		target, ok := targetByArticle[source.ArticleID]
+		if !ok {
+			continue
+		}
+		fields := []struct {
+			name           string
+			source, target string
+		}{
+			{"title", source.Title, target.Title},
+			{"excerpt", source.Excerpt, target.Excerpt},
+			{"meta_title", source.MetaTitle, target.MetaTitle},
+			{"meta_description", source.MetaDescription, target.MetaDescription},
+		}
+		for _, field := range fields {
+			pairs = appendPair(pairs, MemoryPair{Source: field.source, Target: field.target, Type: "article", ID: source.ArticleID, Field: field.name})
+		}
+
+		sourceParagraphs := SplitParagraphs(source.Content)
+		targetParagraphs := SplitParagraphs(target.Content)
+		if len(sourceParagraphs) != len(targetParagraphs) {
+			continue
+		}
+		for i := range sourceParagraphs {
+			pairs = appendPair(pairs, MemoryPair{Source: sourceParagraphs[i], Target: targetParagraphs[i], Type: "article", ID: source.ArticleID, Field: "content"})
+		}
+	}
+
+	return pairs, nil
+}
+
+// 加入對照（忽略空白內容）
+func appendPair(pairs []MemoryPair, pair MemoryPair) []MemoryPair {
+	pair.Source = strings.TrimSpace(pair.Source)
+	pair.Target = strings.TrimSpace(pair.Target)
+	if pair.Source == "" || pair.Target == "" {
+		return pairs
+	}
+	return append(pairs, pair)
+}
+
+// 以空行將內文拆成段落
+func SplitParagraphs(text string) []string {
+	var paragraphs []string
+	for _, paragraph := range paragraphBreak.Split(strings.ReplaceAll(text, "\r\n", "\n"), -1) {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			paragraphs = append(paragraphs, paragraph)
+		}
+	}
+	return paragraphs
+}
+
+// 正規化段落：轉為小寫、合併空白
+func normalizeSegment(text string) []rune {
+	var runes []rune
+	space := false
+	for _, r := range strings.TrimSpace(text) {
+		if unicode.IsSpace(r) {
+			space = true
+			continue
+		}
+		if space && len(runes) > 0 {
+			runes = append(runes, ' ')
+		}
+		space = false
+		runes = append(runes, unicode.ToLower(r))
+	}
+	return runes
+}
+
+// 長度差距下可能達到的最高相似度
+func lengthBound(a, b int) float64 {
+	longer := max(a, b)
+	if longer == 0 {
+		return 1
+	}
+	return float64(min(a, b)) / float64(longer)
+}
+
+// 以編輯距離計算相似度（1 表示完全相同）
+func similarity(a, b []rune) float64 {
+	longer := max(len(a), len(b))
+	if longer == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return 1 - float64(prev[len(b)])/float64(longer)
+}
+
+// 檢查譯文是否使用詞彙表指定的譯法；來源內容未出現詞彙時不檢查
+func CheckGlossary(source, target string, terms []models.GlossaryTerm) []GlossaryViolation {
+	violations := []GlossaryViolation{}
+	for _, term := range terms {
+		if !containsTerm(source, term.SourceTerm, term.CaseSensitive) {
+			continue
+		}
+		if containsTerm(target, term.TargetTerm, term.CaseSensitive) {
+			continue
+		}
+		violations = append(violations, GlossaryViolation{
+			TermID:     term.ID,
+			SourceTerm: term.SourceTerm,
+			TargetTerm: term.TargetTerm,
+		})
+	}
+	return violations
+}
+
+// 判斷內容是否包含詞彙；以字母或數字結尾的詞彙需為完整單字，避免 "go" 符合 "good"
+func containsTerm(text, term string, caseSensitive bool) bool {
+	if term == "" {
+		return false
+	}
+	if !caseSensitive {
+		text = strings.ToLower(text)
+		term = strings.ToLower(term)
+	}
+
+	for offset := 0; ; {
+		i := strings.Index(text[offset:], term)
+		if i < 0 {
+			return false
+		}
+		start := offset + i
+		end := start + len(term)
+		if wordBoundary(text, start, term, true) && wordBoundary(text, end, term, false) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+// 檢查詞彙前後是否為單字邊界；表意文字（如中文）不需要邊界
+func wordBoundary(text string, pos int, term string, before bool) bool {
+	var edge, neighbour rune
+	if before {
+		if pos == 0 {
+			return true
+		}
+		edge, _ = utf8.DecodeRuneInString(term)
+		neighbour, _ = utf8.DecodeLastRuneInString(text[:pos])
+	} else {
+		if pos >= len(text) {
+			return true
+		}
+		edge, _ = utf8.DecodeLastRuneInString(term)
+		neighbour, _ = utf8.DecodeRuneInString(text[pos:])
+	}
+
+	if !isWordRune(edge) || unicode.Is(unicode.Han, edge) {
+		return true
+	}
+	return !isWordRune(neighbour) || unicode.Is(unicode.Han, neighbour)
+}
+
+// 是否為單字字元
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
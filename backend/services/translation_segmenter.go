@@ -0,0 +1,160 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	// Markdown 行首標記：標題、引用、清單、表格
+	markdownLinePrefix = regexp.MustCompile(`^\s*(?:#{1,6}\s+|>\s*|[-*+]\s+(?:\[[ xX]\]\s+)?|\d+[.)]\s+|\|\s*)*`)
+	// 不可翻譯的行內內容：行內程式碼、HTML 標籤與註解、連結網址、網址、HTML 實體、表格分隔
+	protectedInline = regexp.MustCompile("`[^`]*`|<!--.*?-->|</?[A-Za-z][^>]*>|\\]\\([^)]*\\)|https?://[^\\s)<>\"]+|&[A-Za-z0-9#]+;|\\s*\\|\\s*")
+	// 翻譯結果中的佔位符（容許翻譯服務調整空白）
+	placeholderTag = regexp.MustCompile(`<x\s+id\s*=\s*"(\d+)"\s*/>`)
+	// 程式碼區塊的圍欄
+	codeFence = regexp.MustCompile("^\\s*(```|~~~)")
+	// Markdown 表格的對齊列
+	tableDivider = regexp.MustCompile(`^\s*\|?\s*:?-{3,}:?\s*(\|\s*:?-{3,}:?\s*)*\|?\s*$`)
+)
+
+// 分段後的文件：保留原本的結構，只將可翻譯的文字交給翻譯服務
+type MarkupDocument struct {
+	pieces   []markupPiece
+	segments []string
+}
+
+// 文件片段：原樣保留的內容，或一個可翻譯的段落
+type markupPiece struct {
+	literal string
+	segment int      // 可翻譯段落索引，-1 表示原樣保留
+	tokens  []string // 段落中被佔位符取代的內容
+}
+
+// 將 Markdown/HTML 內容拆成可翻譯的段落；程式碼區塊、標籤、網址等內容以佔位符保護
+func SegmentMarkup(text string) *MarkupDocument {
+	doc := &MarkupDocument{}
+	inFence := false
+
+	lines := strings.SplitAfter(text, "\n")
+	for _, line := range lines {
+		body := strings.TrimRight(line, "\r\n")
+		ending := line[len(body):]
+
+		// 程式碼區塊整段保留
+		if codeFence.MatchString(body) {
+			inFence = !inFence
+			doc.literal(line)
+			continue
+		}
+		if inFence || tableDivider.MatchString(body) {
+			doc.literal(line)
+			continue
+		}
+
+		prefix := markdownLinePrefix.FindString(body)
+		rest := body[len(prefix):]
+		content := strings.TrimRightFunc(rest, unicode.IsSpace)
+		trailing := rest[len(content):]
+
+		doc.literal(prefix)
+		doc.segment(content)
+		doc.literal(trailing + ending)
+	}
+
+	return doc
+}
+
+// 加入原樣保留的內容
+func (d *MarkupDocument) literal(text string) {
+	if text != "" {
+		d.pieces = append(d.pieces, markupPiece{literal: text, segment: -1})
+	}
+}
+
+// 加入可翻譯的內容；不含任何文字時原樣保留
+func (d *MarkupDocument) segment(text string) {
+	var tokens []string
+	var builder strings.Builder
+	last := 0
+	for _, loc := range protectedInline.FindAllStringIndex(text, -1) {
+		builder.WriteString(escapeSegment(text[last:loc[0]]))
+		fmt.Fprintf(&builder, `<x id="%d"/>`, len(tokens))
+		tokens = append(tokens, text[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	builder.WriteString(escapeSegment(text[last:]))
+
+	if !hasTranslatableText(protectedInline.ReplaceAllString(text, "")) {
+		d.literal(text)
+		return
+	}
+
+	d.pieces = append(d.pieces, markupPiece{segment: len(d.segments), tokens: tokens})
+	d.segments = append(d.segments, builder.String())
+}
+
+// 取得要交給翻譯服務的段落（以 HTML 格式表示，佔位符為 <x id="N"/>）
+func (d *MarkupDocument) Segments() []string {
+	return d.segments
+}
+
+// 以翻譯後的段落組回文件；遺失的佔位符內容附加在段落最後，避免連結或程式碼消失
+func (d *MarkupDocument) Render(translated []string) string {
+	var builder strings.Builder
+	for _, piece := range d.pieces {
+		if piece.segment < 0 {
+			builder.WriteString(piece.literal)
+			continue
+		}
+
+		text := d.segments[piece.segment]
+		if piece.segment < len(translated) {
+			text = translated[piece.segment]
+		}
+		builder.WriteString(restoreSegment(text, piece.tokens))
+	}
+	return builder.String()
+}
+
+// 將佔位符換回原本的內容，並還原翻譯服務輸出的 HTML 跳脫字元
+func restoreSegment(text string, tokens []string) string {
+	var builder strings.Builder
+	used := make([]bool, len(tokens))
+	last := 0
+	for _, match := range placeholderTag.FindAllStringSubmatchIndex(text, -1) {
+		builder.WriteString(html.UnescapeString(text[last:match[0]]))
+		if id, err := strconv.Atoi(text[match[2]:match[3]]); err == nil && id < len(tokens) && !used[id] {
+			builder.WriteString(tokens[id])
+			used[id] = true
+		}
+		last = match[1]
+	}
+	builder.WriteString(html.UnescapeString(text[last:]))
+
+	for id, token := range tokens {
+		if !used[id] {
+			builder.WriteString(token)
+		}
+	}
+	return builder.String()
+}
+
+// 跳脫會被翻譯服務視為 HTML 的字元
+func escapeSegment(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// 是否含有需要翻譯的文字（字母或表意文字）
+func hasTranslatableText(text string) bool {
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// 機器翻譯服務
+const (
+	FakeTranslator   = "fake"   // 本地假翻譯（測試與開發用，結果可預期）
+	DeepLTranslator  = "deepl"  // DeepL API
+	GoogleTranslator = "google" // Google Cloud Translation API v2
+	LibreTranslator  = "libre"  // LibreTranslate（可自行架設）
+)
+
+// 機器翻譯介面：texts 為 HTML 格式的段落，翻譯時須保留 <x id="N"/> 佔位符
+type Translator interface {
+	Name() string
+	Translate(ctx context.Context, texts []string, sourceLang, targetLang string) ([]string, error)
+}
+
+// 機器翻譯配置
+type TranslatorConfig struct {
+	Strategy string        // 翻譯服務: "fake"、"deepl"、"google" 或 "libre"
+	APIKey   string        // 翻譯服務的 API 金鑰
+	Endpoint string        // 翻譯服務網址（未設定時使用官方網址）
+	Timeout  time.Duration // 單次請求逾時
+}
+
+// 依配置建立機器翻譯服務
+func NewTranslator(config TranslatorConfig) (Translator, error) {
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	client := &http.Client{Timeout: config.Timeout}
+
+	switch config.Strategy {
+	case FakeTranslator:
+		return &fakeTranslator{}, nil
+	case DeepLTranslator:
+		if config.APIKey == "" {
+			return nil, errors.New("未設定 DeepL API 金鑰")
+		}
+		if config.Endpoint == "" {
+			config.Endpoint = "https://api-free.deepl.com"
+		}
+		return &deeplTranslator{config: config, client: client}, nil
+	case GoogleTranslator:
+		if config.APIKey == "" {
+			return nil, errors.New("未設定 Google Translation API 金鑰")
+		}
+		if config.Endpoint == "" {
+			config.Endpoint = "https://translation.googleapis.com"
+		}
+		return &googleTranslator{config: config, client: client}, nil
+	case LibreTranslator:
+		if config.Endpoint == "" {
+			return nil, errors.New("未設定 LibreTranslate 服務網址")
+		}
+		return &libreTranslator{config: config, client: client}, nil
+	default:
+		return nil, errors.New("不支援的機器翻譯服務")
+	}
+}
+
+// 翻譯多個欄位並保留 Markdown/HTML 結構；所有欄位的段落合併後分批送出
+func TranslateFields(ctx context.Context, translator Translator, fields []string, sourceLang, targetLang string, batchSize int) ([]string, error) {
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+
+	docs := make([]*MarkupDocument, len(fields))
+	var segments []string
+	for i, field := range fields {
+		docs[i] = SegmentMarkup(field)
+		segments = append(segments, docs[i].Segments()...)
+	}
+
+	translated := make([]string, 0, len(segments))
+	for start := 0; start < len(segments); start += batchSize {
+		end := min(start+batchSize, len(segments))
+		batch, err := translator.Translate(ctx, segments[start:end], sourceLang, targetLang)
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) != end-start {
+			return nil, fmt.Errorf("翻譯結果數量不符：送出 %d 段，收到 %d 段", end-start, len(batch))
+		}
+		translated = append(translated, batch...)
+	}
+
+	results := make([]string, len(fields))
+	offset := 0
+	for i, doc := range docs {
+		count := len(doc.Segments())
+		results[i] = doc.Render(translated[offset : offset+count])
+		offset += count
+	}
+	return results, nil
+}
+
+// 本地假翻譯：在每個段落前加上目標語言標記
+type fakeTranslator struct{}
+
+func (t *fakeTranslator) Name() string {
+	return FakeTranslator
+}
+
+func (t *fakeTranslator) Translate(ctx context.Context, texts []string, sourceLang, targetLang string) ([]string, error) {
+	results := make([]string, len(texts))
+	for i, text := range texts {
+		results[i] = "[" + targetLang + "] " + text
+	}
+	return results, nil
+}
+
+// DeepL API 翻譯
+type deeplTranslator struct {
+	config TranslatorConfig
+	client *http.Client
+}
+
+func (t *deeplTranslator) Name() string {
+	return DeepLTranslator
+}
+
+func (t *deeplTranslator) Translate(ctx context.Context, texts []string, sourceLang, targetLang string) ([]string, error) {
+	payload := map[string]interface{}{
+		"text":         texts,
+		"source_lang":  deeplLanguage(sourceLang, false),
+		"target_lang":  deeplLanguage(targetLang, true),
+		"tag_handling": "html",
+	}
+
+	var result struct {
+		Translations []struct {
+			Text string `json:"text"`
+		} `json:"translations"`
+	}
+	headers := map[string]string{"Authorization": "DeepL-Auth-Key " + t.config.APIKey}
+	if err := postJSON(ctx, t.client, strings.TrimRight(t.config.Endpoint, "/")+"/v2/translate", headers, payload, &result); err != nil {
+		return nil, err
+	}
+
+	translated := make([]string, len(result.Translations))
+	for i, item := range result.Translations {
+		translated[i] = item.Text
+	}
+	return translated, nil
+}
+
+// 轉換為 DeepL 的語言代碼（目標語言需區分部分地區變體）
+func deeplLanguage(code string, target bool) string {
+	code = strings.ToUpper(code)
+	primary, _, _ := strings.Cut(code, "-")
+	if !target {
+		return primary
+	}
+	switch code {
+	case "ZH-TW", "ZH-HK", "ZH-HANT":
+		return "ZH-HANT"
+	case "ZH", "ZH-CN", "ZH-HANS":
+		return "ZH-HANS"
+	case "EN":
+		return "EN-US"
+	case "PT":
+		return "PT-PT"
+	}
+	return code
+}
+
+// Google Cloud Translation API v2 翻譯
+type googleTranslator struct {
+	config TranslatorConfig
+	client *http.Client
+}
+
+func (t *googleTranslator) Name() string {
+	return GoogleTranslator
+}
+
+func (t *googleTranslator) Translate(ctx context.Context, texts []string, sourceLang, targetLang string) ([]string, error) {
+	payload := map[string]interface{}{
+		"q":      texts,
+		"source": sourceLang,
+		"target": targetLang,
+		"format": "html",
+	}
+
+	var result struct {
+		Data struct {
+			Translations []struct {
+				TranslatedText string `json:"translatedText"`
+			} `json:"translations"`
+		} `json:"data"`
+	}
+	endpoint := strings.TrimRight(t.config.Endpoint, "/") + "/language/translate/v2?key=" + url.QueryEscape(t.config.APIKey)
+	if err := postJSON(ctx, t.client, endpoint, nil, payload, &result); err != nil {
+		return nil, err
+	}
+
+	translated := make([]string, len(result.Data.Translations))
+	for i, item := range result.Data.Translations {
+		translated[i] = item.TranslatedText
+	}
+	return translated, nil
+}
+
+// LibreTranslate 翻譯
+type libreTranslator struct {
+	config TranslatorConfig
+	client *http.Client
+}
+
+func (t *libreTranslator) Name() string {
+	return LibreTranslator
+}
+
+func (t *libreTranslator) Translate(ctx context.Context, texts []string, sourceLang, targetLang string) ([]string, error) {
+	payload := map[string]interface{}{
+		"q":       texts,
+		"source":  libreLanguage(sourceLang),
+		"target":  libreLanguage(targetLang),
+		"format":  "html",
+		"api_key": t.config.APIKey,
+	}
+
+	var result struct {
+		TranslatedText []string `json:"translatedText"`
+	}
+	if err := postJSON(ctx, t.client, strings.TrimRight(t.config.Endpoint, "/")+"/translate", nil, payload, &result); err != nil {
+		return nil, err
+	}
+	return result.TranslatedText, nil
+}
+
+// 轉換為 LibreTranslate 的語言代碼（繁體中文為 zt）
+func libreLanguage(code string) string {
+	switch strings.ToLower(code) {
+	case "zh-tw", "zh-hk", "zh-hant":
+		return "zt"
+	}
+	primary, _, _ := strings.Cut(strings.ToLower(code), "-")
+	return primary
+}
+
+// 以 JSON 格式呼叫翻譯服務並解析回應
+func postJSON(ctx context.Context, client *http.Client, endpoint string, headers map[string]string, payload interface{}, result interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("呼叫翻譯服務失敗: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("翻譯服務回應錯誤 %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("解析翻譯服務回應失敗: %w", err)
+	}
+	return nil
+}
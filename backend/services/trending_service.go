@@ -0,0 +1,150 @@
+package services
+
+import (
+	"GolangBlog/models"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 排行方式
+const (
+	TrendingSortScore = "score" // 依時間衰減後的熱度
+	TrendingSortViews = "views" // 依區間內的瀏覽總數
+)
+
+// 排行的時間區間與熱度半衰期
+type TrendingWindow struct {
+	Duration time.Duration
+	HalfLife time.Duration
+}
+
+// 支援的排行時間區間
+var TrendingWindows = map[string]TrendingWindow{
+	"24h": {Duration: 24 * time.Hour, HalfLife: 6 * time.Hour},
+	"7d":  {Duration: 7 * 24 * time.Hour, HalfLife: 36 * time.Hour},
+	"30d": {Duration: 30 * 24 * time.Hour, HalfLife: 7 * 24 * time.Hour},
+}
+
+// 熱門文章排行配置
+type TrendingConfig struct {
+	CacheTTL time.Duration // 排行結果快取時間
+}
+
+// 熱門文章排行結果
+type TrendingArticle struct {
+	ArticleID uint    `json:"article_id"`
+	Views     int64   `json:"views"`
+	Score     float64 `json:"score"`
+}
+
+// 快取的排行結果
+type trendingCacheEntry struct {
+	results   []TrendingArticle
+	expiresAt time.Time
+}
+
+// 熱門文章排行服務
+type TrendingService struct {
+	config TrendingConfig
+	db     *gorm.DB
+	mu     sync.RWMutex
+	cache  map[string]*trendingCacheEntry
+}
+
+// 新建熱門文章排行服務
+func NewTrendingService(config TrendingConfig, db *gorm.DB) *TrendingService {
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = 5 * time.Minute
+	}
+
+	return &TrendingService{
+		config: config,
+		db:     db,
+		cache:  make(map[string]*trendingCacheEntry),
+	}
+}
+
+// 取得統計區間的起始整點
+func ViewBucket(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Hour)
+}
+
+// 累加文章在指定時段的瀏覽數
+func AddViewStat(db *gorm.DB, articleID uint, bucket time.Time, views int64) error {
+	stat := models.ArticleViewStat{
+		ArticleID:   articleID,
+		BucketStart: ViewBucket(bucket),
+		Views:       views,
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "article_id"}, {Name: "bucket_start"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"views": gorm.Expr("article_view_stats.views + ?", views),
+		}),
+	}).Create(&stat).Error
+}
+
+// 刪除早於最長排行區間的每小時瀏覽統計，回傳刪除的筆數
+func PruneViewStats(db *gorm.DB, now time.Time) (int64, error) {
+	var longest time.Duration
+	for _, window := range TrendingWindows {
+		longest = max(longest, window.Duration)
+	}
+
+	result := db.Where("bucket_start < ?", ViewBucket(now.Add(-longest))).Delete(&models.ArticleViewStat{})
+	return result.RowsAffected, result.Error
+}
+
+// 取得指定區間的熱門文章排行；languages 為語言退回順序，文章在其中任一語言有已審閱的翻譯即列入
+func (s *TrendingService) Rank(windowName string, languages []string, sortBy string, limit int) ([]TrendingArticle, error) {
+	window, ok := TrendingWindows[windowName]
+	if !ok {
+		return nil, fmt.Errorf("不支援的時間區間: %s", windowName)
+	}
+	if sortBy != TrendingSortViews {
+		sortBy = TrendingSortScore
+	}
+
+	key := fmt.Sprintf("%s:%s:%s:%d", windowName, strings.Join(languages, ","), sortBy, limit)
+
+	s.mu.RLock()
+	entry, ok := s.cache[key]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.results, nil
+	}
+
+	now := time.Now().UTC()
+	// 以區間中點計算經過時數，熱度每經過一個半衰期減半
+	decay := math.Ln2 / window.HalfLife.Hours()
+
+	var results []TrendingArticle
+	err := s.db.Table("article_view_stats").
+		Select("article_view_stats.article_id, SUM(article_view_stats.views) AS views, "+
+			"SUM(article_view_stats.views * EXP(-? * (EXTRACT(EPOCH FROM (? - article_view_stats.bucket_start)) / 3600.0 - 0.5))) AS score", decay, now).
+		Joins("JOIN articles ON articles.id = article_view_stats.article_id AND articles.deleted_at IS NULL AND articles.status = ?", "published").
+		Where("EXISTS (SELECT 1 FROM article_translations WHERE article_translations.article_id = articles.id AND article_translations.language_code IN ? AND article_translations.deleted_at IS NULL AND "+ReviewedTranslationFilter("article_translations")+")", languages).
+		Where("article_view_stats.bucket_start >= ?", ViewBucket(now.Add(-window.Duration))).
+		Group("article_view_stats.article_id").
+		Order(sortBy + " DESC, article_view_stats.article_id DESC").
+		Limit(limit).
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[key] = &trendingCacheEntry{
+		results:   results,
+		expiresAt: time.Now().Add(s.config.CacheTTL),
+	}
+	s.mu.Unlock()
+
+	return results, nil
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"GolangBlog/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"regexp"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 預設視為爬蟲或自動化工具的 User-Agent
+var defaultBotPattern = regexp.MustCompile(`(?i)bot|crawl|spider|slurp|archiver|facebookexternalhit|embedly|preview|headless|phantomjs|lighthouse|curl|wget|python-requests|go-http-client|okhttp|java/|libwww|httpclient`)
+
+// 瀏覽計數配置
+type ViewCounterConfig struct {
+	BufferSize    int            // 待處理瀏覽紀錄的通道容量
+	FlushInterval time.Duration  // 批次寫入資料庫的間隔
+	DedupeWindow  time.Duration  // 同一訪客重複瀏覽同篇文章不重複計數的時間
+	BotPattern    *regexp.Regexp // 判定為爬蟲的 User-Agent 規則
+}
+
+// 單次瀏覽紀錄
+type viewHit struct {
+	articleID uint
+	visitor   string
+	at        time.Time
+}
+
+// 待寫入的瀏覽數（文章 → 統計時段 → 次數）
+type pendingViews map[uint]map[time.Time]int64
+
+// 非同步瀏覽計數服務：過濾爬蟲、去除重複瀏覽並批次寫入資料庫
+type ViewCounter struct {
+	config  ViewCounterConfig
+	db      *gorm.DB
+	hits    chan viewHit
+	seen    map[string]time.Time
+	pending pendingViews
+
+	pruned time.Time // 上次清除過期瀏覽統計的時間
+
+	stop     chan struct{} // 關閉時背景處理程序寫入剩餘紀錄後結束
+	done     chan struct{} // 背景處理程序結束後關閉
+	stopOnce sync.Once
+}
+
+// 新建瀏覽計數服務
+func NewViewCounter(config ViewCounterConfig, db *gorm.DB) *ViewCounter {
+	if config.BufferSize <= 0 {
+		config.BufferSize = 4096
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 10 * time.Second
+	}
+	if config.DedupeWindow <= 0 {
+		config.DedupeWindow = 30 * time.Minute
+	}
+	if config.BotPattern == nil {
+		config.BotPattern = defaultBotPattern
+	}
+
+	return &ViewCounter{
+		config:  config,
+		db:      db,
+		hits:    make(chan viewHit, config.BufferSize),
+		seen:    make(map[string]time.Time),
+		pending: make(pendingViews),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// 產生訪客識別值（不保存原始 IP）
+func VisitorKey(ip, userAgent string) string {
+	sum := sha256.Sum256([]byte(ip + "|" + userAgent))
+	return hex.EncodeToString(sum[:16])
+}
+
+// 判斷 User-Agent 是否為爬蟲或自動化工具
+func (v *ViewCounter) IsBot(userAgent string) bool {
+	return userAgent == "" || v.config.BotPattern.MatchString(userAgent)
+}
+
+// 記錄一次瀏覽，不會阻塞請求；爬蟲或緩衝已滿時回傳 false
+func (v *ViewCounter) Record(articleID uint, visitor, userAgent string) bool {
+	if v.IsBot(userAgent) {
+		return false
+	}
+
+	select {
+	case v.hits <- viewHit{articleID: articleID, visitor: visitor, at: time.Now()}:
+		return true
+	default:
+		return false
+	}
+}
+
+// 啟動背景處理程序
+func (v *ViewCounter) Start() {
+	go v.run()
+}
+
+// 停止背景處理程序：處理通道中剩餘的瀏覽紀錄並寫入資料庫後返回（需在 Start 之後、伺服器停止接收請求後呼叫）
+func (v *ViewCounter) Stop() {
+	v.stopOnce.Do(func() {
+		close(v.stop)
+	})
+	<-v.done
+}
+
+// 背景處理：接收瀏覽紀錄並定期批次寫入
+func (v *ViewCounter) run() {
+	defer close(v.done)
+
+	ticker := time.NewTicker(v.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case hit := <-v.hits:
+			v.collect(hit)
+		case now := <-ticker.C:
+			v.flush()
+			v.expireSeen(now)
+			v.pruneStats(now)
+		case <-v.stop:
+			v.drain()
+			return
+		}
+	}
+}
+
+// 收取通道中剩餘的瀏覽紀錄並寫入
+func (v *ViewCounter) drain() {
+	for {
+		select {
+		case hit := <-v.hits:
+			v.collect(hit)
+		default:
+			v.flush()
+			return
+		}
+	}
+}
+
+// 去除重複瀏覽後累加至待寫入紀錄
+func (v *ViewCounter) collect(hit viewHit) {
+	key := fmt.Sprintf("%d:%s", hit.articleID, hit.visitor)
+	if last, ok := v.seen[key]; ok && hit.at.Sub(last) < v.config.DedupeWindow {
+		return
+	}
+	v.seen[key] = hit.at
+
+	bucket := ViewBucket(hit.at)
+	if v.pending[hit.articleID] == nil {
+		v.pending[hit.articleID] = make(map[time.Time]int64)
+	}
+	v.pending[hit.articleID][bucket]++
+}
+
+// 將累積的瀏覽數以原子累加方式寫入資料庫，失敗時保留至下次重試
+func (v *ViewCounter) flush() {
+	if len(v.pending) == 0 {
+		return
+	}
+
+	batch := v.pending
+	v.pending = make(pendingViews)
+
+	err := v.db.Transaction(func(tx *gorm.DB) error {
+		for articleID, buckets := range batch {
+			var total int64
+			for bucket, views := range buckets {
+				if err := AddViewStat(tx, articleID, bucket, views); err != nil {
+					return err
+				}
+				total += views
+			}
+
+			// 使用 UpdateColumn 避免更新 updated_at 與其他欄位
+			if err := tx.Model(&models.Article{}).Where("id = ?", articleID).
+				UpdateColumn("view_count", gorm.Expr("view_count + ?", total)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("寫入瀏覽統計失敗，將於下次重試: %v", err)
+		for articleID, buckets := range batch {
+			if v.pending[articleID] == nil {
+				v.pending[articleID] = make(map[time.Time]int64)
+			}
+			for bucket, views := range buckets {
+				v.pending[articleID][bucket] += views
+			}
+		}
+	}
+}
+
+// 每小時刪除一次超出排行區間、不會再被使用的每小時瀏覽統計
+func (v *ViewCounter) pruneStats(now time.Time) {
+	if now.Sub(v.pruned) < time.Hour {
+		return
+	}
+	v.pruned = now
+
+	if _, err := PruneViewStats(v.db, now); err != nil {
+		log.Printf("清除過期瀏覽統計失敗: %v", err)
+	}
+}
+
+// 移除已超過去重時間的訪客紀錄
+func (v *ViewCounter) expireSeen(now time.Time) {
+	for key, at := range v.seen {
+		if now.Sub(at) >= v.config.DedupeWindow {
+			delete(v.seen, key)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package utils
+
+// 依 RFC 7396（JSON Merge Patch）將 patch 套用到 target 並回傳結果（不修改原本的值）：
+// patch 為物件時逐一合併成員，成員值為 null 時移除該成員；其他值（包含陣列）直接取代
+func MergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, _ := target.(map[string]interface{})
+	result := make(map[string]interface{}, len(targetObject)+len(patchObject))
+	for key, value := range targetObject {
+		result[key] = value
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		result[key] = MergePatch(result[key], value)
+	}
+	return result
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/gosimple/slug"
+)
+
+// Slug 產生策略
+const (
+	SlugTransliterate = "transliterate" // 轉寫為拉丁字母（預設）
+	SlugNative        = "native"        // 保留原本的文字，適用於阿拉伯文、希伯來文等
+)
+
+// 依策略產生 slug；未指定策略時轉寫為拉丁字母
+func MakeSlug(text, strategy string) string {
+	if strategy == SlugNative {
+		return NativeSlug(text)
+	}
+	return slug.Make(text)
+}
+
+// 保留原本文字的 slug：字母與數字轉為小寫，其他字元以連字號分隔；
+// 移除方向控制字元、阿拉伯文延長符號，以及阿拉伯文與希伯來文的可省略母音符號
+func NativeSlug(text string) string {
+	var builder strings.Builder
+	pendingDash := false
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Bidi_Control, r) || unicode.Is(unicode.Join_Control, r) || r == '\u0640':
+			continue
+		case unicode.Is(unicode.Mn, r) && r >= 0x0591 && r <= 0x06FF: // 希伯來文與阿拉伯文區段的母音符號
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || (unicode.IsMark(r) && builder.Len() > 0 && !pendingDash):
+			if pendingDash && builder.Len() > 0 {
+				builder.WriteByte('-')
+			}
+			pendingDash = false
+			builder.WriteRune(unicode.ToLower(r))
+		default:
+			pendingDash = true
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 截斷後附加的省略符號
+const Ellipsis = "…"
+
+// 方向控制字元
+const (
+	bidiPDF = '\u202c' // 結束 LRE、RLE、LRO、RLO
+	bidiPDI = '\u2069' // 結束 LRI、RLI、FSI
+)
+
+var (
+	// 程式碼區塊
+	codeBlockPattern = regexp.MustCompile("(?s)```.*?```|~~~.*?~~~")
+	// 圖片
+	imagePattern = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	// 連結：保留連結文字
+	linkPattern = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	// HTML 標籤與註解
+	htmlTagPattern = regexp.MustCompile(`(?s)<!--.*?-->|</?[A-Za-z][^>]*>`)
+	// Markdown 行首標記：標題、引用、清單
+	linePrefixPattern = regexp.MustCompile(`(?m)^\s*(?:#{1,6}\s+|>\s*|[-*+]\s+|\d+[.)]\s+)`)
+	// 強調與行內程式碼標記
+	emphasisPattern = regexp.MustCompile("[*_~`]+")
+)
+
+// 將 Markdown/HTML 內容轉為純文字（移除程式碼區塊、圖片與標記，合併空白）
+func PlainText(markup string) string {
+	text := codeBlockPattern.ReplaceAllString(markup, " ")
+	text = imagePattern.ReplaceAllString(text, "$1")
+	text = linkPattern.ReplaceAllString(text, "$1")
+	text = htmlTagPattern.ReplaceAllString(text, " ")
+	text = linePrefixPattern.ReplaceAllString(text, "")
+	text = emphasisPattern.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// 從內文產生摘要
+func Excerpt(content string, limit int) string {
+	return TruncateText(PlainText(content), limit)
+}
+
+// 截斷文字至 limit 個字元（含省略符號），適用於雙向文字：
+// 不切斷組合字元（如阿拉伯文母音符號、表情符號組合），優先在單字邊界截斷，
+// 並補上未結束的方向嵌入與隔離，避免影響後續內容的顯示方向
+func TruncateText(text string, limit int) string {
+	if limit <= 0 || utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+
+	// 保留省略符號的位置
+	clusters := splitClusters(text)
+	keep, count := 0, 0
+	for keep < len(clusters) && count+utf8.RuneCountInString(clusters[keep]) < limit {
+		count += utf8.RuneCountInString(clusters[keep])
+		keep++
+	}
+
+	// 補上方向控制字元後超過上限時，再往前截斷
+	for ; keep > 0; keep-- {
+		// 在後半段找得到空白時，於單字邊界截斷
+		cut := keep
+		for i := keep; i > keep/2; i-- {
+			if isSpaceCluster(clusters[i]) {
+				cut = i
+				break
+			}
+		}
+
+		kept := strings.TrimRightFunc(strings.Join(clusters[:cut], ""), func(r rune) bool {
+			return unicode.IsSpace(r) || unicode.Is(unicode.Bidi_Control, r) || strings.ContainsRune(",.;:、，。；：-–—", r)
+		})
+		if result := closeBidi(kept) + Ellipsis; utf8.RuneCountInString(result) <= limit {
+			return result
+		}
+	}
+	return Ellipsis
+}
+
+// 將文字拆成使用者感知的字元：基本字元加上其後的組合符號、連接符與變體選擇符
+func splitClusters(text string) []string {
+	var clusters []string
+	start := -1
+	joined := false
+	for i, r := range text {
+		extend := unicode.IsMark(r) || r == '\u200d' || unicode.Is(unicode.Variation_Selector, r) ||
+			(r >= 0x1F3FB && r <= 0x1F3FF) // 膚色修飾符
+		if start >= 0 && (extend || joined) {
+			joined = r == '\u200d'
+			continue
+		}
+		if start >= 0 {
+			clusters = append(clusters, text[start:i])
+		}
+		start = i
+		joined = r == '\u200d'
+	}
+	if start >= 0 {
+		clusters = append(clusters, text[start:])
+	}
+	return clusters
+}
+
+// 是否為空白字元
+func isSpaceCluster(cluster string) bool {
+	return strings.TrimSpace(cluster) == ""
+}
+
+// 補上未結束的方向嵌入（PDF）與隔離（PDI）
+func closeBidi(text string) string {
+	var stack []rune
+	for _, r := range text {
+		switch r {
+		case '\u202a', '\u202b', '\u202d', '\u202e': // LRE、RLE、LRO、RLO
+			stack = append(stack, bidiPDF)
+		case '\u2066', '\u2067', '\u2068': // LRI、RLI、FSI
+			stack = append(stack, bidiPDI)
+		case bidiPDF:
+			// PDF 只結束最近的嵌入，且不跨越隔離
+			if len(stack) > 0 && stack[len(stack)-1] == bidiPDF {
+				stack = stack[:len(stack)-1]
+			}
+		case bidiPDI:
+			// PDI 結束最近的隔離，以及其中尚未結束的嵌入
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i] == bidiPDI {
+					stack = stack[:i]
+					break
+				}
+			}
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString(text)
+	for i := len(stack) - 1; i >= 0; i-- {
+		builder.WriteRune(stack[i])
+	}
+	return builder.String()
+}